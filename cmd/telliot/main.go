@@ -31,8 +31,9 @@ func main() {
 		}
 	}
 	if shouldShowVersionMessage {
+		// Stdout is only for the command results so the message goes to stderr.
 		//lint:ignore faillint it should print to console
-		fmt.Printf(cli.VersionMessage, GitTag, GitHash)
+		fmt.Fprintf(os.Stderr, cli.VersionMessage, GitTag, GitHash)
 
 		newRelease, err := checkNewVersion(GitTag)
 		if err != nil {
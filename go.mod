@@ -14,6 +14,8 @@ require (
 	github.com/fatih/structtag v1.2.0
 	github.com/go-kit/kit v0.10.0
 	github.com/google/go-github/v35 v35.3.1-0.20210613000602-77dd0eb64ad2
+	github.com/google/uuid v1.1.5
+	github.com/holiman/uint256 v1.1.1
 	github.com/itchyny/gojq v0.12.4
 	github.com/joho/godotenv v1.3.0
 	github.com/json-iterator/go v1.1.11
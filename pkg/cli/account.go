@@ -0,0 +1,225 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/math"
+	"github.com/tellor-io/telliot/pkg/stake"
+	"github.com/tellor-io/telliot/pkg/submitter/tellor"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+type keystoreDir struct {
+	Keystore string `optional:"" help:"keystore directory, defaults to the ETH_KEYSTORE_DIR env"`
+}
+
+// dir returns the keystore directory and should be called after the env file is loaded.
+func (self keystoreDir) dir() (string, error) {
+	if self.Keystore != "" {
+		return self.Keystore, nil
+	}
+	if dir := os.Getenv(ethereum.KeystoreDirEnvName); dir != "" {
+		return dir, nil
+	}
+	return "", errors.Errorf("keystore directory not set, use the keystore flag or %v", ethereum.KeystoreDirEnvName)
+}
+
+type keystoreResult struct {
+	Address common.Address `json:"address"`
+	File    string         `json:"file"`
+}
+
+type accountNewCmd struct {
+	cfg
+	keystoreDir
+}
+
+func (self accountNewCmd) Run() error {
+	logger := logging.NewLogger()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	dir, err := self.dir()
+	if err != nil {
+		return err
+	}
+	addr, file, err := ethereum.NewKeystoreAccount(dir)
+	if err != nil {
+		return err
+	}
+	level.Info(logger).Log("msg", "account created, add it to Accounts.Labels in the config to select it by label")
+	return output(keystoreResult{Address: addr, File: file})
+}
+
+type accountImportCmd struct {
+	cfg
+	keystoreDir
+}
+
+func (self accountImportCmd) Run() error {
+	logger := logging.NewLogger()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	dir, err := self.dir()
+	if err != nil {
+		return err
+	}
+	privateKey, err := readSecret("Private key: ")
+	if err != nil {
+		return errors.Wrap(err, "reading the private key")
+	}
+	addr, file, err := ethereum.ImportKeystoreAccount(dir, privateKey)
+	if err != nil {
+		return err
+	}
+	level.Info(logger).Log("msg", "account imported, remove its private key from the env file if it is there")
+	return output(keystoreResult{Address: addr, File: file})
+}
+
+type accountExportCmd struct {
+	cfg
+	keystoreDir
+	addr
+	Yes bool `short:"y" help:"export without a confirmation prompt"`
+}
+
+func (self accountExportCmd) Run() error {
+	logger := logging.NewLogger()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	dir, err := self.dir()
+	if err != nil {
+		return err
+	}
+	addr, err := self.address()
+	if err != nil {
+		return err
+	}
+	if !self.Yes {
+		ok, err := confirm(fmt.Sprintf("Write the unencrypted private key of %v to stdout?", addr.Hex()))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			level.Info(logger).Log("msg", "aborted")
+			return nil
+		}
+	}
+	privateKey, err := ethereum.ExportKeystoreAccount(dir, addr)
+	if err != nil {
+		return err
+	}
+	return output(struct {
+		Address    common.Address `json:"address"`
+		PrivateKey string         `json:"privateKey"`
+	}{addr, privateKey})
+}
+
+// readSecret prompts for a secret on a terminal and otherwise reads it from the first line of stdin.
+func readSecret(prompt string) (string, error) {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+type accountListCmd struct {
+	cfg
+	Labels []string `optional:"" help:"show only the accounts with one of these labels"`
+}
+
+func (self accountListCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	accounts, err := ethereum.GetAccounts()
+	if err != nil {
+		return errors.Wrap(err, "getting accounts")
+	}
+	accounts, err = cfg.Accounts.Select(accounts, self.Labels)
+	if err != nil {
+		return err
+	}
+
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	contract, err := contracts.NewITellor(client)
+	if err != nil {
+		return errors.Wrap(err, "create tellor contract instance")
+	}
+
+	result := make([]accountListResult, 0, len(accounts))
+	for _, account := range accounts {
+		ethBalance, err := client.BalanceAt(ctx, account.Address, nil)
+		if err != nil {
+			return errors.Wrapf(err, "getting the ETH balance of:%v", account.Address.Hex())
+		}
+		info, err := stake.GetInfo(ctx, contract, account.Address)
+		if err != nil {
+			return errors.Wrapf(err, "getting stake info for:%v", account.Address.Hex())
+		}
+		lastSubmit, err := tellor.LastSubmit(&bind.CallOpts{Context: ctx}, contract, account.Address)
+		if err != nil {
+			return err
+		}
+		result = append(result, accountListResult{
+			Address:    account.Address,
+			Labels:     cfg.Accounts.AccountLabels(account.Address),
+			ETH:        math.BigInt18eToFloat(ethBalance),
+			TRB:        math.BigInt18eToFloat(info.Balance),
+			Status:     stake.StatusName(info.Status),
+			LastSubmit: lastSubmit,
+		})
+	}
+	return output(result)
+}
+
+type accountListResult struct {
+	Address    common.Address `json:"address"`
+	Labels     []string       `json:"labels"`
+	ETH        float64        `json:"eth"`
+	TRB        float64        `json:"trb"`
+	Status     string         `json:"status"`
+	LastSubmit time.Time      `json:"lastSubmit"`
+}
@@ -0,0 +1,437 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/math"
+	"github.com/tellor-io/telliot/pkg/stake"
+)
+
+// Statuses of the batch results besides txSent.
+const (
+	batchPlanned = "planned"
+	batchSkipped = "skipped"
+	batchFailed  = "failed"
+)
+
+// ethTransferGas is the gas limit of a plain ETH transfer.
+const ethTransferGas = 21000
+
+type cfgBatch struct {
+	cfg
+	GasPrice int  `optional:"" help:"gas price to use when running the command"`
+	DryRun   bool `help:"only show the transactions that would be sent"`
+	Yes      bool `short:"y" help:"send the transactions without a confirmation prompt"`
+}
+
+func (self cfgBatch) gasPrice() *big.Int {
+	if self.GasPrice > 0 {
+		return big.NewInt(int64(self.GasPrice) * params.GWei)
+	}
+	return nil
+}
+
+// batchResult is a transaction of a batch for a single account.
+type batchResult struct {
+	Account common.Address `json:"account"`
+	Action  string         `json:"action"`
+	To      common.Address `json:"to"`
+	Amount  float64        `json:"amount"`
+	Status  string         `json:"status"`
+	Tx      string         `json:"tx,omitempty"`
+	// Note is why an account is skipped or why its transaction failed.
+	Note string `json:"note,omitempty"`
+
+	amount *big.Int
+}
+
+// batchFunds is the ETH of the account that pays for all transactions of a batch.
+type batchFunds struct {
+	Balance *big.Int
+	// Required is the amount of all planned transactions and their gas.
+	Required *big.Int
+}
+
+// check returns an error when the balance doesn't cover all planned transactions.
+func (self *batchFunds) check() error {
+	if self.Balance.Cmp(self.Required) < 0 {
+		return errors.Errorf("insufficient ETH in the funding account actual: %v, required including the gas: %v",
+			math.BigInt18eToFloat(self.Balance),
+			math.BigInt18eToFloat(self.Required))
+	}
+	return nil
+}
+
+// runBatch shows the planned transactions and sends them after a confirmation.
+// The dry run only writes the plan as the result.
+// The funds are checked only for batches paid by a single account and
+// the dry run shows the plan even when these are insufficient.
+func runBatch(logger log.Logger, self cfgBatch, plan []*batchResult, funds *batchFunds, send func(*batchResult) (*types.Transaction, error)) error {
+	var count int
+	var total float64
+	for _, r := range plan {
+		if r.Status == batchPlanned {
+			count++
+			total += r.Amount
+		}
+	}
+	level.Info(logger).Log("msg", "batch summary", "accounts", len(plan), "transactions", count, "total", total)
+	if funds != nil {
+		level.Info(logger).Log("msg", "batch funds",
+			"balance", math.BigInt18eToFloat(funds.Balance),
+			"required", math.BigInt18eToFloat(funds.Required),
+		)
+		if err := funds.check(); err != nil {
+			if !self.DryRun {
+				return err
+			}
+			level.Warn(logger).Log("msg", "the batch can't be sent", "err", err)
+		}
+	}
+	if self.DryRun {
+		return output(plan)
+	}
+	if count == 0 {
+		level.Info(logger).Log("msg", "nothing to send")
+		return output(plan)
+	}
+
+	if !self.Yes {
+		if err := render(os.Stderr, outputTable, plan); err != nil {
+			return err
+		}
+		ok, err := confirm(fmt.Sprintf("Send %v transactions with a total of %v?", count, total))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			level.Info(logger).Log("msg", "aborted")
+			return nil
+		}
+	}
+
+	for _, r := range plan {
+		if r.Status != batchPlanned {
+			continue
+		}
+		tx, err := send(r)
+		if err != nil {
+			r.Status, r.Note = batchFailed, err.Error()
+			level.Error(logger).Log("msg", "sending batch transaction", "account", r.Account.Hex(), "err", err)
+			continue
+		}
+		r.Status, r.Tx = txSent, tx.Hash().Hex()
+	}
+	return output(plan)
+}
+
+// confirm asks on stderr and reads the answer from stdin.
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%v [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, errors.Wrap(err, "reading the confirmation")
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+type batchSweepCmd struct {
+	cfgBatch
+	To  string  `required:"" help:"address that receives the TRB of all accounts"`
+	Min float64 `optional:"" help:"skip the accounts with less transferable TRB than this"`
+}
+
+func (self batchSweepCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	if !common.IsHexAddress(self.To) {
+		return errors.Errorf("invalid etherum address:%v", self.To)
+	}
+	to := common.HexToAddress(self.To)
+
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	contract, err := contracts.NewITellor(client)
+	if err != nil {
+		return errors.Wrap(err, "create tellor contract instance")
+	}
+	accounts, err := ethereum.GetAccounts()
+	if err != nil {
+		return errors.Wrap(err, "getting accounts")
+	}
+	stakeAmt, err := contract.GetUintVar(&bind.CallOpts{Context: ctx}, ethereum.Keccak256([]byte("_STAKE_AMOUNT")))
+	if err != nil {
+		return errors.Wrap(err, "fetching stake amount")
+	}
+
+	var infos []*stake.Info
+	byAddr := make(map[common.Address]*ethereum.Account)
+	for _, account := range accounts {
+		if account.Address == to {
+			continue
+		}
+		byAddr[account.Address] = account
+		info, err := stake.GetInfo(ctx, contract, account.Address)
+		if err != nil {
+			return errors.Wrapf(err, "getting stake info for:%v", account.Address.Hex())
+		}
+		infos = append(infos, info)
+	}
+
+	plan := sweepPlan(infos, stakeAmt, to, self.Min)
+	return runBatch(logger, self.cfgBatch, plan, nil, func(r *batchResult) (*types.Transaction, error) {
+		auth, err := ethereum.PrepareEthTransaction(ctx, client, byAddr[r.Account], self.gasPrice())
+		if err != nil {
+			return nil, errors.Wrap(err, "prepare ethereum transaction")
+		}
+		return contract.Transfer(auth, r.To, r.amount)
+	})
+}
+
+// sweepPlan transfers the TRB of all accounts to the given address.
+// The contract doesn't allow to transfer the stake until it is unlocked so it stays in the staked accounts.
+func sweepPlan(infos []*stake.Info, stakeAmt *big.Int, to common.Address, min float64) []*batchResult {
+	var plan []*batchResult
+	for _, info := range infos {
+		amount := new(big.Int).Set(info.Balance)
+		if info.Status != stake.StatusNotStaked && info.Status != stake.StatusUnlocked {
+			amount.Sub(amount, stakeAmt)
+		}
+		r := &batchResult{Account: info.Address, Action: "transfer", To: to, Status: batchPlanned, amount: amount}
+		r.Amount = math.BigInt18eToFloat(amount)
+		if amount.Sign() <= 0 || r.Amount < min {
+			r.Status, r.Note = batchSkipped, "not enough transferable TRB"
+		}
+		plan = append(plan, r)
+	}
+	return plan
+}
+
+type batchTopupCmd struct {
+	cfgBatch
+	From   string  `required:"" help:"address of the account that sends the ETH"`
+	Below  float64 `required:"" help:"top up the accounts with less ETH than this"`
+	Target float64 `required:"" help:"ETH balance of the accounts after the top up"`
+}
+
+func (self batchTopupCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	if self.Target < self.Below {
+		return errors.Errorf("the target:%v is less than the threshold:%v", self.Target, self.Below)
+	}
+	below, err := math.FloatToBigInt18e(self.Below)
+	if err != nil {
+		return errors.Wrap(err, "invalid threshold")
+	}
+	target, err := math.FloatToBigInt18e(self.Target)
+	if err != nil {
+		return errors.Wrap(err, "invalid target")
+	}
+
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	funding, err := ethereum.GetAccountByPubAddess(self.From)
+	if err != nil {
+		return err
+	}
+	accounts, err := ethereum.GetAccounts()
+	if err != nil {
+		return errors.Wrap(err, "getting accounts")
+	}
+
+	var balances []ethBalance
+	for _, account := range accounts {
+		if account.Address == funding.Address {
+			continue
+		}
+		balance, err := client.BalanceAt(ctx, account.Address, nil)
+		if err != nil {
+			return errors.Wrapf(err, "getting the ETH balance of:%v", account.Address.Hex())
+		}
+		balances = append(balances, ethBalance{Address: account.Address, Balance: balance})
+	}
+	gasPrice := self.gasPrice()
+	if gasPrice == nil {
+		if gasPrice, err = client.SuggestGasPrice(ctx); err != nil {
+			return errors.Wrap(err, "getting gas price")
+		}
+	}
+	fundingBalance, err := client.BalanceAt(ctx, funding.Address, nil)
+	if err != nil {
+		return errors.Wrap(err, "getting the ETH balance of the funding account")
+	}
+
+	plan := topupPlan(balances, below, target)
+	funds := &batchFunds{Balance: fundingBalance, Required: topupRequired(plan, gasPrice)}
+	return runBatch(logger, self.cfgBatch, plan, funds, topupSender(ctx, client, funding, gasPrice))
+}
+
+type ethBalance struct {
+	Address common.Address
+	Balance *big.Int
+}
+
+// topupPlan sends ETH to the accounts below the threshold so that they have the target balance.
+func topupPlan(balances []ethBalance, below, target *big.Int) []*batchResult {
+	var plan []*batchResult
+	for _, b := range balances {
+		r := &batchResult{Account: b.Address, Action: "topup", To: b.Address, Status: batchPlanned}
+		if b.Balance.Cmp(below) >= 0 {
+			r.Status, r.Note = batchSkipped, fmt.Sprintf("balance %v ETH is not below the threshold", math.BigInt18eToFloat(b.Balance))
+			plan = append(plan, r)
+			continue
+		}
+		r.amount = new(big.Int).Sub(target, b.Balance)
+		r.Amount = math.BigInt18eToFloat(r.amount)
+		plan = append(plan, r)
+	}
+	return plan
+}
+
+// topupRequired returns the ETH needed for all planned transfers including their gas.
+func topupRequired(plan []*batchResult, gasPrice *big.Int) *big.Int {
+	required := big.NewInt(0)
+	gas := new(big.Int).Mul(gasPrice, big.NewInt(ethTransferGas))
+	for _, r := range plan {
+		if r.Status != batchPlanned {
+			continue
+		}
+		required.Add(required, r.amount)
+		required.Add(required, gas)
+	}
+	return required
+}
+
+// topupSender returns the send function of the top up transfers.
+// All transfers are from the funding account so the nonce is increased locally
+// instead of waiting for every transaction to show up as pending.
+func topupSender(ctx context.Context, client ethereum.EthClient, funding *ethereum.Account, gasPrice *big.Int) func(*batchResult) (*types.Transaction, error) {
+	var auth *bind.TransactOpts
+	return func(r *batchResult) (*types.Transaction, error) {
+		if auth == nil {
+			var err error
+			auth, err = ethereum.PrepareEthTransaction(ctx, client, funding, gasPrice)
+			if err != nil {
+				return nil, errors.Wrap(err, "prepare ethereum transaction")
+			}
+		}
+		tx := types.NewTransaction(auth.Nonce.Uint64(), r.To, r.amount, ethTransferGas, auth.GasPrice, nil)
+		signed, err := auth.Signer(auth.From, tx)
+		if err != nil {
+			return nil, errors.Wrap(err, "signing the transaction")
+		}
+		if err := client.SendTransaction(ctx, signed); err != nil {
+			return nil, errors.Wrap(err, "sending the transaction")
+		}
+		auth.Nonce.Add(auth.Nonce, big.NewInt(1))
+		return signed, nil
+	}
+}
+
+type batchDepositCmd struct {
+	cfgBatch
+}
+
+func (self batchDepositCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	contract, err := contracts.NewITellor(client)
+	if err != nil {
+		return errors.Wrap(err, "create tellor contract instance")
+	}
+	accounts, err := ethereum.GetAccounts()
+	if err != nil {
+		return errors.Wrap(err, "getting accounts")
+	}
+	stakeAmt, err := contract.GetUintVar(&bind.CallOpts{Context: ctx}, ethereum.Keccak256([]byte("_STAKE_AMOUNT")))
+	if err != nil {
+		return errors.Wrap(err, "fetching stake amount")
+	}
+
+	var infos []*stake.Info
+	byAddr := make(map[common.Address]*ethereum.Account)
+	for _, account := range accounts {
+		byAddr[account.Address] = account
+		info, err := stake.GetInfo(ctx, contract, account.Address)
+		if err != nil {
+			return errors.Wrapf(err, "getting stake info for:%v", account.Address.Hex())
+		}
+		infos = append(infos, info)
+	}
+
+	plan := depositPlan(infos, stakeAmt, contract.Address)
+	return runBatch(logger, self.cfgBatch, plan, nil, func(r *batchResult) (*types.Transaction, error) {
+		auth, err := ethereum.PrepareEthTransaction(ctx, client, byAddr[r.Account], self.gasPrice())
+		if err != nil {
+			return nil, errors.Wrap(err, "prepare ethereum transaction")
+		}
+		return contract.DepositStake(auth)
+	})
+}
+
+// depositPlan deposits the stake of the accounts that are not staked or are locked for withdraw and have enough TRB.
+func depositPlan(infos []*stake.Info, stakeAmt *big.Int, tellor common.Address) []*batchResult {
+	var plan []*batchResult
+	for _, info := range infos {
+		r := &batchResult{
+			Account: info.Address,
+			Action:  "deposit",
+			To:      tellor,
+			Amount:  math.BigInt18eToFloat(stakeAmt),
+			Status:  batchPlanned,
+		}
+		switch {
+		case !info.CanDeposit():
+			r.Status, r.Note = batchSkipped, stake.StatusName(info.Status)
+		case info.Balance.Cmp(stakeAmt) < 0:
+			r.Status, r.Note = batchSkipped, fmt.Sprintf("insufficient TRB balance %v", math.BigInt18eToFloat(info.Balance))
+		}
+		plan = append(plan, r)
+	}
+	return plan
+}
@@ -0,0 +1,114 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/stake"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func trb(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func TestSweepPlan(t *testing.T) {
+	to := common.HexToAddress("0x1")
+	var infos []*stake.Info
+	for status := int64(stake.StatusNotStaked); status <= stake.StatusUnlocked; status++ {
+		infos = append(infos, &stake.Info{Address: common.BigToAddress(big.NewInt(status + 10)), Status: status, Balance: trb(600)})
+	}
+	// Only the stake without any other TRB.
+	infos = append(infos, &stake.Info{Address: common.HexToAddress("0x2"), Status: stake.StatusStaked, Balance: trb(500)})
+
+	plan := sweepPlan(infos, trb(500), to, 0)
+	testutil.Equals(t, len(infos), len(plan))
+	for i, expected := range []float64{600, 100, 100, 100, 100, 600} {
+		testutil.Equals(t, batchPlanned, plan[i].Status, "status:%v", infos[i].Status)
+		testutil.Equals(t, expected, plan[i].Amount, "status:%v", infos[i].Status)
+		testutil.Equals(t, to, plan[i].To)
+	}
+	testutil.Equals(t, batchSkipped, plan[6].Status)
+
+	plan = sweepPlan(infos, trb(500), to, 200)
+	testutil.Equals(t, batchSkipped, plan[1].Status, "below the minimum")
+	testutil.Equals(t, batchPlanned, plan[0].Status)
+}
+
+func TestDepositPlan(t *testing.T) {
+	tellor := common.HexToAddress("0x1")
+	plan := depositPlan([]*stake.Info{
+		{Address: common.HexToAddress("0x2"), Status: stake.StatusNotStaked, Balance: trb(500)},
+		{Address: common.HexToAddress("0x3"), Status: stake.StatusNotStaked, Balance: trb(499)},
+		{Address: common.HexToAddress("0x4"), Status: stake.StatusStaked, Balance: trb(500)},
+		{Address: common.HexToAddress("0x5"), Status: stake.StatusLockedForWithdraw, Balance: trb(500)},
+	}, trb(500), tellor)
+	testutil.Equals(t, batchPlanned, plan[0].Status)
+	testutil.Equals(t, float64(500), plan[0].Amount)
+	testutil.Equals(t, tellor, plan[0].To)
+	testutil.Equals(t, batchSkipped, plan[1].Status)
+	testutil.Equals(t, batchSkipped, plan[2].Status)
+	testutil.Equals(t, stake.StatusName(stake.StatusStaked), plan[2].Note)
+	testutil.Equals(t, batchPlanned, plan[3].Status, "the contract accepts a deposit for a stake locked for withdraw")
+}
+
+func TestTopup(t *testing.T) {
+	ctx := context.Background()
+	accounts, err := simulation.NewAccounts(4)
+	testutil.Ok(t, err)
+	funding := accounts[0]
+	cfg := simulation.Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Balance:    big.NewInt(1e17),
+	}
+	for _, acc := range accounts {
+		cfg.Accounts = append(cfg.Accounts, acc.Address)
+	}
+	backend, err := simulation.NewBackend(logging.NewLogger(), cfg)
+	testutil.Ok(t, err)
+
+	below, target := big.NewInt(2e17), big.NewInt(5e17)
+	plan := topupPlan([]ethBalance{
+		{Address: accounts[1].Address, Balance: big.NewInt(1e17)},
+		{Address: accounts[2].Address, Balance: big.NewInt(2e17)},
+		{Address: accounts[3].Address, Balance: big.NewInt(1e17)},
+	}, below, target)
+	testutil.Equals(t, batchPlanned, plan[0].Status)
+	testutil.Equals(t, 0.4, plan[0].Amount)
+	testutil.Equals(t, batchSkipped, plan[1].Status, "not below the threshold")
+	testutil.Equals(t, batchPlanned, plan[2].Status)
+
+	// The funds include the gas of each planned transfer.
+	gas := new(big.Int).Mul(simulation.GasPrice, big.NewInt(ethTransferGas))
+	required := topupRequired(plan, simulation.GasPrice)
+	testutil.Equals(t, new(big.Int).Add(big.NewInt(8e17), new(big.Int).Mul(gas, big.NewInt(2))), required)
+	testutil.NotOk(t, (&batchFunds{Balance: big.NewInt(8e17), Required: required}).check())
+	testutil.Ok(t, (&batchFunds{Balance: required, Required: required}).check())
+
+	// The transfers are sent before any is mined so each one needs the next nonce.
+	plan = topupPlan([]ethBalance{
+		{Address: accounts[1].Address, Balance: big.NewInt(1e17)},
+		{Address: accounts[2].Address, Balance: big.NewInt(1e17)},
+	}, below, big.NewInt(4e16+1e17))
+	send := topupSender(ctx, backend, funding, simulation.GasPrice)
+	for i, r := range plan {
+		tx, err := send(r)
+		testutil.Ok(t, err)
+		testutil.Equals(t, uint64(i), tx.Nonce())
+	}
+	testutil.Equals(t, 2, backend.Pending())
+	backend.Commit()
+	for _, acc := range accounts[1:3] {
+		balance, err := backend.BalanceAt(ctx, acc.Address, nil)
+		testutil.Ok(t, err)
+		testutil.Equals(t, big.NewInt(4e16+1e17), balance)
+	}
+}
@@ -4,7 +4,7 @@
 package cli
 
 import (
-	"github.com/go-kit/kit/log/level"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/ethereum"
@@ -19,25 +19,54 @@ const VersionMessage = `
 `
 
 var CLI struct {
+	Output string `enum:"logfmt,json,table" default:"logfmt" help:"format of the command results written to stdout - logfmt, json or table, logs are written to stderr"`
+
 	Transfer transferCmd `cmd:"" help:"Transfer tokens"`
 	Approve  approveCmd  `cmd:"" help:"Approve tokens"`
 	Accounts accountsCmd `cmd:"" help:"Show accounts"`
-	Balance  balanceCmd  `cmd:"" help:"Check the balance of an address"`
-	Stake    struct {
+	Account  struct {
+		New    accountNewCmd    `cmd:"" help:"create a new account in the keystore"`
+		Import accountImportCmd `cmd:"" help:"import a private key read from stdin into the keystore"`
+		Export accountExportCmd `cmd:"" help:"write the private key of a keystore account to stdout"`
+		List   accountListCmd   `cmd:"" help:"show the labels, balances, stake status and last submit time of the accounts"`
+	} `cmd:"" help:"Manage the keystore accounts"`
+	Balance balanceCmd `cmd:"" help:"Check the balance of an address"`
+	Stake   struct {
 		Deposit  depositCmd  `cmd:"" help:"deposit a stake"`
 		Request  requestCmd  `cmd:"" help:"request to withdraw stake"`
 		Withdraw withdrawCmd `cmd:"" help:"withdraw stake"`
-		Status   statusCmd   `cmd:"" help:"show stake status"`
+		Status   statusCmd   `cmd:"" help:"show the stake status of all accounts or of a single address"`
+		Schedule scheduleCmd `cmd:"" help:"request to withdraw stake, wait until it is unlocked and withdraw it"`
 	} `cmd:"" help:"Perform one of the stake operations"`
 	Dispute struct {
-		New   newDisputeCmd `cmd:"" help:"start a new dispute"`
-		Vote  voteCmd       `cmd:"" help:"vote on a open dispute"`
-		List  listCmd       `cmd:"" help:"list open disputes"`
-		Tally tallyCmd      `cmd:"" help:"tally votes for a dispute ID"`
+		New     newDisputeCmd    `cmd:"" help:"start a new dispute"`
+		Vote    voteCmd          `cmd:"" help:"vote on a open dispute"`
+		List    listCmd          `cmd:"" help:"list open disputes"`
+		Tally   tallyCmd         `cmd:"" help:"tally votes for a dispute ID"`
+		Confirm confirmCmd       `cmd:"" help:"confirm the recommended vote for a dispute ID when the voter policy requires a confirmation"`
+		Status  disputeStatusCmd `cmd:"" help:"show the disputes opened or voted by our accounts and their tally and fee unlock progress"`
 	} `cmd:"" help:"Perform commands related to disputes"`
 	Dataserver dataserverCmd `cmd:"" help:"launch only a dataserver instance"`
 	Mine       mineCmd       `cmd:"" help:"Submit data to oracle contracts"`
-	Version    VersionCmd    `cmd:"" help:"Show the CLI version information"`
+	Worker     workerCmd     `cmd:"" help:"Mine the challenges served by a remote mining pool"`
+	Batch      struct {
+		Sweep   batchSweepCmd   `cmd:"" help:"transfer the transferable TRB of all accounts to a single address"`
+		Topup   batchTopupCmd   `cmd:"" help:"send ETH from a funding account to all accounts with a balance below a threshold"`
+		Deposit batchDepositCmd `cmd:"" help:"deposit a stake for all accounts that are not staked or are locked for withdraw"`
+	} `cmd:"" help:"Perform operations on all accounts, shows the transactions and asks for a confirmation before sending them"`
+	Tx struct {
+		Sign      txSignCmd      `cmd:"" help:"sign a transaction written with --offline, needs only the private key and no node"`
+		Broadcast txBroadcastCmd `cmd:"" help:"send a transaction signed with 'tx sign'"`
+	} `cmd:"" help:"Sign and send transactions prepared in the offline mode"`
+	Eth struct {
+		Send ethSendCmd `cmd:"" help:"send ETH, retries with a higher gas price and waits until it is mined"`
+	} `cmd:"" help:"Perform ETH operations"`
+	Call   callCmd `cmd:"" help:"call a read only contract method and show the decoded result"`
+	Send   sendCmd `cmd:"" help:"send a transaction calling a contract method, retries with a higher gas price and waits until it is mined"`
+	Mining struct {
+		Bench miningBenchCmd `cmd:"" help:"measure the hash rate and the expected time to find a solution for the current difficulty"`
+	} `cmd:"" help:"Perform commands related to mining"`
+	Version VersionCmd `cmd:"" help:"Show the CLI version information"`
 }
 
 type VersionCmd struct {
@@ -59,7 +88,9 @@ type addr struct {
 
 type cfgGas struct {
 	cfg
-	GasPrice int `optional:"" help:"gas price to use when running the command"`
+	GasPrice int    `optional:"" help:"gas price to use when running the command"`
+	Offline  string `optional:"" help:"write the unsigned transaction to this file instead of sending it, sign it with 'tx sign' and send it with 'tx broadcast'"`
+	Nonce    int64  `optional:"" default:"-1" help:"nonce of the transaction, defaults to the pending nonce of the node so it is needed for several offline transactions that are not yet broadcast"`
 }
 
 type cfgGasAddr struct {
@@ -91,9 +122,14 @@ func (self *accountsCmd) Run() error {
 		return errors.Wrap(err, "getting accounts")
 	}
 
+	result := make([]accountResult, 0, len(accounts))
 	for i, account := range accounts {
-		level.Info(logger).Log("msg", "account", "no", i, "address", account.Address.String())
+		result = append(result, accountResult{No: i, Address: account.Address})
 	}
+	return output(result)
+}
 
-	return nil
+type accountResult struct {
+	No      int            `json:"no"`
+	Address common.Address `json:"address"`
 }
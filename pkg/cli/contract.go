@@ -0,0 +1,162 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	tEthereum "github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/math"
+)
+
+// contractMethod are the arguments of the commands that call a contract method.
+type contractMethod struct {
+	Contract string   `arg:"" help:"contract address or one of tellor, mesosphere, lens for the contracts of the current network"`
+	Method   string   `arg:"" help:"method signature like 'balanceOf(address)(uint256)', the output types are needed only to decode the result"`
+	Args     []string `arg:"" optional:"" help:"method arguments, lists in brackets like [1,2], bytes in hex"`
+}
+
+// address returns the contract address resolving the names of the Tellor contracts.
+func (self contractMethod) address(client tEthereum.EthClient) (common.Address, error) {
+	switch strings.ToLower(self.Contract) {
+	case "tellor":
+		return contracts.GetTellorAddress(client)
+	case "mesosphere":
+		return contracts.GetTellorMesosphereAddress(client)
+	case "lens":
+		return contracts.GetLensAddress(client)
+	}
+	return addr{Addr: self.Contract}.address()
+}
+
+type callResult struct {
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type callCmd struct {
+	cfg
+	contractMethod
+	From string `optional:"" help:"address to call the method from"`
+}
+
+func (self callCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	method, err := contracts.ParseMethod(self.Method)
+	if err != nil {
+		return err
+	}
+	data, err := contracts.PackCall(method, self.Args)
+	if err != nil {
+		return err
+	}
+
+	client, err := tEthereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	to, err := self.address(client)
+	if err != nil {
+		return err
+	}
+	msg := ethereum.CallMsg{To: &to, Data: data}
+	if self.From != "" {
+		if msg.From, err = (addr{Addr: self.From}).address(); err != nil {
+			return err
+		}
+	}
+	res, err := client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return errors.Wrapf(err, "calling %v", method.Sig)
+	}
+
+	if len(method.Outputs) == 0 {
+		level.Info(logger).Log("msg", "no output types in the method signature so the result is not decoded")
+		return output(callResult{Type: "bytes", Value: hexutil.Encode(res)})
+	}
+	values, err := method.Outputs.Unpack(res)
+	if err != nil {
+		return errors.Wrap(err, "decoding the result")
+	}
+	result := make([]callResult, len(values))
+	for i, v := range values {
+		result[i] = callResult{Index: i, Type: method.Outputs[i].Type.String(), Value: contracts.FormatValue(v)}
+	}
+	return output(result)
+}
+
+type sendCmd struct {
+	cfg
+	contractMethod
+	GasPrice int     `optional:"" help:"gas price in gwei, defaults to the gas station price"`
+	From     string  `required:""`
+	Value    float64 `optional:"" help:"ETH to send with the call of a payable method"`
+}
+
+func (self sendCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	from, err := addr{Addr: self.From}.address()
+	if err != nil {
+		return err
+	}
+	value, err := math.FloatToBigInt18e(self.Value)
+	if err != nil {
+		return errors.Wrap(err, "invalid value")
+	}
+	if value.Sign() < 0 {
+		return errors.Errorf("the value should not be negative:%v", self.Value)
+	}
+	method, err := contracts.ParseMethod(self.Method)
+	if err != nil {
+		return err
+	}
+	data, err := contracts.PackCall(method, self.Args)
+	if err != nil {
+		return err
+	}
+
+	client, err := tEthereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	to, err := self.address(client)
+	if err != nil {
+		return err
+	}
+
+	tx, receipt, err := transact(ctx, logger, cfg, client, from, self.GasPrice, to, value, data)
+	if err != nil {
+		return err
+	}
+	level.Info(logger).Log("msg", "contract method called",
+		"method", method.Sig,
+		"contract", to.Hex(),
+		"block", receipt.BlockNumber,
+	)
+	return output(txResult{Action: method.Sig, Status: txMined, From: from, Nonce: tx.Nonce(), Tx: tx.Hash().Hex()})
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
+	"github.com/tellor-io/telliot/pkg/gasPrice/gasStation"
 	"github.com/tellor-io/telliot/pkg/logging"
 	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
 	"github.com/tellor-io/telliot/pkg/tracker/dispute"
@@ -99,12 +101,29 @@ func (self dataserverCmd) Run() error {
 			return errors.Wrap(err, "create tellor contract instance")
 		}
 
+		// Event hub.
+		hub, err := events.New(logger, ctx, cfg.EventHub, client, contractTellor.Address)
+		if err != nil {
+			return errors.Wrap(err, "creating event hub")
+		}
+		g.Add(func() error {
+			err := hub.Start()
+			level.Info(logger).Log("msg", "event hub shutdown complete")
+			return err
+		}, func(error) {
+			hub.Stop()
+		})
+
 		// Reward tracker.
 		accounts, err := ethereum.GetAccounts()
 		if err != nil {
 			return errors.Wrap(err, "getting accounts")
 		}
-		rewardTracker, err := reward.NewRewardTracker(logger, ctx, cfg.RewardTracker, tsDB, client, contractTellor, accounts[0].Address, aggregator)
+		gasPriceQuerier, err := gasStation.New(logger, cfg.GasStation, client)
+		if err != nil {
+			return errors.Wrap(err, "creating gas price tracker")
+		}
+		rewardTracker, err := reward.NewRewardTracker(logger, ctx, cfg.RewardTracker, tsDB, client, contractTellor, hub, accounts[0].Address, aggregator, gasPriceQuerier)
 		if err != nil {
 			return errors.Wrap(err, "creating reward tracker")
 		}
@@ -123,6 +142,7 @@ func (self dataserverCmd) Run() error {
 			tsDB,
 			client,
 			contractTellor,
+			hub,
 			psrTellor.New(logger, cfg.PsrTellor, aggregator),
 		)
 		if err != nil {
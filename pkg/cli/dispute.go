@@ -6,17 +6,24 @@ package cli
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/ethereum"
 	tEthereum "github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
 	"github.com/tellor-io/telliot/pkg/math"
+	"github.com/tellor-io/telliot/pkg/tracker/dispute"
+	"github.com/tellor-io/telliot/pkg/tracker/lifecycle"
+	"github.com/tellor-io/telliot/pkg/voter"
 )
 
 type disputeID struct {
@@ -44,7 +51,7 @@ func (self newDisputeCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	addr, err := self.address()
 	if err != nil {
 		return err
 	}
@@ -57,7 +64,7 @@ func (self newDisputeCmd) Run() error {
 		return errors.Errorf("miner index should be between 0 and 4 (got %v)", self.MinerIndex)
 	}
 
-	balance, err := contract.BalanceOf(&bind.CallOpts{Context: ctx}, account.Address)
+	balance, err := contract.BalanceOf(&bind.CallOpts{Context: ctx}, addr)
 	if err != nil {
 		return errors.Wrap(err, "fetch balance")
 	}
@@ -73,22 +80,16 @@ func (self newDisputeCmd) Run() error {
 			math.BigInt18eToFloat(disputeCost))
 	}
 
-	var gasPrice *big.Int
-	if self.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
-	}
-
-	auth, err := tEthereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := self.newTransaction(ctx, client, addr)
 	if err != nil {
-		return errors.Wrapf(err, "prepare ethereum transaction")
+		return err
 	}
 
-	tx, err := contract.BeginDispute(auth, big.NewInt(self.RequestID), big.NewInt(self.Timestamp), big.NewInt(self.MinerIndex))
+	tx, err := contract.BeginDispute(auth.TransactOpts, big.NewInt(self.RequestID), big.NewInt(self.Timestamp), big.NewInt(self.MinerIndex))
 	if err != nil {
 		return errors.Wrap(err, "send dispute txn")
 	}
-	level.Info(logger).Log("msg", "dispute started", "tx", tx.Hash())
-	return nil
+	return auth.done(logger, tx, "dispute", "requestId", self.RequestID, "timestamp", self.Timestamp, "minerIndex", self.MinerIndex)
 }
 
 type voteCmd struct {
@@ -111,7 +112,7 @@ func (self voteCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	addr, err := self.address()
 	if err != nil {
 		return err
 	}
@@ -120,36 +121,29 @@ func (self voteCmd) Run() error {
 		return errors.Wrap(err, "create tellor contract instance")
 	}
 
-	voted, err := contract.DidVote(&bind.CallOpts{Context: ctx}, big.NewInt(self.DisputeID), account.Address)
+	voted, err := contract.DidVote(&bind.CallOpts{Context: ctx}, big.NewInt(self.DisputeID), addr)
 	if err != nil {
 		return errors.Wrapf(err, "check if you've already voted")
 	}
 	if voted {
-		level.Info(logger).Log("msg", "you have already voted on this dispute")
-		return nil
+		return skipped(logger, "vote", addr, "already voted on the dispute")
 	}
 
-	var gasPrice *big.Int
-	if self.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
-	}
-
-	auth, err := tEthereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := self.newTransaction(ctx, client, addr)
 	if err != nil {
-		return errors.Wrapf(err, "prepare ethereum transaction")
+		return err
 	}
-	tx, err := contract.Vote(auth, big.NewInt(self.DisputeID), self.Support)
+	tx, err := contract.Vote(auth.TransactOpts, big.NewInt(self.DisputeID), self.Support)
 	if err != nil {
 		return errors.Wrapf(err, "submit vote transaction")
 	}
-
-	level.Info(logger).Log("msg", "vote submitted with transaction", "tx", tx.Hash())
-	return nil
+	return auth.done(logger, tx, "vote", "id", self.DisputeID, "support", self.Support)
 }
 
 type tallyCmd struct {
 	cfgGas
 	disputeID
+	From string `optional:"" help:"address that sends the tally, defaults to the first account and is required in the offline mode"`
 }
 
 func (self tallyCmd) Run() error {
@@ -166,262 +160,287 @@ func (self tallyCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	accounts, err := ethereum.GetAccounts()
-	if err != nil {
-		return err
+	var from common.Address
+	switch {
+	case self.From != "":
+		if !common.IsHexAddress(self.From) {
+			return errors.Errorf("invalid etherum address:%v", self.From)
+		}
+		from = common.HexToAddress(self.From)
+	case self.Offline != "":
+		return errors.New("the sender address is required in the offline mode")
+	default:
+		accounts, err := ethereum.GetAccounts()
+		if err != nil {
+			return err
+		}
+		from = accounts[0].Address
 	}
 	contract, err := contracts.NewITellor(client)
 	if err != nil {
 		return errors.Wrap(err, "create tellor contract instance")
 	}
 
-	var gasPrice *big.Int
-	if self.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
-	}
-
-	auth, err := tEthereum.PrepareEthTransaction(ctx, client, accounts[0], gasPrice)
+	auth, err := self.newTransaction(ctx, client, from)
 	if err != nil {
-		return errors.Wrapf(err, "prepare ethereum transaction")
+		return err
 	}
 
-	tx, err := contract.TallyVotes(auth, big.NewInt(self.DisputeID))
+	tx, err := contract.TallyVotes(auth.TransactOpts, big.NewInt(self.DisputeID))
 	if err != nil {
 		return errors.Wrapf(err, "run tally votes if you've already voted")
 	}
+	return auth.done(logger, tx, "tally", "id", self.DisputeID)
+}
 
-	level.Info(logger).Log("msg", "tally votes submitted", "tx", tx.Hash().Hex())
-	return nil
+type confirmCmd struct {
+	cfg
+	disputeID
+}
+
+func (self confirmCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	entry := voter.Entry{Time: time.Now(), DisputeID: self.DisputeID, Action: voter.ActionConfirmed}
+	if err := voter.Audit(cfg.Voter.AuditLog, entry); err != nil {
+		return err
+	}
+	level.Info(logger).Log("msg", "vote confirmed, the voter casts it on the next check", "id", self.DisputeID)
+	return output(entry)
 }
 
 type listCmd struct {
 	cfgAddr
 }
 
+// disputeScanWindow is how far in the past to look for disputes.
+// The voting lasts 7 days so this covers the disputes that are still open or waiting for a tally.
+const disputeScanWindow = 10 * 24 * time.Hour
+
 func (self listCmd) Run() error {
-	// TODO fix it!
-
-	// logger := logging.NewLogger()
-	// ctx := context.Background()
-
-	// cfg, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
-	// if err != nil {
-	// 	return errors.Wrap(err, "creating config")
-	// }
-
-	// client, err := ethereum.NewClient(ctx, logger)
-	// if err != nil {
-	// 	return errors.Wrap(err, "creating ethereum client")
-	// }
-	// account, err := ethereum.GetAccountByPubAddess(self.Addr)
-	// if err != nil {
-	// 	return err
-	// }
-
-	// // Open the TSDB database.
-	// var querable storage.SampleAndChunkQueryable
-	// if cfg.Db.RemoteHost != "" {
-	// 	querable, err = db.NewRemoteDB(cfg.Db)
-	// 	if err != nil {
-	// 		return errors.Wrap(err, "opening remote tsdb DB")
-	// 	}
-	// } else {
-	// 	if err := os.MkdirAll(cfg.Db.Path, 0777); err != nil {
-	// 		return errors.Wrap(err, "creating tsdb DB folder")
-	// 	}
-	// 	tsdbOptions := tsdb.DefaultOptions()
-	// 	tsdbOptions.NoLockfile = true
-	// 	querable, err = tsdb.Open(cfg.Db.Path, nil, nil, tsdbOptions)
-	// 	if err != nil {
-	// 		return errors.Wrap(err, "opening tsdb DB")
-	// 	}
-	// }
-
-	// aggregator, err := aggregator.New(logger, ctx, cfg.Aggregator, querable)
-	// if err != nil {
-	// 	return errors.Wrap(err, "creating aggregator")
-	// }
-
-	// psr := psrTellor.New(logger, cfg.PsrTellor, aggregator)
-	// contract, err := contracts.NewITellor(client)
-	// if err != nil {
-	// 	return errors.Wrap(err, "create tellor contract instance")
-	// }
-
-	// abi, err := abi.JSON(strings.NewReader(contracts.ITellorABI))
-	// if err != nil {
-	// 	return errors.Wrap(err, "parse abi")
-	// }
-
-	// // Just use nil for most of the variables, only using this object to call UnpackLog which only uses the abi.
-	// bar := bind.NewBoundContract(contract.Address, abi, nil, nil, nil)
-
-	// header, err := client.HeaderByNumber(ctx, nil)
-	// if err != nil {
-	// 	return errors.Wrap(err, "get latest eth block header")
-	// }
-
-	// startBlock := big.NewInt(10e3 * 14) // TODO instead of this calculate only 10 days worth of blocks in the past since disputes can be voted only 7 days in the past.
-	// startBlock.Sub(header.Number, startBlock)
-	// newDisputeID := abi.Events["NewDispute"].ID
-	// query := ethereum.FilterQuery{
-	// 	FromBlock: startBlock,
-	// 	ToBlock:   nil,
-	// 	Addresses: []common.Address{contract.Address},
-	// 	Topics:    [][]common.Hash{{newDisputeID}},
-	// }
-
-	// logs, err := client.FilterLogs(ctx, query)
-	// if err != nil {
-	// 	return errors.Wrap(err, "filter eth logs")
-	// }
-
-	// level.Info(logger).Log("msg", "get currently open disputes", "open", len(logs))
-	// for _, rawDispute := range logs {
-	// 	disputeI := contracts.ITellorNewDispute{}
-	// 	err := bar.UnpackLog(&disputeI, "NewDispute", rawDispute)
-	// 	if err != nil {
-	// 		return errors.Wrap(err, "unpack dispute event from logs")
-	// 	}
-	// 	_, executed, votePassed, _, reportedAddr, reportingMiner, _, uintVars, currTally, err := contract.GetAllDisputeVars(&bind.CallOpts{Context: ctx}, disputeI.DisputeId)
-	// 	if err != nil {
-	// 		return errors.Wrap(err, "get dispute details")
-	// 	}
-
-	// 	votingEnds := time.Unix(uintVars[3].Int64(), 0)
-	// 	createdTime := votingEnds.Add(-7 * 24 * time.Hour)
-
-	// 	var descString string
-	// 	if executed {
-	// 		descString = "complete, "
-	// 		if votePassed {
-	// 			descString += "successful"
-	// 		} else {
-	// 			descString += "rejected"
-	// 		}
-	// 	} else {
-	// 		descString = "in progress"
-	// 	}
-
-	// 	level.Info(logger).Log(
-	// 		"msg", "dispute occurred",
-	// 		"disputeId", disputeI.DisputeId.String(),
-	// 		"reportedAddr", reportedAddr.Hex(),
-	// 		"reportingMiner", reportingMiner.Hex(),
-	// 		"createdTime", createdTime.Format("3:04 PM January 02, 2006 MST"),
-	// 		"fee", math.BigInt18eToFloat(uintVars[8]),
-	// 		"requestId", disputeI.RequestId.Uint64(),
-	// 	)
-
-	// 	allSubmitted, err := getNonceSubmits(ctx, client, contract, uintVars[5], &disputeI)
-	// 	if err != nil {
-	// 		return errors.Wrapf(err, "get the values submitted by other miners for the disputed block")
-	// 	}
-	// 	disputedValTime := allSubmitted[uintVars[6].Uint64()].Time
-
-	// 	for i := len(allSubmitted) - 1; i >= 0; i-- {
-	// 		sub := allSubmitted[i]
-	// 		valStr := fmt.Sprintf("%f\n", sub.float64)
-	// 		var pointerStr string
-	// 		if i == int(uintVars[6].Uint64()) {
-	// 			pointerStr = " <--disputed"
-	// 		}
-
-	// 		level.Debug(logger).Log(
-	// 			"msg", "sub created",
-	// 			"valStr", valStr,
-	// 			"created", sub.Time.Format("3:04:05 PM"),
-	// 			"pointerStr", pointerStr,
-	// 		)
-	// 	}
-
-	// 	tmp := new(big.Float)
-	// 	tmp.SetInt(currTally)
-	// 	currTallyFloat, _ := tmp.Float64()
-	// 	tmp.SetInt(uintVars[7])
-	// 	currQuorum, _ := tmp.Float64()
-	// 	currTallyFloat += currQuorum
-	// 	currTallyRatio := currTallyFloat / 2 * currQuorum
-
-	// 	level.Info(logger).Log(
-	// 		"msg", "current TRB support for this dispute",
-	// 		"currTallyRatio", fmt.Sprintf("%0.f%%", currTallyRatio*100),
-	// 		"TRB", math.BigInt18eToFloat(uintVars[7]),
-	// 		"votes", uintVars[4],
-	// 	)
-
-	// 	header, err := client.HeaderByNumber(ctx, nil)
-	// 	if err != nil {
-	// 		return errors.Wrap(err, "get latest eth block header")
-	// 	}
-
-	// 	disputer := dispute.NewDisputeChecker(
-	// 		logger,
-	// 		cfg,
-	// 		client,
-	// 		contract,
-	// 		header.Number.Uint64(),
-	// 		psr,
-	// 	)
-
-	// 	result, err := disputer.CheckValueAtTime(disputeI.RequestId.Int64(), uintVars[2], disputedValTime)
-	// 	if err != nil {
-	// 		return err
-	// 	} else if result == nil || len(result.Datapoints) < 0 {
-	// 		level.Info(logger).Log("msg", "no data available for recommendation")
-	// 		continue
-	// 	}
-	// 	level.Info(logger).Log(
-	// 		"msg", "got recommendation",
-	// 		"vote", !result.WithinRange,
-	// 		"subValue", uintVars[2].String(),
-	// 		"range", strconv.Itoa(int(result.Low))+"to"+strconv.Itoa(int(result.High)),
-	// 	)
-
-	// 	numToShow := 3
-	// 	if numToShow > len(result.Datapoints) {
-	// 		numToShow = len(result.Datapoints)
-	// 	}
-	// 	level.Info(logger).Log(
-	// 		"msg", "recommedation based on",
-	// 		"datapoints", len(result.Datapoints),
-	// 		"deltaMinutes", cfg.DisputeTimeDelta.Duration.Minutes(),
-	// 		"closest", numToShow,
-	// 	)
-	// 	minTotalDelta := time.Duration(math.MaxInt64)
-	// 	index := 0
-	// 	for i := 0; i < len(result.Datapoints)-numToShow; i++ {
-	// 		totalDelta := time.Duration(0)
-	// 		for j := 0; j < numToShow; j++ {
-	// 			delta := result.Times[i+j].Sub(disputedValTime)
-	// 			if delta < 0 {
-	// 				delta = -delta
-	// 			}
-	// 			totalDelta += delta
-	// 		}
-	// 		if totalDelta < minTotalDelta {
-	// 			minTotalDelta = totalDelta
-	// 			index = i
-	// 		}
-	// 	}
-	// 	for i := 0; i < numToShow; i++ {
-	// 		dp := result.Datapoints[index+i]
-	// 		t := result.Times[index+i]
-	// 		level.Info(logger).Log("msg", "check datapoint", "dp", dp)
-	// 		delta := disputedValTime.Sub(t)
-	// 		if delta > 0 {
-	// 			level.Info(logger).Log(
-	// 				"msg", "check delta before",
-	// 				"seconds", fmt.Sprintf("%.0f", delta.Seconds()),
-	// 			)
-	// 		} else {
-	// 			level.Info(logger).Log(
-	// 				"msg", "check delta after",
-	// 				"seconds", fmt.Sprintf("%.0f", (-delta).Seconds()),
-	// 			)
-	// 		}
-	// 	}
-	// }
-
-	return nil
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	if err != nil {
+		return err
+	}
+	contract, err := contracts.NewITellor(client)
+	if err != nil {
+		return errors.Wrap(err, "create tellor contract instance")
+	}
+
+	// Open the TSDB database with the values recorded by the dispute tracker.
+	// The local one is opened read only so it can be used while the miner is running.
+	var querable storage.Queryable
+	if cfg.Db.RemoteHost != "" {
+		querable, err = db.NewRemoteDB(cfg.Db)
+		if err != nil {
+			return errors.Wrap(err, "opening remote tsdb DB")
+		}
+	} else {
+		tsDB, err := tsdb.OpenDBReadOnly(cfg.Db.Path, logger)
+		if err != nil {
+			return errors.Wrap(err, "opening local tsdb DB")
+		}
+		defer tsDB.Close()
+		querable = tsDB
+	}
+
+	startBlock, err := ethereum.BlockBefore(ctx, client, disputeScanWindow)
+	if err != nil {
+		return errors.Wrap(err, "estimating the dispute scan start block")
+	}
+	disputes, err := contract.FilterNewDispute(&bind.FilterOpts{Context: ctx, Start: startBlock}, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "filter dispute logs")
+	}
+	defer disputes.Close()
+
+	result := []disputeResult{}
+	for disputes.Next() {
+		event := disputes.Event
+		_, executed, passed, _, reportedMiner, reportingParty, _, uintVars, tally, err := contract.GetAllDisputeVars(&bind.CallOpts{Context: ctx}, event.DisputeId)
+		if err != nil {
+			return errors.Wrapf(err, "get dispute details id:%v", event.DisputeId)
+		}
+		voted, err := contract.DidVote(&bind.CallOpts{Context: ctx}, event.DisputeId, account.Address)
+		if err != nil {
+			return errors.Wrapf(err, "check if voted on dispute id:%v", event.DisputeId)
+		}
+
+		votingEnds := time.Unix(uintVars[3].Int64(), 0)
+		timeLeft := time.Until(votingEnds).Round(time.Minute)
+		var status string
+		switch {
+		case executed && passed:
+			status = "passed"
+		case executed:
+			status = "failed"
+		case timeLeft <= 0:
+			status = "awaiting tally"
+			timeLeft = 0
+		default:
+			status = "open"
+		}
+
+		d := disputeResult{
+			ID:             event.DisputeId.Int64(),
+			Status:         status,
+			RequestID:      uintVars[0].Int64(),
+			Timestamp:      uintVars[1].Int64(),
+			Value:          uintVars[2].Int64(),
+			ReportedMiner:  reportedMiner,
+			ReportingParty: reportingParty,
+			Fee:            math.BigInt18eToFloat(uintVars[8]),
+			Tally:          math.BigInt18eToFloat(tally),
+			Quorum:         math.BigInt18eToFloat(uintVars[7]),
+			Votes:          uintVars[4].Int64(),
+			VotingEnds:     votingEnds,
+			TimeLeft:       timeLeft.String(),
+			Voted:          voted,
+			Tolerance:      cfg.DisputeTracker.RequestTolerance(uintVars[0].Int64()),
+		}
+		if executed {
+			result = append(result, d)
+			continue
+		}
+
+		valueTime := time.Unix(uintVars[1].Int64(), 0)
+		recommendation, err := dispute.Recommend(
+			ctx,
+			querable,
+			uintVars[0].Int64(),
+			float64(uintVars[2].Int64()),
+			valueTime,
+			cfg.DisputeTracker.Window.Duration,
+			cfg.DisputeTracker.RequestTolerance(uintVars[0].Int64()),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "recommendation for dispute id:%v", event.DisputeId)
+		}
+		if recommendation == nil {
+			level.Info(logger).Log("msg", "no recorded values for a recommendation", "id", event.DisputeId)
+			result = append(result, d)
+			continue
+		}
+		d.Support = &recommendation.Support
+		d.PSRValue = recommendation.PSRValue
+		d.PSRTime = recommendation.PSRTime
+		d.Deviation = recommendation.Deviation
+		for _, dp := range recommendation.Datapoints {
+			level.Info(logger).Log(
+				"msg", "datapoint",
+				"id", event.DisputeId,
+				"metric", dp.Metric,
+				"miner", dp.Miner,
+				"value", dp.Value,
+				"offset", dp.Time.Sub(valueTime).Round(time.Second),
+			)
+		}
+		result = append(result, d)
+	}
+	if err := disputes.Error(); err != nil {
+		return errors.Wrap(err, "iterating the dispute logs")
+	}
+	level.Info(logger).Log("msg", "disputes found", "count", len(result), "since", time.Now().Add(-disputeScanWindow).Format(time.RFC3339))
+	return output(result)
+}
+
+type disputeResult struct {
+	ID             int64          `json:"id"`
+	Status         string         `json:"status"`
+	RequestID      int64          `json:"requestId"`
+	Timestamp      int64          `json:"timestamp"`
+	Value          int64          `json:"value"`
+	ReportedMiner  common.Address `json:"reportedMiner"`
+	ReportingParty common.Address `json:"reportingParty"`
+	Fee            float64        `json:"fee"`
+	Tally          float64        `json:"tally"`
+	Quorum         float64        `json:"quorum"`
+	Votes          int64          `json:"votes"`
+	VotingEnds     time.Time      `json:"votingEnds"`
+	TimeLeft       string         `json:"timeLeft"`
+	Voted          bool           `json:"voted"`
+	// Support is the recommended vote, it is not set for the tallied disputes
+	// and when there are no recorded values for a recommendation.
+	Support   *bool     `json:"support"`
+	PSRValue  float64   `json:"psrValue"`
+	PSRTime   time.Time `json:"psrTime"`
+	Deviation float64   `json:"deviationPercent"`
+	Tolerance float64   `json:"tolerancePercent"`
+}
+
+type disputeStatusCmd struct {
+	cfg
+}
+
+func (self disputeStatusCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+	disputes, err := lifecycle.LoadState(cfg.DisputeLifecycle.StateFile)
+	if err != nil {
+		return err
+	}
+	if len(disputes) == 0 {
+		level.Info(logger).Log("msg", "no tracked disputes, these are tracked by the mine command when DisputeLifecycle is enabled")
+	}
+
+	result := make([]disputeStatusResult, 0, len(disputes))
+	for _, d := range disputes {
+		status := disputeStatusResult{
+			ID:        d.ID,
+			RequestID: d.RequestID,
+			Miner:     d.Miner,
+			Reporter:  d.Reporter,
+			Voters:    len(d.Voters),
+			Fee:       d.Fee,
+			Stage:     d.Stage,
+			Passed:    d.Passed,
+			Updated:   d.Updated,
+		}
+		switch d.Stage {
+		case lifecycle.StageVoting:
+			status.Due = d.VotingEnds
+		case lifecycle.StageTally:
+			status.Tx = d.TallyTx
+		case lifecycle.StageUnlock:
+			status.Due, status.Tx = d.UnlockAt, d.UnlockTx
+		}
+		result = append(result, status)
+	}
+	return output(result)
+}
+
+type disputeStatusResult struct {
+	ID        int64   `json:"id"`
+	RequestID int64   `json:"requestId"`
+	Miner     string  `json:"miner"`
+	Reporter  string  `json:"reporter"`
+	Voters    int     `json:"voters"`
+	Fee       float64 `json:"fee"`
+	Stage     string  `json:"stage"`
+	Passed    bool    `json:"passed"`
+	// Due is when the voting ends or the fee can be unlocked.
+	Due time.Time `json:"due"`
+	// Tx is the last tally or unlock transaction.
+	Tx      string    `json:"tx"`
+	Updated time.Time `json:"updated"`
 }
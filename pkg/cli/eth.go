@@ -0,0 +1,228 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	tEthereum "github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/gasPrice"
+	"github.com/tellor-io/telliot/pkg/gasPrice/gasStation"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/math"
+	"github.com/tellor-io/telliot/pkg/transactor"
+)
+
+type ethSendCmd struct {
+	cfg
+	GasPrice int     `optional:"" help:"gas price in gwei, defaults to the gas station price"`
+	From     string  `required:""`
+	To       string  `required:""`
+	Amount   float64 `arg:"" help:"amount in ETH"`
+}
+
+func (self ethSendCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	from, err := addr{Addr: self.From}.address()
+	if err != nil {
+		return err
+	}
+	to, err := addr{Addr: self.To}.address()
+	if err != nil {
+		return err
+	}
+	amount, err := math.FloatToBigInt18e(self.Amount)
+	if err != nil {
+		return errors.Wrap(err, "invalid input amount")
+	}
+	if amount.Sign() <= 0 {
+		return errors.Errorf("the amount should be positive:%v", self.Amount)
+	}
+
+	client, err := tEthereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+
+	tx, receipt, err := transact(ctx, logger, cfg, client, from, self.GasPrice, to, amount, nil)
+	if err != nil {
+		return err
+	}
+	level.Info(logger).Log("msg", "ETH sent",
+		"amount", math.BigInt18eToFloat(amount),
+		"to", to.Hex(),
+		"block", receipt.BlockNumber,
+	)
+	return output(txResult{Action: "send", Status: txMined, From: from, Nonce: tx.Nonce(), Tx: tx.Hash().Hex()})
+}
+
+// transact sends the value and call data from the given address through the transactor
+// which retries with a higher gas price and waits until the transaction is mined.
+// A zero gas price uses the gas station price.
+// It fails before sending anything when the checks of checkTransaction fail.
+func transact(
+	ctx context.Context,
+	logger log.Logger,
+	cfg *config.Config,
+	client tEthereum.EthClient,
+	from common.Address,
+	gasPriceGwei int,
+	to common.Address,
+	value *big.Int,
+	data []byte,
+) (*types.Transaction, *types.Receipt, error) {
+	account, err := tEthereum.GetAccountByPubAddess(from.Hex())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var querier gasPrice.GasPriceQuerier
+	if gasPriceGwei > 0 {
+		querier = fixedGasPrice(gasPriceGwei)
+	} else {
+		querier, err = gasStation.New(logger, cfg.GasStation, client)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "creating gas price querier")
+		}
+	}
+
+	gasPrice, err := querier.Query(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting gas price")
+	}
+	if mul := cfg.Transactor.GasMultiplier; mul > 0 {
+		gasPrice = new(big.Int).Mul(gasPrice, big.NewInt(int64(mul)))
+	}
+	gas, err := checkTransaction(ctx, client, gasPrice, ethereum.CallMsg{From: from, To: &to, Value: value, Data: data})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tr, err := transactor.New(logger, cfg.Transactor, querier, client, account)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "creating transactor")
+	}
+	sender := &rawSender{
+		ctx:    ctx,
+		logger: logger,
+		client: client,
+		// Only the address is used for raw calls so no ABI is needed.
+		contract: bind.NewBoundContract(to, abi.ABI{}, client, client, client),
+		value:    value,
+		gas:      gas,
+		data:     data,
+	}
+	tx, receipt, err := tr.Transact(ctx, sender.send)
+	if err != nil {
+		return nil, nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return tx, receipt, errors.Errorf("transaction reverted tx:%v", tx.Hash().Hex())
+	}
+	return tx, receipt, nil
+}
+
+// rawSender sends the value and call data for the transactor.
+// A send that returned an error might still be mined so before every retry
+// the sent transactions are checked and a retry never takes a new nonce
+// which would transfer the value again.
+type rawSender struct {
+	ctx      context.Context
+	logger   log.Logger
+	client   tEthereum.EthClient
+	contract *bind.BoundContract
+	value    *big.Int
+	gas      uint64
+	data     []byte
+	sent     []*types.Transaction
+}
+
+func (self *rawSender) send(auth *bind.TransactOpts) (*types.Transaction, error) {
+	for _, tx := range self.sent {
+		if _, err := self.client.TransactionReceipt(self.ctx, tx.Hash()); err == nil {
+			level.Info(self.logger).Log("msg", "an already sent transaction was mined", "tx", tx.Hash().Hex())
+			return tx, nil
+		}
+	}
+	if len(self.sent) > 0 && auth.Nonce.Uint64() != self.sent[0].Nonce() {
+		return nil, errors.Errorf("the nonce:%v of the sent transaction is used by another transaction", self.sent[0].Nonce())
+	}
+
+	relay := auth.NoSend // The relay does the sending.
+	auth.Value = self.value
+	auth.GasLimit = self.gas
+	auth.NoSend = true
+	tx, err := self.contract.RawTransact(auth, self.data)
+	if err != nil || relay {
+		return tx, err
+	}
+	// Recorded before sending as it might be delivered even when the send fails.
+	self.sent = append(self.sent, tx)
+	if err := self.client.SendTransaction(self.ctx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// checkTransaction returns the estimated gas of the transaction.
+// The transactor uses the confirmed nonce to replace stuck transactions
+// so it fails when the account has pending transactions.
+// It also fails when the balance doesn't cover the value and the gas
+// since the transactor would only keep retrying.
+func checkTransaction(ctx context.Context, client tEthereum.EthClient, gasPrice *big.Int, msg ethereum.CallMsg) (uint64, error) {
+	nonce, err := client.NonceAt(ctx, msg.From, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "getting nonce")
+	}
+	pendingNonce, err := client.PendingNonceAt(ctx, msg.From)
+	if err != nil {
+		return 0, errors.Wrap(err, "getting pending nonce")
+	}
+	if pendingNonce > nonce {
+		return 0, errors.Errorf("account has %v pending transactions which would be replaced, wait until these are mined", pendingNonce-nonce)
+	}
+
+	// Estimated so that transfers to contracts get enough gas and a reverting call fails before it is sent.
+	gas, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, errors.Wrap(err, "estimating gas")
+	}
+	cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
+	cost.Add(cost, msg.Value)
+	balance, err := client.BalanceAt(ctx, msg.From, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "get balance")
+	}
+	if balance.Cmp(cost) < 0 {
+		return 0, errors.Errorf("insufficient balance ETH actual: %v, required for the value and gas: %v",
+			math.BigInt18eToFloat(balance),
+			math.BigInt18eToFloat(cost))
+	}
+	return gas, nil
+}
+
+// fixedGasPrice is a gas price querier that always returns the same gas price in gwei.
+type fixedGasPrice int
+
+func (self fixedGasPrice) Query(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(int64(self) * params.GWei), nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestCheckTransaction(t *testing.T) {
+	ctx := context.Background()
+	accounts, err := simulation.NewAccounts(2)
+	testutil.Ok(t, err)
+	from, to := accounts[0].Address, accounts[1].Address
+	balance := big.NewInt(1e18)
+	backend, err := simulation.NewBackend(logging.NewLogger(), simulation.Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Accounts:   []common.Address{from, to},
+		Balance:    balance,
+	})
+	testutil.Ok(t, err)
+
+	gasCost := new(big.Int).Mul(simulation.GasPrice, big.NewInt(int64(params.TxGas)))
+	value := new(big.Int).Sub(balance, gasCost)
+	gas, err := checkTransaction(ctx, backend, simulation.GasPrice, ethereum.CallMsg{From: from, To: &to, Value: value})
+	testutil.Ok(t, err)
+	testutil.Equals(t, params.TxGas, gas)
+
+	_, err = checkTransaction(ctx, backend, simulation.GasPrice, ethereum.CallMsg{From: from, To: &to, Value: balance})
+	testutil.NotOk(t, err, "the balance should cover the gas as well")
+
+	// A pending transaction would be replaced by the transactor.
+	chainID, err := backend.NetworkID(ctx)
+	testutil.Ok(t, err)
+	tx, err := accounts[0].NewTransactor(chainID).Signer(from, types.NewTransaction(0, to, big.NewInt(1), params.TxGas, simulation.GasPrice, nil))
+	testutil.Ok(t, err)
+	testutil.Ok(t, backend.SendTransaction(ctx, tx))
+	_, err = checkTransaction(ctx, backend, simulation.GasPrice, ethereum.CallMsg{From: from, To: &to, Value: big.NewInt(1)})
+	testutil.NotOk(t, err, "the account has a pending transaction")
+
+	backend.Commit()
+	_, err = checkTransaction(ctx, backend, simulation.GasPrice, ethereum.CallMsg{From: from, To: &to, Value: big.NewInt(1)})
+	testutil.Ok(t, err)
+}
+
+// failingSendBackend delivers the transactions, but returns an error for the first sends.
+type failingSendBackend struct {
+	*simulation.Backend
+	fails int
+}
+
+func (self *failingSendBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := self.Backend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	if self.fails > 0 {
+		self.fails--
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+func TestRawSender(t *testing.T) {
+	ctx := context.Background()
+	accounts, err := simulation.NewAccounts(2)
+	testutil.Ok(t, err)
+	from, to := accounts[0].Address, accounts[1].Address
+	backend, err := simulation.NewBackend(logging.NewLogger(), simulation.Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Accounts:   []common.Address{from},
+		Balance:    big.NewInt(1e18),
+	})
+	testutil.Ok(t, err)
+	client := &failingSendBackend{Backend: backend, fails: 1}
+	chainID, err := backend.NetworkID(ctx)
+	testutil.Ok(t, err)
+	value := big.NewInt(1000)
+	sender := &rawSender{
+		ctx:      ctx,
+		logger:   logging.NewLogger(),
+		client:   client,
+		contract: bind.NewBoundContract(to, abi.ABI{}, client, client, client),
+		value:    value,
+		gas:      params.TxGas,
+	}
+	// auth returns the options of a transactor retry at the given nonce.
+	auth := func(nonce int64) *bind.TransactOpts {
+		opts := accounts[0].NewTransactor(chainID)
+		opts.Nonce = big.NewInt(nonce)
+		opts.GasPrice = simulation.GasPrice
+		return opts
+	}
+
+	_, err = sender.send(auth(0))
+	testutil.NotOk(t, err)
+	testutil.Equals(t, 1, backend.Pending(), "the failed send should still be delivered")
+
+	// The transactor takes the next nonce after a nonce too low error.
+	_, err = sender.send(auth(1))
+	testutil.NotOk(t, err, "the value shouldn't be sent with another nonce while the sent transaction is not mined")
+
+	backend.Commit()
+	tx, err := sender.send(auth(1))
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(0), tx.Nonce(), "the mined transaction should be returned")
+	testutil.Equals(t, 0, backend.Pending(), "nothing else should be sent")
+	balance, err := backend.BalanceAt(ctx, to, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, value, balance)
+}
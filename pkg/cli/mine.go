@@ -20,6 +20,7 @@ import (
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
 	"github.com/tellor-io/telliot/pkg/gasPrice/gasStation"
 	"github.com/tellor-io/telliot/pkg/logging"
 	"github.com/tellor-io/telliot/pkg/mining"
@@ -30,14 +31,17 @@ import (
 	"github.com/tellor-io/telliot/pkg/tasker"
 	"github.com/tellor-io/telliot/pkg/tracker/dispute"
 	"github.com/tellor-io/telliot/pkg/tracker/index"
+	"github.com/tellor-io/telliot/pkg/tracker/lifecycle"
 	"github.com/tellor-io/telliot/pkg/tracker/profit"
 	"github.com/tellor-io/telliot/pkg/tracker/reward"
 	"github.com/tellor-io/telliot/pkg/transactor"
+	"github.com/tellor-io/telliot/pkg/voter"
 	"github.com/tellor-io/telliot/pkg/web"
 )
 
 type mineCmd struct {
 	Config configPath `type:"existingfile" help:"path to config file"`
+	Labels []string   `optional:"" help:"labels of the accounts to mine with, overrides Accounts.Mine in the config"`
 }
 
 func (self mineCmd) Run() error {
@@ -60,279 +64,412 @@ func (self mineCmd) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "getting accounts")
 	}
+	labels := cfg.Accounts.Mine
+	if len(self.Labels) > 0 {
+		labels = self.Labels
+	}
+	accounts, err = cfg.Accounts.Select(accounts, labels)
+	if err != nil {
+		return errors.Wrap(err, "selecting the accounts")
+	}
 
 	// We define our run groups here.
 	var g run.Group
-	// Run groups.
+	// Handle interupts.
+	g.Add(run.SignalHandler(context.Background(), syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM))
+
+	closeDB, err := mine(ctx, logger, cfg, client, accounts, &g)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if err := g.Run(); err != nil {
+		level.Error(logger).Log("msg", "main exited with error", "err", err)
+		return err
+	}
+
+	level.Info(logger).Log("msg", "main shutdown complete")
+	return nil
+}
+
+// mine adds all components of the mine command to the run group.
+// The client is an argument so that the whole command can run against a simulated chain in tests.
+// The returned function closes the database and should be called after the run group exits.
+func mine(
+	ctx context.Context,
+	logger log.Logger,
+	cfg *config.Config,
+	client ethereum.EthClient,
+	accounts []*ethereum.Account,
+	g *run.Group,
+) (closeDB func(), err error) {
+	closeDB = func() {}
+	defer func() {
+		if err != nil {
+			closeDB()
+		}
+	}()
+
+	// A single event hub is shared by all components that need the tellor contract events.
+	// It is created only when used as the tellor contract doesn't exist on all networks.
+	var hub *events.Hub
+	eventHub := func(contract *contracts.ITellor) (*events.Hub, error) {
+		if hub != nil {
+			return hub, nil
+		}
+		h, err := events.New(logger, ctx, cfg.EventHub, client, contract.Address)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating event hub")
+		}
+		hub = h
+		g.Add(func() error {
+			err := hub.Start()
+			level.Info(logger).Log("msg", "event hub shutdown complete")
+			return err
+		}, func(error) {
+			hub.Stop()
+		})
+		return hub, nil
+	}
+
+	// Open a local or remote instance of the TSDB database.
+	var tsDB storage.SampleAndChunkQueryable
+	if cfg.Db.RemoteHost != "" {
+		tsDB, err = db.NewRemoteDB(cfg.Db)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening remote tsdb DB")
+		}
+		level.Info(logger).Log("msg", "connected to remote db", "host", cfg.Db.RemoteHost, "port", cfg.Db.RemotePort)
+	} else {
+		// Open the TSDB database.
+		tsdbOptions := tsdb.DefaultOptions()
+		// 5 days are enough as the aggregator needs data only 24 hours in the past.
+		tsdbOptions.RetentionDuration = int64(5 * 24 * time.Hour)
+		_tsDB, err := tsdb.Open(cfg.Db.Path, nil, nil, tsdbOptions)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening local tsdb DB")
+		}
+		closeDB = func() {
+			if err := _tsDB.Close(); err != nil {
+				level.Error(logger).Log("msg", "closing the tsdb", "err", err)
+			}
+		}
+		tsDB = _tsDB
+		level.Info(logger).Log("msg", "opened local db", "path", cfg.Db.Path)
+		level.Warn(logger).Log("msg", "FOR NEW DB INSTANCES IT IS NORMAL TO SEE SOME QUERY ERRORS AS THE DATABASE IS NOT YET POPULATED WITH VALUES")
+	}
+
+	// Web/Api server.
 	{
-		// Handle interupts.
-		g.Add(run.SignalHandler(context.Background(), syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM))
+		srv, err := web.New(logger, ctx, tsDB, cfg.Web)
+		if err != nil {
+			return nil, errors.Wrap(err, "create web server")
+		}
+		g.Add(func() error {
+			err := srv.Start()
+			level.Info(logger).Log("msg", "web server shutdown complete")
+			return err
+		}, func(error) {
+			srv.Stop()
+		})
+	}
+
+	// Aggregator.
+	aggregator, err := aggregator.New(logger, ctx, cfg.Aggregator, tsDB)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating aggregator")
+	}
 
-		// Open a local or remote instance of the TSDB database.
-		var tsDB storage.SampleAndChunkQueryable
-		if cfg.Db.RemoteHost != "" {
-			tsDB, err = db.NewRemoteDB(cfg.Db)
+	gasPriceQuerier, err := gasStation.New(logger, cfg.GasStation, client)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating gas price tracker")
+	}
+
+	// Index tracker.
+	// Run only when not using remote DB as it needs to write to the local db.
+	if cfg.Db.RemoteHost == "" {
+		_tsDB, ok := tsDB.(*tsdb.DB)
+		if !ok {
+			return nil, errors.New("tsdb is not a writable DB instance")
+		}
+
+		// Index Tracker.
+		index, err := index.New(logger, ctx, cfg.IndexTracker, _tsDB, client)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating index tracker")
+		}
+
+		g.Add(func() error {
+			err := index.Run()
+			level.Info(logger).Log("msg", "index shutdown complete")
+			return err
+		}, func(error) {
+			index.Stop()
+		})
+
+		_netID, err := client.NetworkID(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting network ID")
+		}
+		netID := _netID.Int64()
+
+		// Run some component only when not connected to a remote DB.
+		// A remote DB already runs a dispute tracker so no need to run another one.
+		// Also run and only for mainnet or rinkeby as the tellor oracle exists only on those networks.
+		if netID == 1 || netID == 4 {
+			contractTellor, err := contracts.NewITellor(client)
 			if err != nil {
-				return errors.Wrap(err, "opening remote tsdb DB")
+				return nil, errors.Wrap(err, "create tellor contract instance")
 			}
-			level.Info(logger).Log("msg", "connected to remote db", "host", cfg.Db.RemoteHost, "port", cfg.Db.RemotePort)
-		} else {
-			// Open the TSDB database.
-			tsdbOptions := tsdb.DefaultOptions()
-			// 5 days are enough as the aggregator needs data only 24 hours in the past.
-			tsdbOptions.RetentionDuration = int64(5 * 24 * time.Hour)
-			_tsDB, err := tsdb.Open(cfg.Db.Path, nil, nil, tsdbOptions)
+			hub, err := eventHub(contractTellor)
 			if err != nil {
-				return errors.Wrap(err, "opening local tsdb DB")
+				return nil, err
 			}
-			defer func() {
-				if err := _tsDB.Close(); err != nil {
-					level.Error(logger).Log("msg", "closing the tsdb", "err", err)
-				}
-			}()
-			tsDB = _tsDB
-			level.Info(logger).Log("msg", "opened local db", "path", cfg.Db.Path)
-			level.Warn(logger).Log("msg", "FOR NEW DB INSTANCES IT IS NORMAL TO SEE SOME QUERY ERRORS AS THE DATABASE IS NOT YET POPULATED WITH VALUES")
-		}
 
-		// Web/Api server.
-		{
-			srv, err := web.New(logger, ctx, tsDB, cfg.Web)
+			// Reward tracker.
+			rewardTracker, err := reward.NewRewardTracker(logger, ctx, cfg.RewardTracker, _tsDB, client, contractTellor, hub, accounts[0].Address, aggregator, gasPriceQuerier)
 			if err != nil {
-				return errors.Wrap(err, "create web server")
+				return nil, errors.Wrap(err, "creating reward tracker")
 			}
 			g.Add(func() error {
-				err := srv.Start()
-				level.Info(logger).Log("msg", "web server shutdown complete")
+				err := rewardTracker.Start()
+				level.Info(logger).Log("msg", "reward tracker shutdown complete")
 				return err
 			}, func(error) {
-				srv.Stop()
+				rewardTracker.Stop()
+			})
+
+			disputeTracker, err := dispute.New(
+				logger,
+				ctx,
+				cfg.DisputeTracker,
+				_tsDB,
+				client,
+				contractTellor,
+				hub,
+				psrTellor.New(logger, cfg.PsrTellor, aggregator),
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating profit tracker")
+			}
+			g.Add(func() error {
+				disputeTracker.Start()
+				level.Info(logger).Log("msg", "dispute tracker shutdown complete")
+				return nil
+			}, func(error) {
+				disputeTracker.Stop()
 			})
 		}
 
-		// Aggregator.
-		aggregator, err := aggregator.New(logger, ctx, cfg.Aggregator, tsDB)
-		if err != nil {
-			return errors.Wrap(err, "creating aggregator")
+	}
+
+	if cfg.SubmitterTellor.Enabled {
+		// Profit tracker.
+		var accountAddrs []common.Address
+		for _, acc := range accounts {
+			accountAddrs = append(accountAddrs, acc.Address)
 		}
 
-		// Index tracker.
-		// Run only when not using remote DB as it needs to write to the local db.
-		if cfg.Db.RemoteHost == "" {
-			_tsDB, ok := tsDB.(*tsdb.DB)
-			if !ok {
-				return errors.New("tsdb is not a writable DB instance")
-			}
+		contractTellor, err := contracts.NewITellor(client)
+		if err != nil {
+			return nil, errors.Wrap(err, "create tellor contract instance")
+		}
+		hub, err := eventHub(contractTellor)
+		if err != nil {
+			return nil, err
+		}
 
-			// Index Tracker.
-			index, err := index.New(logger, ctx, cfg.IndexTracker, _tsDB, client)
+		profitTracker, err := profit.NewProfitTracker(logger, ctx, cfg.ProfitTracker, client, contractTellor, hub, accountAddrs)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating profit tracker")
+		}
+		g.Add(func() error {
+			err := profitTracker.Start()
+			level.Info(logger).Log("msg", "profit tracker shutdown complete")
+			return err
+		}, func(error) {
+			profitTracker.Stop()
+		})
+
+		// Event tasker.
+		tasker, taskerChs, err := tasker.New(ctx, logger, cfg.Tasker, client, contractTellor, hub, accounts)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating tasker")
+		}
+		g.Add(func() error {
+			err := tasker.Start()
+			level.Info(logger).Log("msg", "tasker shutdown complete")
+			return err
+		}, func(error) {
+			tasker.Stop()
+		})
+
+		// A single pool server serves the work for all accounts to the remote workers.
+		var pool *mining.PoolServer
+		if cfg.Mining.Pool.Enabled {
+			pool, err = mining.NewPoolServer(logger, ctx, cfg.Mining)
 			if err != nil {
-				return errors.Wrapf(err, "creating index tracker")
+				return nil, errors.Wrap(err, "creating mining pool")
 			}
-
 			g.Add(func() error {
-				err := index.Run()
-				level.Info(logger).Log("msg", "index shutdown complete")
+				err := pool.Start()
+				level.Info(logger).Log("msg", "mining pool shutdown complete")
 				return err
 			}, func(error) {
-				index.Stop()
+				pool.Stop()
 			})
+		}
+
+		// Create a submitter for each account.
+		for _, account := range accounts {
+			loggerWithAddr := log.With(logger, "addr", account.Address.String()[:6])
 
-			_netID, err := client.NetworkID(ctx)
+			transactor, err := transactor.New(loggerWithAddr, cfg.Transactor, gasPriceQuerier, client, account)
 			if err != nil {
-				return errors.Wrap(err, "getting network ID")
-			}
-			netID := _netID.Int64()
-
-			// Run some component only when not connected to a remote DB.
-			// A remote DB already runs a dispute tracker so no need to run another one.
-			// Also run and only for mainnet or rinkeby as the tellor oracle exists only on those networks.
-			if netID == 1 || netID == 4 {
-				contractTellor, err := contracts.NewITellor(client)
-				if err != nil {
-					return errors.Wrap(err, "create tellor contract instance")
-				}
-
-				// Reward tracker.
-				rewardTracker, err := reward.NewRewardTracker(logger, ctx, cfg.RewardTracker, _tsDB, client, contractTellor, accounts[0].Address, aggregator)
-				if err != nil {
-					return errors.Wrap(err, "creating reward tracker")
-				}
-				g.Add(func() error {
-					err := rewardTracker.Start()
-					level.Info(logger).Log("msg", "reward tracker shutdown complete")
-					return err
-				}, func(error) {
-					rewardTracker.Stop()
-				})
-
-				disputeTracker, err := dispute.New(
-					logger,
-					ctx,
-					cfg.DisputeTracker,
-					_tsDB,
-					client,
-					contractTellor,
-					psrTellor.New(logger, cfg.PsrTellor, aggregator),
-				)
-				if err != nil {
-					return errors.Wrap(err, "creating profit tracker")
-				}
-				g.Add(func() error {
-					disputeTracker.Start()
-					level.Info(logger).Log("msg", "dispute tracker shutdown complete")
-					return nil
-				}, func(error) {
-					disputeTracker.Stop()
-				})
+				return nil, errors.Wrap(err, "creating transactor")
 			}
 
-		}
-
-		gasPriceQuerier, err := gasStation.New(logger, cfg.GasStation, client)
-		if err != nil {
-			return errors.Wrap(err, "creating gas price tracker")
-		}
+			psr := psrTellor.New(loggerWithAddr, cfg.PsrTellor, aggregator)
 
-		if cfg.SubmitterTellor.Enabled {
-			// Profit tracker.
-			var accountAddrs []common.Address
-			for _, acc := range accounts {
-				accountAddrs = append(accountAddrs, acc.Address)
+			rewardQuerier, err := reward.NewRewardQuerier(logger, ctx, cfg.RewardTracker, tsDB, client, contractTellor, accounts[0].Address, aggregator)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating reward tracker")
 			}
-
-			contractTellor, err := contracts.NewITellor(client)
+			// Get a channel on which it listens for new data to submit.
+			submitter, submitterCh, err := tellor.New(
+				ctx,
+				loggerWithAddr,
+				cfg.SubmitterTellor,
+				client,
+				contractTellor,
+				account,
+				rewardQuerier,
+				transactor,
+				gasPriceQuerier,
+				psr,
+			)
 			if err != nil {
-				return errors.Wrap(err, "create tellor contract instance")
+				return nil, errors.Wrap(err, "creating tellor submitter")
 			}
-
-			profitTracker, err := profit.NewProfitTracker(logger, ctx, cfg.ProfitTracker, client, contractTellor, accountAddrs)
+			// A solution that was pending before a restart is sent again unless its transaction is already mined.
+			recoverCtx, recoverCncl := context.WithTimeout(ctx, cfg.Transactor.DrainTimeout.Duration)
+			recovered, err := submitter.Recover(recoverCtx)
+			recoverCncl()
 			if err != nil {
-				return errors.Wrap(err, "creating profit tracker")
+				level.Error(loggerWithAddr).Log("msg", "recovering the pending submit", "err", err)
 			}
 			g.Add(func() error {
-				err := profitTracker.Start()
-				level.Info(logger).Log("msg", "profit tracker shutdown complete")
+				err := submitter.Start()
+				level.Info(loggerWithAddr).Log("msg", "tellor submitter shutdown complete")
 				return err
 			}, func(error) {
-				profitTracker.Stop()
+				submitter.Stop()
 			})
 
-			// Event tasker.
-			tasker, taskerChs, err := tasker.New(ctx, logger, cfg.Tasker, client, contractTellor, accounts)
+			// Will be used to cancel pending submissions and
+			// to mine only for the accounts that can submit.
+			tasker.AddSubmitCanceler(submitter)
+			tasker.AddSubmitEligibility(account.Address, submitter)
+
+			// The Miner component.
+			miner, err := mining.NewMiningManager(loggerWithAddr, ctx, cfg.Mining, contractTellor, taskerChs[account.Address.String()], submitterCh, client, pool)
 			if err != nil {
-				return errors.Wrap(err, "creating tasker")
+				return nil, errors.Wrap(err, "creating miner")
+			}
+			if recovered != nil {
+				miner.AddSolution(recovered, submitter)
 			}
 			g.Add(func() error {
-				err := tasker.Start()
-				level.Info(logger).Log("msg", "tasker shutdown complete")
+				err := miner.Start()
+				level.Info(loggerWithAddr).Log("msg", "miner shutdown complete")
 				return err
 			}, func(error) {
-				tasker.Stop()
+				miner.Stop()
 			})
+		}
+	}
 
-			// Create a submitter for each account.
-			for _, account := range accounts {
-				loggerWithAddr := log.With(logger, "addr", account.Address.String()[:6])
-
-				transactor, err := transactor.New(loggerWithAddr, cfg.Transactor, gasPriceQuerier, client, account)
-				if err != nil {
-					return errors.Wrap(err, "creating transactor")
-				}
-
-				psr := psrTellor.New(loggerWithAddr, cfg.PsrTellor, aggregator)
-
-				rewardQuerier, err := reward.NewRewardQuerier(logger, ctx, cfg.RewardTracker, tsDB, client, contractTellor, accounts[0].Address, aggregator)
-				if err != nil {
-					return errors.Wrap(err, "creating reward tracker")
-				}
-				// Get a channel on which it listens for new data to submit.
-				submitter, submitterCh, err := tellor.New(
-					ctx,
-					loggerWithAddr,
-					cfg.SubmitterTellor,
-					client,
-					contractTellor,
-					account,
-					rewardQuerier,
-					transactor,
-					gasPriceQuerier,
-					psr,
-				)
-				if err != nil {
-					return errors.Wrap(err, "creating tellor submitter")
-				}
-				g.Add(func() error {
-					err := submitter.Start()
-					level.Info(loggerWithAddr).Log("msg", "tellor submitter shutdown complete")
-					return err
-				}, func(error) {
-					submitter.Stop()
-				})
-
-				// Will be used to cancel pending submissions.
-				tasker.AddSubmitCanceler(submitter)
-
-				// The Miner component.
-				miner, err := mining.NewMiningManager(loggerWithAddr, ctx, cfg.Mining, contractTellor, taskerChs[account.Address.String()], submitterCh, client)
-				if err != nil {
-					return errors.Wrap(err, "creating miner")
-				}
-				g.Add(func() error {
-					err := miner.Start()
-					level.Info(loggerWithAddr).Log("msg", "miner shutdown complete")
-					return err
-				}, func(error) {
-					miner.Stop()
-				})
-			}
+	if cfg.Voter.Enabled {
+		contractTellor, err := contracts.NewITellor(client)
+		if err != nil {
+			return nil, errors.Wrap(err, "create tellor contract instance")
+		}
+		hub, err := eventHub(contractTellor)
+		if err != nil {
+			return nil, err
+		}
+		voter, err := voter.New(logger, ctx, cfg.Voter, cfg.DisputeTracker, tsDB, client, contractTellor, hub, accounts)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating voter")
+		}
+		g.Add(func() error {
+			err := voter.Start()
+			level.Info(logger).Log("msg", "voter shutdown complete")
+			return err
+		}, func(error) {
+			voter.Stop()
+		})
+	}
+
+	if cfg.DisputeLifecycle.Enabled {
+		contractTellor, err := contracts.NewITellor(client)
+		if err != nil {
+			return nil, errors.Wrap(err, "create tellor contract instance")
+		}
+		hub, err := eventHub(contractTellor)
+		if err != nil {
+			return nil, err
+		}
+		lifecycleTracker, err := lifecycle.NewLifecycleTracker(logger, ctx, cfg.DisputeLifecycle, client, contractTellor, hub, accounts)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating dispute lifecycle tracker")
+		}
+		g.Add(func() error {
+			err := lifecycleTracker.Start()
+			level.Info(logger).Log("msg", "dispute lifecycle tracker shutdown complete")
+			return err
+		}, func(error) {
+			lifecycleTracker.Stop()
+		})
+	}
+
+	if cfg.SubmitterTellorMesosphere.Enabled {
+		contract, err := contracts.NewITellorMesosphere(client)
+		if err != nil {
+			return nil, errors.Wrap(err, "create contract instance")
 		}
 
-		if cfg.SubmitterTellorMesosphere.Enabled {
-			contract, err := contracts.NewITellorMesosphere(client)
+		// Create a submitter for each account.
+		for _, account := range accounts {
+			loggerWithAddr := log.With(logger, "addr", account.Address.String()[:6])
+			psr := psrTellorMesosphere.New(loggerWithAddr, cfg.PsrTellorMesosphere, aggregator)
+			transactor, err := transactor.New(loggerWithAddr, cfg.Transactor, gasPriceQuerier, client, account)
 			if err != nil {
-				return errors.Wrap(err, "create contract instance")
+				return nil, errors.Wrap(err, "creating transactor")
 			}
 
-			// Create a submitter for each account.
-			for _, account := range accounts {
-				loggerWithAddr := log.With(logger, "addr", account.Address.String()[:6])
-				psr := psrTellorMesosphere.New(loggerWithAddr, cfg.PsrTellorMesosphere, aggregator)
-				transactor, err := transactor.New(loggerWithAddr, cfg.Transactor, gasPriceQuerier, client, account)
-				if err != nil {
-					return errors.Wrap(err, "creating transactor")
-				}
-
-				submitter, err := tellorMesosphere.New(
-					ctx,
-					loggerWithAddr,
-					cfg.SubmitterTellorMesosphere,
-					client,
-					contract,
-					account,
-					transactor,
-					psr,
-				)
-				if err != nil {
-					return errors.Wrap(err, "creating tellor mesosphere submitter")
-				}
-				g.Add(func() error {
-					err := submitter.Start()
-					level.Info(loggerWithAddr).Log("msg", "tellor mesosphere submitter shutdown complete")
-					return err
-				}, func(error) {
-					submitter.Stop()
-				})
+			submitter, err := tellorMesosphere.New(
+				ctx,
+				loggerWithAddr,
+				cfg.SubmitterTellorMesosphere,
+				client,
+				contract,
+				account,
+				transactor,
+				psr,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating tellor mesosphere submitter")
 			}
+			g.Add(func() error {
+				err := submitter.Start()
+				level.Info(loggerWithAddr).Log("msg", "tellor mesosphere submitter shutdown complete")
+				return err
+			}, func(error) {
+				submitter.Stop()
+			})
 		}
-
-	}
-
-	if err := g.Run(); err != nil {
-		level.Error(logger).Log("msg", "main exited with error", "err", err)
-		return err
 	}
-
-	level.Info(logger).Log("msg", "main shutdown complete")
-	return nil
+	return closeDB, nil
 }
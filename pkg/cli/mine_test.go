@@ -0,0 +1,167 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/oklog/run"
+	"github.com/phayes/freeport"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func testMineConfig(t *testing.T) *config.Config {
+	cfg := config.DefaultConfig
+	port, err := freeport.GetFreePort()
+	testutil.Ok(t, err)
+	cfg.Web.ListenPort = uint(port)
+
+	dir := t.TempDir()
+	cfg.Db.Path = filepath.Join(dir, "db")
+	cfg.EnvFile = filepath.Join(dir, ".env")
+	cfg.IndexTracker.IndexFile = filepath.Join(dir, "index.json")
+	testutil.Ok(t, ioutil.WriteFile(cfg.IndexTracker.IndexFile, []byte("{}"), 0600))
+
+	// The values are taken from the manual data file so the test doesn't depend on the data APIs.
+	cfg.Aggregator.ManualDataFile = filepath.Join(dir, "manualData.json")
+	manual := `{"tellor":{`
+	for id := 1; id <= 5; id++ {
+		if id > 1 {
+			manual += ","
+		}
+		manual += `"` + strconv.Itoa(id) + `":{"VALUE":` + strconv.Itoa(id*10) + `,"DATE":4102444800}`
+	}
+	manual += `}}`
+	testutil.Ok(t, ioutil.WriteFile(cfg.Aggregator.ManualDataFile, []byte(manual), 0600))
+
+	cfg.SubmitterTellor.StateDir = filepath.Join(dir, "submitter")
+	cfg.SubmitterTellor.Enabled = true
+	cfg.SubmitterTellor.MinSubmitPeriod.Duration = 0
+	cfg.SubmitterTellor.ProfitThreshold = 0
+	cfg.Mining.NumProcessors = 1
+	return &cfg
+}
+
+// TestMine runs all components of the mine command against a simulated chain
+// and checks that the miners fill all slots before and after a reorg.
+func TestMine(t *testing.T) {
+	logger := logging.NewLogger()
+	accounts, err := simulation.NewAccounts(5)
+	testutil.Ok(t, err)
+	simCfg := simulation.Config{
+		Difficulty: big.NewInt(1000),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Balance:    big.NewInt(1e18),
+		// Short enough for the same miners to submit again after the reorg.
+		MinerTimeLimit: time.Second,
+	}
+	for _, acc := range accounts {
+		simCfg.Accounts = append(simCfg.Accounts, acc.Address)
+		simCfg.Stakers = append(simCfg.Stakers, acc.Address)
+	}
+	backend, err := simulation.NewBackend(logger, simCfg)
+	testutil.Ok(t, err)
+	// The reorg below needs blocks before the first submit to drop the parent of the submits.
+	for i := 0; i < 3; i++ {
+		backend.Commit()
+	}
+
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+
+	var g run.Group
+	g.Add(func() error {
+		<-ctx.Done()
+		return nil
+	}, func(error) {
+		cncl()
+	})
+	closeDB, err := mine(ctx, logger, testMineConfig(t), backend, accounts, &g)
+	testutil.Ok(t, err)
+	defer closeDB()
+
+	// Mine a block every second to keep the block times close to the clock used by the submitters.
+	// The chain continues after the shutdown so that the submitters can drain the sent transactions.
+	stopCommits := make(chan struct{})
+	defer close(stopCommits)
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCommits:
+				return
+			case <-ticker.C:
+				backend.Commit()
+			}
+		}
+	}()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- g.Run()
+	}()
+
+	waitForValues := func(n int) []simulation.NewValue {
+		timeout := time.After(2 * time.Minute)
+		for {
+			if values := backend.NewValues(); len(values) >= n {
+				return values
+			}
+			select {
+			case err := <-errs:
+				t.Fatalf("the run group exited early err:%v", err)
+			case <-timeout:
+				t.Fatalf("timeout waiting for %v new values", n)
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+
+	values := waitForValues(1)
+	miners := make(map[string]bool)
+	for i, miner := range values[0].Miners {
+		miners[miner.Hex()] = true
+		for j, val := range values[0].Values[i] {
+			testutil.Equals(t, int64((j+1)*10*1e6), val.Int64())
+		}
+	}
+	testutil.Equals(t, len(accounts), len(miners), "all accounts should fill a slot")
+
+	// Wait for the miners to submit for the next challenge and
+	// then drop the blocks with the submits for both challenges.
+	// The submits for the first challenge are mined again on a different parent so the next challenge changes
+	// and the tasker needs to continue with it.
+	next := backend.CurrentChallenge()
+	for i := 0; i < 20; i++ {
+		head, err := backend.HeaderByNumber(ctx, nil)
+		testutil.Ok(t, err)
+		if head.Number.Uint64() > values[0].Block+1 && backend.Pending() == 0 {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	testutil.Ok(t, backend.Reorg(values[0].Block-2))
+
+	values = waitForValues(2)
+	testutil.Assert(t, values[1].Challenge != next, "the reorg should change the challenge")
+
+	// All components share the context so any of them can be the first to exit
+	// and the error of the run group is not checked.
+	cncl()
+	select {
+	case <-errs:
+	case <-time.After(time.Minute):
+		t.Fatal("timeout waiting for the run group to exit")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/mining"
+)
+
+type miningBenchCmd struct {
+	cfg
+	Duration time.Duration `default:"30s" help:"how long to run the benchmark"`
+}
+
+func (self miningBenchCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	level.Info(logger).Log("msg", "running the mining benchmark", "duration", self.Duration, "processors", cfg.Mining.Processors())
+	rates, err := mining.Bench(logger, ctx, cfg.Mining, self.Duration)
+	if err != nil {
+		return errors.Wrap(err, "running the benchmark")
+	}
+	result := benchResult{SolutionWindow: mining.SolutionWindow.String()}
+	for _, rate := range rates {
+		result.Backends = append(result.Backends, backendRateResult{Name: rate.Name, HashRate: rate.HashRate})
+		result.HashRate += rate.HashRate
+	}
+	level.Info(logger).Log("msg", "total hash rate", "hashRate", mining.FormatHashRate(result.HashRate))
+
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		level.Warn(logger).Log("msg", "skipping the estimate for the current difficulty", "err", errors.Wrap(err, "creating ethereum client"))
+		return output(result)
+	}
+	contract, err := contracts.NewITellor(client)
+	if err != nil {
+		return errors.Wrap(err, "create tellor contract instance")
+	}
+	vars, err := contract.GetNewCurrentVariables(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return errors.Wrap(err, "getting the current difficulty")
+	}
+
+	result.Difficulty = vars.Difficutly.String()
+	result.ExpectedTimeToSolution = mining.ExpectedSolveTime(result.HashRate, vars.Difficutly).Round(time.Second).String()
+	result.ProbabilityInWindow = mining.SolveProbability(result.HashRate, vars.Difficutly, mining.SolutionWindow) * 100
+	return output(result)
+}
+
+type benchResult struct {
+	// HashRate is the total of all backends in hashes per second.
+	HashRate               float64             `json:"hashRate"`
+	Backends               []backendRateResult `json:"backends"`
+	Difficulty             string              `json:"difficulty"`
+	ExpectedTimeToSolution string              `json:"expectedTimeToSolution"`
+	SolutionWindow         string              `json:"solutionWindow"`
+	ProbabilityInWindow    float64             `json:"probabilityInWindowPercent"`
+}
+
+type backendRateResult struct {
+	Name     string  `json:"name"`
+	HashRate float64 `json:"hashRate"`
+}
@@ -0,0 +1,184 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+// Formats of the command results.
+const (
+	outputLogfmt = "logfmt"
+	outputJSON   = "json"
+	outputTable  = "table"
+)
+
+// output writes the result of a command to stdout in the format selected with the global output flag.
+// Logs go to stderr so stdout has only the results.
+func output(result interface{}) error {
+	return render(os.Stdout, CLI.Output, result)
+}
+
+// render writes the result in the given format.
+// The result is a struct or a slice of structs with the field names taken from their json tags.
+// The logfmt format has a line per struct and the table format a row per struct.
+func render(w io.Writer, format string, result interface{}) error {
+	if format == outputJSON {
+		v := reflect.ValueOf(result)
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			result = []struct{}{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return errors.Wrap(enc.Encode(result), "encoding the result")
+	}
+
+	columns, rows := flatten(result)
+	switch format {
+	case outputLogfmt:
+		logger := log.NewLogfmtLogger(w)
+		for _, row := range rows {
+			keyvals := make([]interface{}, 0, 2*len(columns))
+			for i, column := range columns {
+				keyvals = append(keyvals, column, row[i])
+			}
+			if err := logger.Log(keyvals...); err != nil {
+				return errors.Wrap(err, "writing the result")
+			}
+		}
+		return nil
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = columnTitle(column)
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return errors.Wrap(tw.Flush(), "writing the result")
+	default:
+		return errors.Errorf("unknown output format:%v", format)
+	}
+}
+
+// flatten returns the column names and the formatted field values of every struct in the result.
+func flatten(result interface{}) ([]string, [][]string) {
+	v := reflect.Indirect(reflect.ValueOf(result))
+	var items []reflect.Value
+	var typ reflect.Type
+	if v.Kind() == reflect.Slice {
+		typ = v.Type().Elem()
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, reflect.Indirect(v.Index(i)))
+		}
+	} else {
+		typ = v.Type()
+		items = append(items, v)
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var columns []string
+	var fields []int
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, name)
+		fields = append(fields, i)
+	}
+
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = formatValue(item.Field(field))
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// formatValue formats a field value for the logfmt and table formats.
+// Lists are comma separated and nested structs are encoded as JSON.
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		if _, ok := v.Interface().(fmt.Stringer); ok {
+			return fmt.Sprint(v.Interface())
+		}
+		return formatValue(v.Elem())
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	if _, ok := v.Interface().(fmt.Stringer); ok {
+		return fmt.Sprint(v.Interface())
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			return formatJSON(v)
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(items, ",")
+	case reflect.Struct, reflect.Map:
+		return formatJSON(v)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func formatJSON(v reflect.Value) string {
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(data)
+}
+
+// columnTitle turns a json field name like requestId into a table title like REQUEST ID.
+func columnTitle(name string) string {
+	var title []rune
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(name[i-1])) {
+			title = append(title, ' ')
+		}
+		title = append(title, unicode.ToUpper(r))
+	}
+	return string(title)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestRender(t *testing.T) {
+	since := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	result := []stakeStatusResult{
+		{Address: common.HexToAddress("0x1"), Status: "Staked", Since: since, TRB: 500},
+		{Address: common.HexToAddress("0x2"), Status: "Not staked", TRB: 1.5},
+	}
+
+	var buf bytes.Buffer
+	testutil.Ok(t, render(&buf, outputLogfmt, result))
+	testutil.Equals(t, ""+
+		"address=0x0000000000000000000000000000000000000001 status=Staked since=2021-06-01T10:00:00Z withdrawAt= withdrawIn= trb=500\n"+
+		"address=0x0000000000000000000000000000000000000002 status=\"Not staked\" since= withdrawAt= withdrawIn= trb=1.5\n",
+		buf.String())
+
+	buf.Reset()
+	testutil.Ok(t, render(&buf, outputTable, result))
+	testutil.Equals(t, ""+
+		"ADDRESS                                     STATUS      SINCE                 WITHDRAW AT  WITHDRAW IN  TRB\n"+
+		"0x0000000000000000000000000000000000000001  Staked      2021-06-01T10:00:00Z                            500\n"+
+		"0x0000000000000000000000000000000000000002  Not staked                                                  1.5\n",
+		buf.String())
+
+	buf.Reset()
+	testutil.Ok(t, render(&buf, outputJSON, result))
+	var decoded []stakeStatusResult
+	testutil.Ok(t, json.Unmarshal(buf.Bytes(), &decoded))
+	testutil.Equals(t, result[0].Address, decoded[0].Address)
+	testutil.Equals(t, result[1].TRB, decoded[1].TRB)
+
+	// Nil lists are empty JSON arrays so that these can be iterated by scripts.
+	buf.Reset()
+	testutil.Ok(t, render(&buf, outputJSON, []stakeStatusResult(nil)))
+	testutil.Equals(t, "[]\n", buf.String())
+
+	// Lists are comma separated and nested structs are encoded as JSON.
+	buf.Reset()
+	testutil.Ok(t, render(&buf, outputLogfmt, benchResult{
+		HashRate: 3,
+		Backends: []backendRateResult{{Name: "cpu", HashRate: 3}},
+	}))
+	testutil.Equals(t, `hashRate=3 backends="[{\"name\":\"cpu\",\"hashRate\":3}]" difficulty= expectedTimeToSolution= solutionWindow= probabilityInWindowPercent=0`+"\n", buf.String())
+
+	testutil.NotOk(t, render(&buf, "xml", result))
+}
@@ -5,12 +5,13 @@ package cli
 
 import (
 	"context"
-	"math/big"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
@@ -19,6 +20,8 @@ import (
 	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
 	"github.com/tellor-io/telliot/pkg/math"
+	"github.com/tellor-io/telliot/pkg/stake"
+	"github.com/tellor-io/telliot/pkg/submitter/tellor"
 )
 
 type depositCmd struct {
@@ -38,7 +41,7 @@ func (self depositCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	addr, err := self.address()
 	if err != nil {
 		return err
 	}
@@ -47,19 +50,18 @@ func (self depositCmd) Run() error {
 		return errors.Wrap(err, "create tellor contract instance")
 	}
 
-	balance, err := contract.BalanceOf(&bind.CallOpts{Context: ctx}, account.Address)
+	balance, err := contract.BalanceOf(&bind.CallOpts{Context: ctx}, addr)
 	if err != nil {
 		return errors.Wrap(err, "get TRB balance")
 	}
 
-	status, startTime, err := contract.GetStakerInfo(&bind.CallOpts{Context: ctx}, account.Address)
+	info, err := stake.GetInfo(ctx, contract, addr)
 	if err != nil {
-		return errors.Wrap(err, "get stake status")
+		return err
 	}
-
-	if status.Uint64() != 0 && status.Uint64() != 2 {
-		printStakeStatus(logger, status, startTime)
-		return nil
+	if !info.CanDeposit() {
+		logStakeInfo(logger, info)
+		return skipped(logger, "deposit", addr, "the stake status is "+stake.StatusName(info.Status))
 	}
 
 	stakeAmt, err := contract.GetUintVar(nil, ethereum.Keccak256([]byte("_STAKE_AMOUNT")))
@@ -73,22 +75,16 @@ func (self depositCmd) Run() error {
 			math.BigInt18eToFloat(stakeAmt))
 	}
 
-	var gasPrice *big.Int
-	if self.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
-	}
-
-	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := self.newTransaction(ctx, client, addr)
 	if err != nil {
-		return errors.Wrap(err, "prepare ethereum transaction")
+		return err
 	}
 
-	tx, err := contract.DepositStake(auth)
+	tx, err := contract.DepositStake(auth.TransactOpts)
 	if err != nil {
 		return errors.Wrap(err, "contract failed")
 	}
-	level.Info(logger).Log("msg", "stake depositied", "tx", tx.Hash())
-	return nil
+	return auth.done(logger, tx, "deposit")
 }
 
 type withdrawCmd struct {
@@ -109,7 +105,7 @@ func (self withdrawCmd) Run() error {
 		return errors.Wrap(err, "creating ethereum client")
 	}
 
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	addr, err := self.address()
 	if err != nil {
 		return err
 	}
@@ -118,33 +114,29 @@ func (self withdrawCmd) Run() error {
 		return errors.Wrap(err, "create tellor contract instance")
 	}
 
-	status, startTime, err := contract.GetStakerInfo(nil, account.Address)
+	info, err := stake.GetInfo(ctx, contract, addr)
 	if err != nil {
-		return errors.Wrap(err, "get stake status")
+		return err
 	}
-	if status.Uint64() != 2 {
-		level.Info(logger).Log("msg", "can't withdraw")
-		printStakeStatus(logger, status, startTime)
-		return nil
+	if info.Status != stake.StatusLockedForWithdraw {
+		logStakeInfo(logger, info)
+		return skipped(logger, "withdraw", addr, "the stake status is "+stake.StatusName(info.Status)+", request a withdraw first")
 	}
-
-	var gasPrice *big.Int
-	if self.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
+	if time.Now().Before(info.WithdrawAt) {
+		logStakeInfo(logger, info)
+		return skipped(logger, "withdraw", addr, "the stake can be withdrawn after "+info.WithdrawAt.UTC().String())
 	}
 
-	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := self.newTransaction(ctx, client, addr)
 	if err != nil {
-		return errors.Wrap(err, "prepare ethereum transaction")
+		return err
 	}
 
-	tx, err := contract.WithdrawStake(auth)
+	tx, err := contract.WithdrawStake(auth.TransactOpts)
 	if err != nil {
 		return errors.Wrap(err, "contract")
 	}
-	level.Info(logger).Log("msg", "withdrew stake", "txHash", tx.Hash().Hex())
-
-	return nil
+	return auth.done(logger, tx, "withdraw")
 }
 
 type requestCmd struct {
@@ -155,7 +147,7 @@ func (self requestCmd) Run() error {
 	logger := logging.NewLogger()
 	ctx := context.Background()
 
-	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	cfg, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
 	if err != nil {
 		return errors.Wrap(err, "creating config")
 	}
@@ -164,7 +156,7 @@ func (self requestCmd) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "creating ethereum client")
 	}
-	account, err := ethereum.GetAccountByPubAddess(self.Addr)
+	addr, err := self.address()
 	if err != nil {
 		return err
 	}
@@ -173,37 +165,56 @@ func (self requestCmd) Run() error {
 		return errors.Wrap(err, "create tellor contract instance")
 	}
 
-	status, startTime, err := contract.GetStakerInfo(nil, account.Address)
-	if err != nil {
-		return errors.Wrap(err, "get stake status")
-	}
-	if status.Uint64() != 1 {
-		printStakeStatus(logger, status, startTime)
-		return nil
-	}
-
-	var gasPrice *big.Int
-	if self.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
+	if err := checkWithdrawRequest(ctx, client, contract, cfg, addr); err != nil {
+		return err
 	}
 
-	auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+	auth, err := self.newTransaction(ctx, client, addr)
 	if err != nil {
-		return errors.Wrap(err, "prepare ethereum transaction")
+		return err
 	}
 
-	tx, err := contract.RequestStakingWithdraw(auth)
+	tx, err := contract.RequestStakingWithdraw(auth.TransactOpts)
 	if err != nil {
 		return errors.Wrap(err, "contract")
 	}
+	return auth.done(logger, tx, "request")
+}
 
-	level.Info(logger).Log("msg", "withdrawal request sent", "txHash", tx.Hash().Hex())
-
+// checkWithdrawRequest returns an error when the stake of the account shouldn't be locked for withdraw.
+// This is when it is not staked, its miner is in dispute or has a submit that is not yet confirmed.
+func checkWithdrawRequest(ctx context.Context, client ethereum.EthClient, contract *contracts.ITellor, cfg *config.Config, addr common.Address) error {
+	info, err := stake.GetInfo(ctx, contract, addr)
+	if err != nil {
+		return err
+	}
+	if info.Status != stake.StatusStaked {
+		return errors.Errorf("account is not in a status that can request a withdraw:%v", stake.StatusName(info.Status))
+	}
+	pending, err := tellor.HasPendingSubmit(ctx, client, cfg.SubmitterTellor.StateDir, addr)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return errors.New("account has a pending submit, stop its miner and wait for the submit to be confirmed")
+	}
+	nonce, err := client.NonceAt(ctx, addr, nil)
+	if err != nil {
+		return errors.Wrap(err, "getting nonce")
+	}
+	pendingNonce, err := client.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return errors.Wrap(err, "getting pending nonce")
+	}
+	if pendingNonce > nonce {
+		return errors.Errorf("account has %v pending transactions", pendingNonce-nonce)
+	}
 	return nil
 }
 
 type statusCmd struct {
-	cfgAddr
+	cfg
+	Addr string `arg:"" optional:"" help:"show only this address instead of all accounts"`
 }
 
 func (self statusCmd) Run() error {
@@ -225,42 +236,171 @@ func (self statusCmd) Run() error {
 		return errors.Wrap(err, "create tellor contract instance")
 	}
 
-	valid := common.IsHexAddress(self.Addr)
-	if !valid {
-		return errors.Errorf("invalid etherum address:%v", self.Addr)
+	var addrs []common.Address
+	if self.Addr != "" {
+		if !common.IsHexAddress(self.Addr) {
+			return errors.Errorf("invalid etherum address:%v", self.Addr)
+		}
+		addrs = append(addrs, common.HexToAddress(self.Addr))
+	} else {
+		accounts, err := ethereum.GetAccounts()
+		if err != nil {
+			return errors.Wrap(err, "getting accounts")
+		}
+		for _, account := range accounts {
+			addrs = append(addrs, account.Address)
+		}
+	}
+
+	result := make([]stakeStatusResult, 0, len(addrs))
+	for _, addr := range addrs {
+		info, err := stake.GetInfo(ctx, contract, addr)
+		if err != nil {
+			return errors.Wrapf(err, "getting stake info for:%v", addr.String())
+		}
+		status := stakeStatusResult{
+			Address:    addr,
+			Status:     stake.StatusName(info.Status),
+			WithdrawAt: info.WithdrawAt,
+			TRB:        math.BigInt18eToFloat(info.Balance),
+		}
+		if info.Status == stake.StatusLockedForWithdraw {
+			status.WithdrawIn = "now"
+			if wait := time.Until(info.WithdrawAt); wait > 0 {
+				status.WithdrawIn = wait.Round(time.Minute).String()
+			}
+		}
+		if info.Status != stake.StatusNotStaked {
+			status.Since = info.Since
+		}
+		result = append(result, status)
+	}
+	return output(result)
+}
+
+type stakeStatusResult struct {
+	Address    common.Address `json:"address"`
+	Status     string         `json:"status"`
+	Since      time.Time      `json:"since"`
+	WithdrawAt time.Time      `json:"withdrawAt"`
+	WithdrawIn string         `json:"withdrawIn"`
+	TRB        float64        `json:"trb"`
+}
+
+type scheduleCmd struct {
+	cfgGasAddr
+}
+
+// Run requests a withdraw, waits until the stake is unlocked and withdraws it.
+// It continues from the current stake status so it can be run again after an interruption.
+func (self scheduleCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if self.Offline != "" {
+		return errors.New("the schedule waits for its transactions to be mined so it can't run in the offline mode")
+	}
+
+	cfg, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	addr, err := self.address()
+	if err != nil {
+		return err
+	}
+	contract, err := contracts.NewITellor(client)
+	if err != nil {
+		return errors.Wrap(err, "create tellor contract instance")
+	}
+
+	info, err := stake.GetInfo(ctx, contract, addr)
+	if err != nil {
+		return err
+	}
+	if info.Status == stake.StatusStaked {
+		if err := checkWithdrawRequest(ctx, client, contract, cfg, addr); err != nil {
+			return err
+		}
+		auth, err := self.newTransaction(ctx, client, addr)
+		if err != nil {
+			return err
+		}
+		tx, err := contract.RequestStakingWithdraw(auth.TransactOpts)
+		if err != nil {
+			return errors.Wrap(err, "contract")
+		}
+		level.Info(logger).Log("msg", "withdrawal request sent, waiting for it to be mined", "txHash", tx.Hash().Hex())
+		if err := waitMined(ctx, client, tx); err != nil {
+			return err
+		}
+		info, err = stake.GetInfo(ctx, contract, addr)
+		if err != nil {
+			return err
+		}
+	}
+	if info.Status != stake.StatusLockedForWithdraw {
+		logStakeInfo(logger, info)
+		return errors.Errorf("account is not in a status that can withdraw:%v", stake.StatusName(info.Status))
 	}
-	addr := common.HexToAddress(self.Addr)
 
-	status, startTime, err := contract.GetStakerInfo(&bind.CallOpts{Context: ctx}, addr)
+	if wait := time.Until(info.WithdrawAt); wait > 0 {
+		level.Info(logger).Log("msg", "waiting until the stake can be withdrawn", "at", info.WithdrawAt.UTC(), "wait", wait.Round(time.Minute))
+		select {
+		case <-ctx.Done():
+			level.Info(logger).Log("msg", "interrupted, run again to continue waiting")
+			return nil
+		case <-time.After(wait):
+		}
+	}
+
+	auth, err := self.newTransaction(ctx, client, addr)
+	if err != nil {
+		return err
+	}
+	tx, err := contract.WithdrawStake(auth.TransactOpts)
 	if err != nil {
-		return errors.Wrap(err, "get stake status")
+		return errors.Wrap(err, "contract")
 	}
+	level.Info(logger).Log("msg", "withdraw sent, waiting for it to be mined", "txHash", tx.Hash().Hex())
+	if err := waitMined(ctx, client, tx); err != nil {
+		return err
+	}
+	return auth.done(logger, tx, "withdraw")
+}
 
-	printStakeStatus(logger, status, startTime)
+func waitMined(ctx context.Context, client ethereum.EthClient, tx *types.Transaction) error {
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		return errors.Wrap(err, "waiting for the transaction to be mined")
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return errors.Errorf("transaction failed:%v", tx.Hash().Hex())
+	}
 	return nil
 }
 
-func printStakeStatus(logger log.Logger, bigStatus *big.Int, started *big.Int) {
-	// 0-not Staked, 1=Staked, 2=LockedForWithdraw 3= OnDispute
-	status := bigStatus.Uint64()
-	stakeTime := time.Unix(started.Int64(), 0)
-	switch status {
-	case 0:
+func logStakeInfo(logger log.Logger, info *stake.Info) {
+	switch info.Status {
+	case stake.StatusNotStaked:
 		level.Info(logger).Log("msg", "not currently staked")
-	case 1:
-		level.Info(logger).Log("msg", "staked in good standing since", "UTC", stakeTime.UTC())
-	case 2:
-		startedRound := started.Int64()
-		startedRound = ((startedRound + 86399) / 86400) * 86400
-		target := time.Unix(startedRound, 0)
-		timePassed := time.Since(target)
-		delta := timePassed - (time.Hour * 24 * 7)
-		if delta > 0 {
+	case stake.StatusStaked:
+		level.Info(logger).Log("msg", "staked in good standing since", "UTC", info.Since.UTC())
+	case stake.StatusLockedForWithdraw:
+		if delta := time.Since(info.WithdrawAt); delta > 0 {
 			level.Info(logger).Log("msg", "stake has been eligbile to withdraw for", "delta", delta)
 		} else {
 			level.Info(logger).Log("msg", "stake will be eligible to withdraw in", "delta", -delta)
 		}
-	case 3:
+	case stake.StatusOnDispute:
 		level.Info(logger).Log("msg", "stake is currently under dispute")
+	default:
+		level.Info(logger).Log("msg", "stake status", "status", stake.StatusName(info.Status))
 	}
 }
@@ -5,11 +5,9 @@ package cli
 
 import (
 	"context"
-	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/params"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/config"
@@ -69,18 +67,9 @@ func (self *transferCmd) Run() error {
 			math.BigInt18eToFloat(amount))
 	}
 
-	var gasPrice *big.Int
-	if self.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
-	}
-
-	acc, err := ethereum.GetAccountByPubAddess(self.From)
-	if err != nil {
-		return errors.Wrap(err, "getting auth account")
-	}
-	fromAuth, err := ethereum.PrepareEthTransaction(ctx, client, acc, gasPrice)
+	fromAuth, err := self.newTransaction(ctx, client, from)
 	if err != nil {
-		return errors.Wrap(err, "preparing ethereum transaction")
+		return err
 	}
 
 	valid = common.IsHexAddress(self.To)
@@ -89,17 +78,14 @@ func (self *transferCmd) Run() error {
 	}
 	to := common.HexToAddress(self.To)
 
-	tx, err := contract.Transfer(fromAuth, to, amount)
+	tx, err := contract.Transfer(fromAuth.TransactOpts, to, amount)
 	if err != nil {
 		return errors.Wrap(err, "calling transfer")
 	}
-	level.Info(logger).Log(
-		"msg", "transferred",
+	return fromAuth.done(logger, tx, "transfer",
 		"amount", math.BigInt18eToFloat(amount),
 		"to", to.String()[:12],
-		"tx", tx.Hash(),
 	)
-	return nil
 }
 
 type approveCmd tokenCmd
@@ -144,19 +130,9 @@ func (self *approveCmd) Run() error {
 			math.BigInt18eToFloat(amount))
 	}
 
-	var gasPrice *big.Int
-	if self.GasPrice > 0 {
-		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
-	}
-
-	acc, err := ethereum.GetAccountByPubAddess(self.From)
+	fromAuth, err := self.newTransaction(ctx, client, from)
 	if err != nil {
-		return errors.Wrap(err, "getting auth account")
-	}
-
-	fromAuth, err := ethereum.PrepareEthTransaction(ctx, client, acc, gasPrice)
-	if err != nil {
-		return errors.Wrap(err, "preparing ethereum transaction")
+		return err
 	}
 
 	valid = common.IsHexAddress(self.To)
@@ -165,13 +141,11 @@ func (self *approveCmd) Run() error {
 	}
 	spender := common.HexToAddress(self.To)
 
-	tx, err := contract.Approve(fromAuth, spender, amount)
+	tx, err := contract.Approve(fromAuth.TransactOpts, spender, amount)
 	if err != nil {
 		return errors.Wrap(err, "calling approve")
 	}
-	level.Info(logger).Log("msg", "approved", "amount", math.BigInt18eToFloat(amount), "spender", spender.String()[:12], "tx", tx.Hash())
-	return nil
-
+	return fromAuth.done(logger, tx, "approve", "amount", math.BigInt18eToFloat(amount), "spender", spender.String()[:12])
 }
 
 type balanceCmd struct {
@@ -213,11 +187,15 @@ func (self *balanceCmd) Run() error {
 		return errors.Wrapf(err, "getting trb balance")
 	}
 
-	level.Info(logger).Log(
-		"msg", "balance check",
-		"address", addr.String(),
-		"ETH", math.BigInt18eToFloat(ethBalance),
-		"TRB", math.BigInt18eToFloat(trbBalance),
-	)
-	return nil
+	return output(balanceResult{
+		Address: addr,
+		ETH:     math.BigInt18eToFloat(ethBalance),
+		TRB:     math.BigInt18eToFloat(trbBalance),
+	})
+}
+
+type balanceResult struct {
+	Address common.Address `json:"address"`
+	ETH     float64        `json:"eth"`
+	TRB     float64        `json:"trb"`
 }
@@ -0,0 +1,210 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+// transaction is a transaction of a command that is either signed and sent
+// or in the offline mode written unsigned to a file.
+type transaction struct {
+	*bind.TransactOpts
+	offline string
+	chainID *big.Int
+}
+
+// newTransaction prepares a transaction from the given address.
+// In the offline mode it doesn't need the private key of the address.
+func (self cfgGas) newTransaction(ctx context.Context, client ethereum.EthClient, from common.Address) (*transaction, error) {
+	var gasPrice *big.Int
+	if self.GasPrice > 0 {
+		gasPrice = big.NewInt(int64(self.GasPrice) * params.GWei)
+	}
+
+	var tx *transaction
+	if self.Offline != "" {
+		auth, chainID, err := ethereum.PrepareOfflineTransaction(ctx, client, from, gasPrice)
+		if err != nil {
+			return nil, errors.Wrap(err, "prepare offline transaction")
+		}
+		tx = &transaction{TransactOpts: auth, offline: self.Offline, chainID: chainID}
+	} else {
+		account, err := ethereum.GetAccountByPubAddess(from.Hex())
+		if err != nil {
+			return nil, err
+		}
+		auth, err := ethereum.PrepareEthTransaction(ctx, client, account, gasPrice)
+		if err != nil {
+			return nil, errors.Wrap(err, "prepare ethereum transaction")
+		}
+		tx = &transaction{TransactOpts: auth}
+	}
+
+	// The offline transactions are not pending until broadcast so
+	// the node returns the same nonce for all of them.
+	if self.Nonce >= 0 {
+		next, err := client.NonceAt(ctx, from, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting nonce")
+		}
+		if uint64(self.Nonce) < next {
+			return nil, errors.Errorf("the nonce:%v is already used, the next nonce is:%v", self.Nonce, next)
+		}
+		tx.Nonce = big.NewInt(self.Nonce)
+	}
+	return tx, nil
+}
+
+// Statuses of the transaction results.
+const (
+	txSent     = "sent"
+	txMined    = "mined"
+	txUnsigned = "unsigned"
+	txSigned   = "signed"
+	// txSkipped is a command that had nothing to send.
+	txSkipped = "skipped"
+)
+
+// txResult is the result of the commands that send, sign or write a transaction.
+type txResult struct {
+	Action string         `json:"action"`
+	Status string         `json:"status"`
+	From   common.Address `json:"from"`
+	Nonce  uint64         `json:"nonce"`
+	// Tx is the hash of the signed transaction.
+	Tx string `json:"tx,omitempty"`
+	// File is the unsigned or signed transaction file.
+	File string `json:"file,omitempty"`
+	// Reason is why nothing was sent for a skipped command.
+	Reason string `json:"reason,omitempty"`
+}
+
+// done writes the result of the sent transaction or in the offline mode writes the transaction to the offline file.
+// The keyvals with the details of the action are logged.
+func (self *transaction) done(logger log.Logger, tx *types.Transaction, action string, keyvals ...interface{}) error {
+	result := txResult{Action: action, Status: txSent, From: self.From, Nonce: tx.Nonce(), Tx: tx.Hash().Hex()}
+	if self.offline != "" {
+		if err := ethereum.NewUnsignedTx(tx, self.chainID, self.From).Write(self.offline); err != nil {
+			return err
+		}
+		result.Status, result.Tx, result.File = txUnsigned, "", self.offline
+		level.Info(logger).Log(append([]interface{}{"msg", "unsigned transaction written, sign it with 'tx sign'", "action", action}, keyvals...)...)
+	} else {
+		level.Info(logger).Log(append([]interface{}{"msg", "transaction sent", "action", action}, keyvals...)...)
+	}
+	return output(result)
+}
+
+// skipped writes the result of a command that has nothing to send.
+func skipped(logger log.Logger, action string, from common.Address, reason string) error {
+	level.Info(logger).Log("msg", "nothing sent", "action", action, "reason", reason)
+	return output(txResult{Action: action, Status: txSkipped, From: from, Reason: reason})
+}
+
+// address parses the address argument of a command.
+func (self addr) address() (common.Address, error) {
+	if !common.IsHexAddress(self.Addr) {
+		return common.Address{}, errors.Errorf("invalid etherum address:%v", self.Addr)
+	}
+	return common.HexToAddress(self.Addr), nil
+}
+
+type txSignCmd struct {
+	cfg
+	File string `arg:"" type:"existingfile" help:"the unsigned transaction file"`
+	Out  string `optional:"" help:"file for the signed transaction, defaults to the unsigned transaction file with a .signed extension"`
+}
+
+func (self txSignCmd) Run() error {
+	logger := logging.NewLogger()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	unsigned, err := ethereum.ReadUnsignedTx(self.File)
+	if err != nil {
+		return err
+	}
+	account, err := ethereum.GetAccountByPubAddess(unsigned.From.Hex())
+	if err != nil {
+		return err
+	}
+	tx, err := unsigned.Sign(account)
+	if err != nil {
+		return errors.Wrap(err, "signing the transaction")
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "encoding the signed transaction")
+	}
+
+	out := self.Out
+	if out == "" {
+		out = self.File + ".signed"
+	}
+	if err := ioutil.WriteFile(out, []byte(hexutil.Encode(raw)), 0600); err != nil {
+		return errors.Wrap(err, "writing the signed transaction")
+	}
+	level.Info(logger).Log("msg", "transaction signed, send it with 'tx broadcast'")
+	return output(txResult{Action: "sign", Status: txSigned, From: unsigned.From, Nonce: tx.Nonce(), Tx: tx.Hash().Hex(), File: out})
+}
+
+type txBroadcastCmd struct {
+	cfg
+	File string `arg:"" type:"existingfile" help:"the signed transaction file"`
+}
+
+func (self txBroadcastCmd) Run() error {
+	logger := logging.NewLogger()
+	ctx := context.Background()
+
+	_, err := config.ParseConfig(logger, string(self.Config)) // Load the env file.
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	data, err := ioutil.ReadFile(self.File)
+	if err != nil {
+		return errors.Wrap(err, "reading the signed transaction")
+	}
+	raw, err := hexutil.Decode(strings.TrimSpace(string(data)))
+	if err != nil {
+		return errors.Wrap(err, "decoding the signed transaction")
+	}
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return errors.Wrap(err, "decoding the signed transaction")
+	}
+
+	client, err := ethereum.NewClient(ctx, logger)
+	if err != nil {
+		return errors.Wrap(err, "creating ethereum client")
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return errors.Wrap(err, "getting the transaction sender")
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return errors.Wrap(err, "sending the transaction")
+	}
+	return output(txResult{Action: "broadcast", Status: txSent, From: from, Nonce: tx.Nonce(), Tx: tx.Hash().Hex(), File: self.File})
+}
@@ -0,0 +1,53 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestNewOfflineTransaction(t *testing.T) {
+	ctx := context.Background()
+	accounts, err := simulation.NewAccounts(2)
+	testutil.Ok(t, err)
+	from, to := accounts[0].Address, accounts[1].Address
+	backend, err := simulation.NewBackend(logging.NewLogger(), simulation.Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Accounts:   []common.Address{from, to},
+		Balance:    big.NewInt(1e18),
+	})
+	testutil.Ok(t, err)
+
+	offline := cfgGas{Offline: filepath.Join(t.TempDir(), "tx.json"), Nonce: -1}
+	tx, err := offline.newTransaction(ctx, backend, from)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(0), tx.Nonce.Int64(), "defaults to the pending nonce")
+
+	offline.Nonce = 2
+	tx, err = offline.newTransaction(ctx, backend, from)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(2), tx.Nonce.Int64())
+
+	chainID, err := backend.NetworkID(ctx)
+	testutil.Ok(t, err)
+	signed, err := accounts[0].NewTransactor(chainID).Signer(from, types.NewTransaction(0, to, big.NewInt(1), params.TxGas, simulation.GasPrice, nil))
+	testutil.Ok(t, err)
+	testutil.Ok(t, backend.SendTransaction(ctx, signed))
+	backend.Commit()
+
+	offline.Nonce = 0
+	_, err = offline.newTransaction(ctx, backend, from)
+	testutil.NotOk(t, err, "a used nonce should be rejected")
+}
@@ -0,0 +1,63 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package cli
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/oklog/run"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/mining"
+)
+
+type workerCmd struct {
+	Config configPath `type:"existingfile" help:"path to config file"`
+	Pool   string     `arg:"" required:"" help:"the address of the mining pool, for example 192.168.1.10:3333"`
+	Name   string     `optional:"" help:"the worker name shown in the pool logs and metrics, defaults to the hostname"`
+}
+
+func (self workerCmd) Run() error {
+	logger := logging.NewLogger()
+
+	cfg, err := config.ParseConfig(logger, string(self.Config))
+	if err != nil {
+		return errors.Wrap(err, "creating config")
+	}
+
+	name := self.Name
+	if name == "" {
+		name, err = os.Hostname()
+		if err != nil {
+			return errors.Wrap(err, "getting the hostname")
+		}
+	}
+
+	worker, err := mining.NewWorker(logger, context.Background(), cfg.Mining, self.Pool, name)
+	if err != nil {
+		return errors.Wrap(err, "creating worker")
+	}
+
+	var g run.Group
+	g.Add(run.SignalHandler(context.Background(), syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM))
+	g.Add(func() error {
+		err := worker.Start()
+		level.Info(logger).Log("msg", "worker shutdown complete")
+		return err
+	}, func(error) {
+		worker.Stop()
+	})
+
+	if err := g.Run(); err != nil {
+		level.Error(logger).Log("msg", "main exited with error", "err", err)
+		return err
+	}
+
+	level.Info(logger).Log("msg", "main shutdown complete")
+	return nil
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/aggregator"
 	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
 	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/gasPrice/gasStation"
 	"github.com/tellor-io/telliot/pkg/mining"
@@ -26,9 +28,11 @@ import (
 	"github.com/tellor-io/telliot/pkg/tasker"
 	"github.com/tellor-io/telliot/pkg/tracker/dispute"
 	"github.com/tellor-io/telliot/pkg/tracker/index"
+	"github.com/tellor-io/telliot/pkg/tracker/lifecycle"
 	"github.com/tellor-io/telliot/pkg/tracker/profit"
 	"github.com/tellor-io/telliot/pkg/tracker/reward"
 	"github.com/tellor-io/telliot/pkg/transactor"
+	"github.com/tellor-io/telliot/pkg/voter"
 	"github.com/tellor-io/telliot/pkg/web"
 )
 
@@ -41,14 +45,18 @@ type Config struct {
 	ProfitTracker             profit.Config
 	RewardTracker             reward.Config
 	Tasker                    tasker.Config
+	EventHub                  events.Config
 	Transactor                transactor.Config
 	IndexTracker              index.Config
 	DisputeTracker            dispute.Config
+	Voter                     voter.Config
+	DisputeLifecycle          lifecycle.Config
 	Aggregator                aggregator.Config
 	PsrTellor                 psrTellor.Config
 	PsrTellorMesosphere       psrTellorMesosphere.Config
 	Db                        db.Config
 	GasStation                gasStation.Config
+	Accounts                  ethereum.AccountsConfig
 	// EnvFile location that include all private details like private key etc.
 	EnvFile string `json:"envFile"`
 }
@@ -57,6 +65,9 @@ var DefaultConfig = Config{
 	Mining: mining.Config{
 		LogLevel:  "info",
 		Heartbeat: time.Minute,
+		Pool: mining.PoolConfig{
+			ListenPort: 3333,
+		},
 	},
 	Web: web.Config{
 		LogLevel:   "info",
@@ -69,32 +80,66 @@ var DefaultConfig = Config{
 		RemoteTimeout: format.Duration{Duration: 5 * time.Second},
 	},
 	Tasker: tasker.Config{
+		LogLevel:                 "info",
+		ConfirmationDepth:        1,
+		EligibilityCheckInterval: format.Duration{Duration: time.Minute},
+	},
+	EventHub: events.Config{
 		LogLevel: "info",
 	},
 	ProfitTracker: profit.Config{
-		LogLevel: "info",
+		LogLevel:          "info",
+		ConfirmationDepth: 12,
 	},
 	RewardTracker: reward.Config{
-		LogLevel: "info",
+		LogLevel:          "info",
+		ConfirmationDepth: 1,
+		GasPriceInterval:  format.Duration{Duration: time.Minute},
+		GasPriceWindow:    format.Duration{Duration: time.Hour},
 	},
 	DisputeTracker: dispute.Config{
-		LogLevel: "info",
+		LogLevel:          "info",
+		ConfirmationDepth: 12,
+		Tolerance:         5,
+		Window:            format.Duration{Duration: 15 * time.Minute},
+	},
+	Voter: voter.Config{
+		LogLevel:          "info",
+		Policy:            voter.PolicyDryRun,
+		AuditLog:          "db/voter/audit.jsonl",
+		ConfirmInterval:   format.Duration{Duration: time.Minute},
+		ConfirmationDepth: 12,
+	},
+	DisputeLifecycle: lifecycle.Config{
+		LogLevel:          "info",
+		ConfirmationDepth: 12,
+		Interval:          format.Duration{Duration: 10 * time.Minute},
+		StateFile:         "db/disputes.json",
+		RetryAfter:        format.Duration{Duration: 30 * time.Minute},
 	},
 	Transactor: transactor.Config{
 		LogLevel:      "info",
 		GasMax:        10,
 		GasMultiplier: 1,
+		Relay: transactor.RelayConfig{
+			Method:         transactor.RelayMethodPrivateTx,
+			FallbackBlocks: 25,
+		},
+		DrainTimeout: format.Duration{Duration: time.Minute},
 	},
 	SubmitterTellor: tellor.Config{
 		Enabled:  true,
 		LogLevel: "info",
 		// With a 1 second delay here as a workaround to prevent a race condition in the oracle contract check.
 		MinSubmitPeriod: format.Duration{Duration: 15*time.Minute + 1*time.Second},
+		StateDir:        "db/submitter",
 	},
 	SubmitterTellorMesosphere: tellorMesosphere.Config{
 		LogLevel:             "info",
 		MinSubmitPeriod:      format.Duration{Duration: 15 * time.Second},
 		MinSubmitPriceChange: 0.05,
+		Heartbeat:            format.Duration{Duration: 5 * time.Minute},
+		RequestIDs:           []tellorMesosphere.RequestID{{ID: 1}, {ID: 2}},
 	},
 	PsrTellor: psrTellor.Config{
 		MinConfidence: 70,
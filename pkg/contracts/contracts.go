@@ -2,9 +2,10 @@ package contracts
 
 import (
 	"context"
+	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/contracts/balancer"
 	"github.com/tellor-io/telliot/pkg/contracts/lens"
@@ -25,6 +26,13 @@ const (
 	LensAddressHardhat                     = "0x577417CFaF319a1fAD90aA135E3848D2C00e68CF"
 )
 
+// Backend is the part of the ethereum client needed to
+// find the contract addresses for the current network and to interact with them.
+type Backend interface {
+	bind.ContractBackend
+	NetworkID(ctx context.Context) (*big.Int, error)
+}
+
 type (
 	ITellorNewDispute    = tellor.ITellorNewDispute
 	TellorNonceSubmitted = tellor.TellorNonceSubmitted
@@ -50,7 +58,7 @@ type ITellor struct {
 	Address common.Address
 }
 
-func NewITellor(client *ethclient.Client) (*ITellor, error) {
+func NewITellor(client Backend) (*ITellor, error) {
 	conractAddr, err := GetTellorAddress(client)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting contract address")
@@ -69,10 +77,10 @@ func NewITellor(client *ethclient.Client) (*ITellor, error) {
 		return nil, errors.Wrap(err, "creating telllor interface")
 	}
 
-	return &ITellor{Address: common.HexToAddress(TellorAddress), ITellor: tellorInstance, Main: lensInstance}, nil
+	return &ITellor{Address: conractAddr, ITellor: tellorInstance, Main: lensInstance}, nil
 }
 
-func NewITellorMesosphere(client *ethclient.Client) (*ITellorMesosphere, error) {
+func NewITellorMesosphere(client Backend) (*ITellorMesosphere, error) {
 	conractAddr, err := GetTellorMesosphereAddress(client)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting contract address")
@@ -82,10 +90,10 @@ func NewITellorMesosphere(client *ethclient.Client) (*ITellorMesosphere, error)
 		return nil, errors.Wrap(err, "creating telllor interface")
 	}
 
-	return &ITellorMesosphere{Address: common.HexToAddress(TellorMesosphereAddress), TellorMesosphere: tellorInstance}, nil
+	return &ITellorMesosphere{Address: conractAddr, TellorMesosphere: tellorInstance}, nil
 }
 
-func GetTellorMesosphereAddress(client *ethclient.Client) (common.Address, error) {
+func GetTellorMesosphereAddress(client Backend) (common.Address, error) {
 	networkID, err := client.NetworkID(context.Background())
 	if err != nil {
 		return common.Address{}, err
@@ -100,7 +108,7 @@ func GetTellorMesosphereAddress(client *ethclient.Client) (common.Address, error
 	}
 }
 
-func GetTellorAddress(client *ethclient.Client) (common.Address, error) {
+func GetTellorAddress(client Backend) (common.Address, error) {
 	networkID, err := client.NetworkID(context.Background())
 	if err != nil {
 		return common.Address{}, err
@@ -120,7 +128,7 @@ func GetTellorAddress(client *ethclient.Client) (common.Address, error) {
 	}
 }
 
-func GetLensAddress(client *ethclient.Client) (common.Address, error) {
+func GetLensAddress(client Backend) (common.Address, error) {
 	networkID, err := client.NetworkID(context.Background())
 	if err != nil {
 		return common.Address{}, err
@@ -0,0 +1,223 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package contracts
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// ParseMethod parses a method signature like transfer(address,uint256).
+// The output types are optional and follow the inputs like balanceOf(address)(uint256)
+// or balanceOf(address) returns (uint256). Tuples are not supported.
+func ParseMethod(signature string) (abi.Method, error) {
+	signature = strings.TrimSpace(signature)
+	open := strings.Index(signature, "(")
+	if open <= 0 {
+		return abi.Method{}, errors.Errorf("missing method name or inputs:%v", signature)
+	}
+	name := strings.TrimSpace(signature[:open])
+	inputs, rest, err := parseTypes(signature[open:])
+	if err != nil {
+		return abi.Method{}, errors.Wrapf(err, "parsing the inputs of:%v", signature)
+	}
+
+	var outputs abi.Arguments
+	rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), "returns"))
+	if rest != "" {
+		outputs, rest, err = parseTypes(rest)
+		if err != nil {
+			return abi.Method{}, errors.Wrapf(err, "parsing the outputs of:%v", signature)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return abi.Method{}, errors.Errorf("unexpected text after the outputs:%v", rest)
+		}
+	}
+	return abi.NewMethod(name, name, abi.Function, "", false, true, inputs, outputs), nil
+}
+
+// parseTypes parses a list of types in parentheses and returns the text after it.
+func parseTypes(s string) (abi.Arguments, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", errors.Errorf("expected a list of types in parentheses:%v", s)
+	}
+	end := strings.Index(s, ")")
+	if end < 0 {
+		return nil, "", errors.Errorf("missing closing parentheses:%v", s)
+	}
+	var args abi.Arguments
+	if list := strings.TrimSpace(s[1:end]); list != "" {
+		for _, t := range strings.Split(list, ",") {
+			// Drop the argument name when there is one.
+			t = strings.Fields(t + " ")[0]
+			typ, err := abi.NewType(t, "", nil)
+			if err != nil {
+				return nil, "", errors.Wrapf(err, "parsing type:%v", t)
+			}
+			args = append(args, abi.Argument{Type: typ})
+		}
+	}
+	return args, s[end+1:], nil
+}
+
+// PackCall returns the call data of the method with the arguments parsed from strings.
+// Lists are in brackets like [1,2,3], bytes are hex encoded and numbers can be decimal or hex.
+func PackCall(method abi.Method, args []string) ([]byte, error) {
+	if len(args) != len(method.Inputs) {
+		return nil, errors.Errorf("the method %v needs %v arguments, got %v", method.Sig, len(method.Inputs), len(args))
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := parseValue(method.Inputs[i].Type, arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "argument %v", i)
+		}
+		values[i] = v.Interface()
+	}
+	packed, err := method.Inputs.Pack(values...)
+	if err != nil {
+		return nil, errors.Wrap(err, "packing the arguments")
+	}
+	return append(method.ID, packed...), nil
+}
+
+func parseValue(t abi.Type, s string) (reflect.Value, error) {
+	s = strings.TrimSpace(s)
+	switch t.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(s) {
+			return reflect.Value{}, errors.Errorf("invalid address:%v", s)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return reflect.Value{}, errors.Errorf("invalid number:%v", s)
+		}
+		if (t.T == abi.UintTy && (n.Sign() < 0 || n.BitLen() > t.Size)) || (t.T == abi.IntTy && n.BitLen() >= t.Size) {
+			return reflect.Value{}, errors.Errorf("number out of range for %v:%v", t.String(), s)
+		}
+		if t.GetType() == reflect.TypeOf(n) {
+			return reflect.ValueOf(n), nil
+		}
+		if t.T == abi.UintTy {
+			return reflect.ValueOf(n.Uint64()).Convert(t.GetType()), nil
+		}
+		return reflect.ValueOf(n.Int64()).Convert(t.GetType()), nil
+	case abi.BoolTy:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "invalid bool:%v", s)
+		}
+		return reflect.ValueOf(b), nil
+	case abi.StringTy:
+		return reflect.ValueOf(s), nil
+	case abi.BytesTy:
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "invalid bytes:%v", s)
+		}
+		return reflect.ValueOf(b), nil
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return reflect.Value{}, errors.Wrapf(err, "invalid bytes:%v", s)
+		}
+		if len(b) > t.Size {
+			return reflect.Value{}, errors.Errorf("more than %v bytes:%v", t.Size, s)
+		}
+		v := reflect.New(t.GetType()).Elem()
+		reflect.Copy(v, reflect.ValueOf(b))
+		return v, nil
+	case abi.SliceTy, abi.ArrayTy:
+		items, err := splitList(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		var v reflect.Value
+		if t.T == abi.SliceTy {
+			v = reflect.MakeSlice(t.GetType(), len(items), len(items))
+		} else {
+			if len(items) != t.Size {
+				return reflect.Value{}, errors.Errorf("expected %v items:%v", t.Size, s)
+			}
+			v = reflect.New(t.GetType()).Elem()
+		}
+		for i, item := range items {
+			elem, err := parseValue(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, errors.Wrapf(err, "item %v", i)
+			}
+			v.Index(i).Set(elem)
+		}
+		return v, nil
+	default:
+		return reflect.Value{}, errors.Errorf("unsupported type:%v", t.String())
+	}
+}
+
+// splitList splits a list like [1,[2,3]] into its top level items.
+func splitList(s string) ([]string, error) {
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, errors.Errorf("expected a list in brackets:%v", s)
+	}
+	s = strings.TrimSpace(s[1 : len(s)-1])
+	if s == "" {
+		return nil, nil
+	}
+	var items []string
+	var depth, start int
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(items, s[start:]), nil
+}
+
+// FormatValue formats a value returned by a contract call.
+func FormatValue(v interface{}) string {
+	switch x := v.(type) {
+	case common.Address:
+		return x.Hex()
+	case []byte:
+		return hexutil.Encode(x)
+	case *big.Int:
+		return x.String()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = FormatValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
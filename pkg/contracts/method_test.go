@@ -0,0 +1,80 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package contracts
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestParseMethod(t *testing.T) {
+	method, err := ParseMethod("transfer(address,uint256)")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "transfer(address,uint256)", method.Sig)
+	// The well known ERC20 transfer selector.
+	testutil.Equals(t, "0xa9059cbb", hexutil.Encode(method.ID))
+	testutil.Equals(t, 0, len(method.Outputs))
+
+	method, err = ParseMethod("balanceOf(address _user) returns (uint256)")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "balanceOf(address)", method.Sig)
+	testutil.Equals(t, 1, len(method.Outputs))
+
+	method, err = ParseMethod("getStakerInfo(address)(uint256,uint256)")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(method.Outputs))
+
+	for _, invalid := range []string{"transfer", "(address)", "transfer(address", "transfer(addr)", "f()(uint256) x"} {
+		_, err := ParseMethod(invalid)
+		testutil.NotOk(t, err, invalid)
+	}
+}
+
+func TestPackCall(t *testing.T) {
+	method, err := ParseMethod("transfer(address,uint256)")
+	testutil.Ok(t, err)
+	data, err := PackCall(method, []string{"0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0", "0x10"})
+	testutil.Ok(t, err)
+	values, err := method.Inputs.Unpack(data[4:])
+	testutil.Ok(t, err)
+	testutil.Equals(t, common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0"), values[0])
+	testutil.Equals(t, big.NewInt(16), values[1])
+
+	method, err = ParseMethod("f(uint8,int64,bool,bytes4,uint256[],address[2],string)")
+	testutil.Ok(t, err)
+	data, err = PackCall(method, []string{
+		"255",
+		"-5",
+		"true",
+		"0x01020304",
+		"[1, 2, 3]",
+		"[0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002]",
+		"hello",
+	})
+	testutil.Ok(t, err)
+	values, err = method.Inputs.Unpack(data[4:])
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint8(255), values[0])
+	testutil.Equals(t, int64(-5), values[1])
+	testutil.Equals(t, true, values[2])
+	testutil.Equals(t, "0x01020304", FormatValue(values[3]))
+	testutil.Equals(t, "[1,2,3]", FormatValue(values[4]))
+	testutil.Equals(t, "[0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002]", FormatValue(values[5]))
+	testutil.Equals(t, "hello", values[6])
+
+	for _, args := range [][]string{
+		{"256", "0", "true", "0x", "[]", "[0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002]", ""},
+		{"1", "0", "yes", "0x", "[]", "[0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002]", ""},
+		{"1", "0", "true", "0x0102030405", "[]", "[0x0000000000000000000000000000000000000001,0x0000000000000000000000000000000000000002]", ""},
+		{"1", "0", "true", "0x", "[]", "[0x0000000000000000000000000000000000000001]", ""},
+		{"1"},
+	} {
+		_, err := PackCall(method, args)
+		testutil.NotOk(t, err, args)
+	}
+}
@@ -5,17 +5,17 @@ package ethereum
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"math/big"
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
@@ -78,12 +78,49 @@ func DecodeHex(s string) []byte {
 
 func PrepareEthTransaction(
 	ctx context.Context,
-	client *ethclient.Client,
+	client EthClient,
 	account *Account,
 	gasPrice *big.Int,
 ) (*bind.TransactOpts, error) {
+	netID, err := client.NetworkID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting network id")
+	}
+	return prepareTransaction(ctx, client, account.NewTransactor(netID), gasPrice)
+}
 
-	nonce, err := client.PendingNonceAt(ctx, account.GetAddress())
+// PrepareOfflineTransaction is like PrepareEthTransaction, but doesn't need the private key of the account.
+// The transaction is only built and returned unsigned by the contract bindings so that it can be signed on another host.
+// It also returns the chain ID that the transaction needs to be signed for.
+func PrepareOfflineTransaction(
+	ctx context.Context,
+	client EthClient,
+	from common.Address,
+	gasPrice *big.Int,
+) (*bind.TransactOpts, *big.Int, error) {
+	netID, err := client.NetworkID(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting network id")
+	}
+	auth := &bind.TransactOpts{
+		From: from,
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		},
+		NoSend:  true,
+		Context: context.Background(),
+	}
+	auth, err = prepareTransaction(ctx, client, auth, gasPrice)
+	return auth, netID, err
+}
+
+func prepareTransaction(
+	ctx context.Context,
+	client EthClient,
+	auth *bind.TransactOpts,
+	gasPrice *big.Int,
+) (*bind.TransactOpts, error) {
+	nonce, err := client.PendingNonceAt(ctx, auth.From)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting pending nonce")
 	}
@@ -95,7 +132,7 @@ func PrepareEthTransaction(
 		}
 	}
 
-	ethBalance, err := client.BalanceAt(ctx, account.GetAddress(), nil)
+	ethBalance, err := client.BalanceAt(ctx, auth.From, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting balance")
 	}
@@ -106,15 +143,6 @@ func PrepareEthTransaction(
 		return nil, errors.Errorf("insufficient ethereum to send a transaction: %v < %v", ethBalance, cost)
 	}
 
-	netID, err := client.NetworkID(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "getting network id")
-	}
-
-	auth, err := bind.NewKeyedTransactorWithChainID(account.GetPrivateKey(), netID)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating transactor")
-	}
 	auth.Nonce = big.NewInt(int64(nonce))
 	auth.Value = big.NewInt(0)        // in wei
 	auth.GasLimit = uint64(3_000_000) // in units
@@ -122,6 +150,31 @@ func PrepareEthTransaction(
 	return auth, nil
 }
 
+// BlockBefore estimates the block number mined the given duration ago from the recent average block time.
+func BlockBefore(ctx context.Context, client EthClient, d time.Duration) (uint64, error) {
+	const sample = 1000
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "get latest eth block header")
+	}
+	if head.Number.Uint64() <= sample {
+		return 0, nil
+	}
+	past, err := client.HeaderByNumber(ctx, new(big.Int).Sub(head.Number, big.NewInt(sample)))
+	if err != nil {
+		return 0, errors.Wrap(err, "get past eth block header")
+	}
+	blockTime := time.Duration(head.Time-past.Time) * time.Second / sample
+	if blockTime <= 0 {
+		return 0, nil
+	}
+	blocks := uint64(d / blockTime)
+	if blocks >= head.Number.Uint64() {
+		return 0, nil
+	}
+	return head.Number.Uint64() - blocks, nil
+}
+
 func Keccak256(input []byte) [32]byte {
 	hash := crypto.Keccak256(input)
 	var hashed [32]byte
@@ -131,16 +184,26 @@ func Keccak256(input []byte) [32]byte {
 }
 
 type Account struct {
-	Address    common.Address
-	PrivateKey *ecdsa.PrivateKey
+	Address common.Address
+	Signer  Signer
 }
 
 func (a *Account) GetAddress() common.Address {
 	return a.Address
 }
 
-func (a *Account) GetPrivateKey() *ecdsa.PrivateKey {
-	return a.PrivateKey
+// NewTransactor returns transact options that sign with the account signer.
+func (a *Account) NewTransactor(chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: a.Address,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != a.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return a.Signer.SignTx(tx, chainID)
+		},
+		Context: context.Background(),
+	}
 }
 
 func GetAccountByPubAddess(pubAddr string) (*Account, error) {
@@ -157,57 +220,61 @@ func GetAccountByPubAddess(pubAddr string) (*Account, error) {
 	return nil, errors.Errorf("account not found:%v", pubAddr)
 }
 
-// GetAccounts returns a slice of Account from private keys in
-// PrivateKeysEnvName environment variable.
+// GetAccounts returns a slice of Account from all configured signers -
+// private keys in the PrivateKeysEnvName environment variable,
+// encrypted keystore files in KeystoreDirEnvName and
+// a remote signer at RemoteSignerURLEnvName.
 func GetAccounts() ([]*Account, error) {
-	_privateKeys := os.Getenv(PrivateKeysEnvName)
-	privateKeys := strings.Split(_privateKeys, ",")
+	var accounts []*Account
 
 	// Create an Account instance per private keys.
-	accounts := make([]*Account, len(privateKeys))
-	for i, pkey := range privateKeys {
-		privateKey, err := crypto.HexToECDSA(strings.TrimSpace(pkey))
+	if privateKeys := os.Getenv(PrivateKeysEnvName); privateKeys != "" {
+		for _, pkey := range strings.Split(privateKeys, ",") {
+			account, err := privateKeyAccount(pkey)
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, account)
+		}
+	}
+
+	if dir := os.Getenv(KeystoreDirEnvName); dir != "" {
+		keystoreAccs, err := keystoreAccounts(dir)
 		if err != nil {
-			return nil, errors.Wrap(err, "getting private key to ECDSA")
+			return nil, errors.Wrap(err, "getting keystore accounts")
 		}
+		accounts = append(accounts, keystoreAccs...)
+	}
 
-		publicKey := privateKey.Public()
-		publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-		if !ok {
-			return nil, errors.New("casting public key to ECDSA")
+	if url := os.Getenv(RemoteSignerURLEnvName); url != "" {
+		remoteAccs, err := remoteSignerAccounts(url)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting remote signer accounts")
 		}
+		accounts = append(accounts, remoteAccs...)
+	}
 
-		publicAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-		accounts[i] = &Account{Address: publicAddress, PrivateKey: privateKey}
+	if len(accounts) == 0 {
+		return nil, errors.Errorf("no accounts configured, set at least one of %v, %v or %v", PrivateKeysEnvName, KeystoreDirEnvName, RemoteSignerURLEnvName)
 	}
 	return accounts, nil
 }
 
-func NewClient(ctx context.Context, logger log.Logger) (*ethclient.Client, error) {
-	nodeURL := os.Getenv(NodeURLEnvName)
-
-	client, err := ethclient.DialContext(ctx, nodeURL)
+// NewClient creates a client pool for all the node URLs
+// in the NodeURLEnvName environment variable separated by `,`.
+func NewClient(ctx context.Context, logger log.Logger) (*ClientPool, error) {
+	pool, err := NewClientPool(ctx, logger, strings.Split(os.Getenv(NodeURLEnvName), ","))
 	if err != nil {
-		return nil, errors.Wrap(err, "create rpc client instance")
-	}
-
-	if !strings.Contains(strings.ToLower(nodeURL), "arbitrum") { // Arbitrum nodes doesn't support sync checking.
-		// Issue #55, halt if client is still syncing with Ethereum network
-		s, err := client.SyncProgress(ctx)
-		if err != nil {
-			return nil, errors.Wrap(err, "determining if Ethereum client is syncing")
-		}
-		if s != nil {
-			return nil, errors.New("ethereum node is still syncing with the network")
-		}
+		return nil, errors.Wrap(err, "create client pool")
 	}
 
-	id, err := client.NetworkID(ctx)
+	id, err := pool.NetworkID(ctx)
 	if err != nil {
-		return nil, level.Error(logger).Log("msg", "get nerwork ID", "err", err)
+		pool.Close()
+		return nil, errors.Wrap(err, "get network ID")
 	}
 
 	level.Info(logger).Log("msg", "client created", "netID", id.String())
 
-	return client, nil
+	return pool, nil
 }
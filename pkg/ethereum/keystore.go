@@ -0,0 +1,179 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Scrypt parameters of the new keystore files, lowered in the tests.
+var (
+	keystoreScryptN = keystore.StandardScryptN
+	keystoreScryptP = keystore.StandardScryptP
+)
+
+// NewKeystoreAccount generates a new key and stores it encrypted in the keystore dir.
+// It returns the address and the path of the keystore file.
+func NewKeystoreAccount(dir string) (common.Address, string, error) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return common.Address{}, "", errors.Wrap(err, "generating key")
+	}
+	return storeKey(dir, privateKey)
+}
+
+// ImportKeystoreAccount stores a hex encoded private key encrypted in the keystore dir.
+func ImportKeystoreAccount(dir string, privateKey string) (common.Address, string, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(privateKey), "0x"))
+	if err != nil {
+		return common.Address{}, "", errors.Wrap(err, "parsing the private key")
+	}
+	return storeKey(dir, key)
+}
+
+// ExportKeystoreAccount decrypts the keystore file of the address and returns its hex encoded private key.
+func ExportKeystoreAccount(dir string, addr common.Address) (string, error) {
+	path, err := keystoreFile(dir, addr)
+	if err != nil {
+		return "", err
+	}
+	keyJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading keystore file:%v", path)
+	}
+	password, err := keystorePassword()
+	if err != nil {
+		return "", err
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return "", errors.Wrapf(err, "decrypting keystore file:%v", path)
+	}
+	return fmt.Sprintf("%x", crypto.FromECDSA(key.PrivateKey)), nil
+}
+
+// storeKey encrypts the key with the keystore password and writes it to a new file in the keystore dir.
+// The file name is the same as the one used by geth.
+func storeKey(dir string, privateKey *ecdsa.PrivateKey) (common.Address, string, error) {
+	addr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	if _, err := keystoreFile(dir, addr); err == nil {
+		return common.Address{}, "", errors.Errorf("account already in the keystore:%v", addr.Hex())
+	}
+
+	password, err := newKeystorePassword()
+	if err != nil {
+		return common.Address{}, "", err
+	}
+	if err := checkKeystorePassword(dir, password); err != nil {
+		return common.Address{}, "", err
+	}
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.New(),
+		Address:    addr,
+		PrivateKey: privateKey,
+	}, password, keystoreScryptN, keystoreScryptP)
+	if err != nil {
+		return common.Address{}, "", errors.Wrap(err, "encrypting the key")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return common.Address{}, "", errors.Wrap(err, "creating keystore dir")
+	}
+	name := fmt.Sprintf("UTC--%s--%x", time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"), addr[:])
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, keyJSON, 0600); err != nil {
+		return common.Address{}, "", errors.Wrap(err, "writing keystore file")
+	}
+	return addr, path, nil
+}
+
+// checkKeystorePassword decrypts an existing keystore file with the password.
+// All keystore files are decrypted with the same password so
+// a key stored with another one would make all accounts unusable.
+func checkKeystorePassword(dir string, password string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "reading keystore dir")
+	}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		keyJSON, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return errors.Wrapf(err, "reading keystore file:%v", file.Name())
+		}
+		if _, err := keystore.DecryptKey(keyJSON, password); err != nil {
+			return errors.Wrapf(err, "the password doesn't match the existing keystore file:%v", file.Name())
+		}
+		return nil
+	}
+	return nil
+}
+
+// keystoreFile returns the path of the keystore file with the given address.
+// The address is read from the files so these can have any name.
+func keystoreFile(dir string, addr common.Address) (string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", errors.Wrap(err, "reading keystore dir")
+	}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		keyJSON, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "reading keystore file:%v", file.Name())
+		}
+		var key struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(keyJSON, &key); err != nil {
+			return "", errors.Wrapf(err, "parsing keystore file:%v", file.Name())
+		}
+		if common.HexToAddress(key.Address) == addr {
+			return path, nil
+		}
+	}
+	return "", errors.Errorf("account not found in the keystore:%v", addr.Hex())
+}
+
+// newKeystorePassword reads the password for a new keystore file.
+// All keystore files are decrypted with the same password
+// so it is the one from the password file when set and otherwise it is prompted twice.
+func newKeystorePassword() (string, error) {
+	if os.Getenv(KeystorePasswordFileEnvName) != "" {
+		return keystorePassword()
+	}
+	password, err := keystorePassword()
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Repeat keystore password: ")
+	repeated, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", errors.Wrap(err, "reading keystore password")
+	}
+	if password != string(repeated) {
+		return "", errors.New("the passwords don't match")
+	}
+	return password, nil
+}
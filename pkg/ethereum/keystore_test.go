@@ -0,0 +1,63 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestKeystoreManagement(t *testing.T) {
+	keystoreScryptN, keystoreScryptP = keystore.LightScryptN, keystore.LightScryptP
+	defer func() {
+		keystoreScryptN, keystoreScryptP = keystore.StandardScryptN, keystore.StandardScryptP
+	}()
+
+	dir := filepath.Join(t.TempDir(), "keystore")
+	passFile := filepath.Join(t.TempDir(), ".password")
+	testutil.Ok(t, ioutil.WriteFile(passFile, []byte("pass\n"), 0600))
+	testutil.Ok(t, os.Setenv(KeystorePasswordFileEnvName, passFile))
+	defer os.Unsetenv(KeystorePasswordFileEnvName)
+
+	created, _, err := NewKeystoreAccount(dir)
+	testutil.Ok(t, err)
+
+	privateKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	keyHex := hex.EncodeToString(crypto.FromECDSA(privateKey))
+	imported, _, err := ImportKeystoreAccount(dir, "0x"+keyHex)
+	testutil.Ok(t, err)
+	testutil.Equals(t, crypto.PubkeyToAddress(privateKey.PublicKey), imported)
+
+	// The same key can't be imported twice.
+	_, _, err = ImportKeystoreAccount(dir, keyHex)
+	testutil.NotOk(t, err)
+
+	// A key with another password would break the decryption of all accounts.
+	testutil.Ok(t, ioutil.WriteFile(passFile, []byte("other\n"), 0600))
+	_, _, err = NewKeystoreAccount(dir)
+	testutil.NotOk(t, err)
+	testutil.Ok(t, ioutil.WriteFile(passFile, []byte("pass\n"), 0600))
+
+	accounts, err := keystoreAccounts(dir)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(accounts))
+
+	exported, err := ExportKeystoreAccount(dir, imported)
+	testutil.Ok(t, err)
+	testutil.Equals(t, keyHex, exported)
+
+	exported, err = ExportKeystoreAccount(dir, created)
+	testutil.Ok(t, err)
+	account, err := privateKeyAccount(exported)
+	testutil.Ok(t, err)
+	testutil.Equals(t, created, account.Address)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// AccountsConfig labels the accounts so that a subset of them can be selected by label.
+type AccountsConfig struct {
+	Labels []AccountLabels `help:"Labels of the accounts by address."`
+	// Mine are the labels of the accounts used by the mine command.
+	Mine []string `help:"Labels of the accounts used by the mine command. All accounts are used when empty."`
+}
+
+type AccountLabels struct {
+	Address string
+	Labels  []string
+}
+
+// AccountLabels returns the labels of the address.
+func (self AccountsConfig) AccountLabels(addr common.Address) []string {
+	var labels []string
+	for _, l := range self.Labels {
+		if common.HexToAddress(l.Address) == addr {
+			labels = append(labels, l.Labels...)
+		}
+	}
+	return labels
+}
+
+// Select returns the accounts with at least one of the labels.
+// All accounts are returned when there are no labels.
+func (self AccountsConfig) Select(accounts []*Account, labels []string) ([]*Account, error) {
+	if len(labels) == 0 {
+		return accounts, nil
+	}
+	var selected []*Account
+	for _, account := range accounts {
+		if hasLabel(self.AccountLabels(account.Address), labels) {
+			selected = append(selected, account)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errors.Errorf("no accounts with the labels:%v", labels)
+	}
+	return selected, nil
+}
+
+func hasLabel(accountLabels []string, labels []string) bool {
+	for _, a := range accountLabels {
+		for _, l := range labels {
+			if a == l {
+				return true
+			}
+		}
+	}
+	return false
+}
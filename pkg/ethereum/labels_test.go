@@ -0,0 +1,41 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestSelectAccounts(t *testing.T) {
+	accounts := []*Account{
+		{Address: common.HexToAddress("0x1")},
+		{Address: common.HexToAddress("0x2")},
+		{Address: common.HexToAddress("0x3")},
+	}
+	cfg := AccountsConfig{
+		Labels: []AccountLabels{
+			{Address: "0x0000000000000000000000000000000000000001", Labels: []string{"miner", "rig1"}},
+			{Address: "0x0000000000000000000000000000000000000002", Labels: []string{"miner"}},
+			{Address: "0x0000000000000000000000000000000000000003", Labels: []string{"cold"}},
+		},
+	}
+
+	selected, err := cfg.Select(accounts, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, accounts, selected)
+
+	selected, err = cfg.Select(accounts, []string{"miner"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, accounts[:2], selected)
+
+	selected, err = cfg.Select(accounts, []string{"rig1", "cold"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []*Account{accounts[0], accounts[2]}, selected)
+
+	_, err = cfg.Select(accounts, []string{"unknown"})
+	testutil.NotOk(t, err)
+}
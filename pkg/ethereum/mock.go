@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -534,6 +535,44 @@ func (c *mockClient) BlockNumber(ctx context.Context) (uint64, error) {
 	return 0, nil
 }
 
+// MockSubscription is a subscription that fails on demand.
+type MockSubscription struct {
+	mtx    sync.Mutex
+	err    chan error
+	closed bool
+}
+
+// NewMockSubscription returns a subscription that fails only when Fail is called.
+func NewMockSubscription() *MockSubscription {
+	return &MockSubscription{err: make(chan error, 1)}
+}
+
+// Fail sends the error to the subscriber unless the subscription is already closed.
+func (self *MockSubscription) Fail(err error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.closed {
+		return
+	}
+	select {
+	case self.err <- err:
+	default:
+	}
+}
+
+func (self *MockSubscription) Unsubscribe() {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if !self.closed {
+		self.closed = true
+		close(self.err)
+	}
+}
+
+func (self *MockSubscription) Err() <-chan error {
+	return self.err
+}
+
 // ABICodec holds abi definitions for encoding/decoding contract methods and events.
 type ABICodec struct {
 	abiStruct abi.ABI
@@ -0,0 +1,86 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// UnsignedTx is a transaction built on a host without the private key.
+// It has everything needed to sign it on another host without a connection to the node.
+type UnsignedTx struct {
+	ChainID  *hexutil.Big    `json:"chainId"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     hexutil.Bytes   `json:"data"`
+}
+
+func NewUnsignedTx(tx *types.Transaction, chainID *big.Int, from common.Address) *UnsignedTx {
+	return &UnsignedTx{
+		ChainID:  (*hexutil.Big)(chainID),
+		From:     from,
+		To:       tx.To(),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Value:    (*hexutil.Big)(tx.Value()),
+		Data:     tx.Data(),
+	}
+}
+
+// ReadUnsignedTx reads an unsigned transaction from a JSON file.
+func ReadUnsignedTx(path string) (*UnsignedTx, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the transaction file")
+	}
+	tx := &UnsignedTx{}
+	if err := json.Unmarshal(data, tx); err != nil {
+		return nil, errors.Wrapf(err, "parsing the transaction file:%v", path)
+	}
+	if tx.ChainID == nil || tx.GasPrice == nil {
+		return nil, errors.Errorf("transaction file without a chain ID or gas price:%v", path)
+	}
+	return tx, nil
+}
+
+// Write saves the transaction as a JSON file.
+func (self *UnsignedTx) Write(path string) error {
+	data, err := json.MarshalIndent(self, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "encoding the transaction")
+	}
+	return errors.Wrap(ioutil.WriteFile(path, data, 0600), "writing the transaction file")
+}
+
+// Sign signs the transaction with the given account which must be the sender of the transaction.
+func (self *UnsignedTx) Sign(account *Account) (*types.Transaction, error) {
+	if account.Address != self.From {
+		return nil, errors.Errorf("transaction sender:%v doesn't match the account:%v", self.From.String(), account.Address.String())
+	}
+	value := big.NewInt(0)
+	if self.Value != nil {
+		value = self.Value.ToInt()
+	}
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    uint64(self.Nonce),
+		GasPrice: self.GasPrice.ToInt(),
+		Gas:      uint64(self.Gas),
+		To:       self.To,
+		Value:    value,
+		Data:     self.Data,
+	})
+	return account.Signer.SignTx(tx, self.ChainID.ToInt())
+}
@@ -0,0 +1,48 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestUnsignedTxSign(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	account := &Account{Address: crypto.PubkeyToAddress(privateKey.PublicKey), Signer: NewKeySigner(privateKey)}
+
+	chainID := big.NewInt(4)
+	to := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	tx := types.NewTransaction(7, to, big.NewInt(0), 3_000_000, big.NewInt(2e9), []byte{1, 2, 3})
+
+	path := filepath.Join(t.TempDir(), "tx.json")
+	testutil.Ok(t, NewUnsignedTx(tx, chainID, account.Address).Write(path))
+	unsigned, err := ReadUnsignedTx(path)
+	testutil.Ok(t, err)
+
+	signed, err := unsigned.Sign(account)
+	testutil.Ok(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	testutil.Ok(t, err)
+	testutil.Equals(t, account.Address, sender)
+	testutil.Equals(t, uint64(7), signed.Nonce())
+	testutil.Equals(t, uint64(3_000_000), signed.Gas())
+	testutil.Equals(t, big.NewInt(2e9), signed.GasPrice())
+	testutil.Equals(t, &to, signed.To())
+	testutil.Equals(t, []byte{1, 2, 3}, signed.Data())
+	testutil.Equals(t, chainID, signed.ChainId())
+
+	// Only the sender can sign the transaction.
+	otherKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	_, err = unsigned.Sign(&Account{Address: crypto.PubkeyToAddress(otherKey.PublicKey), Signer: NewKeySigner(otherKey)})
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,814 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// HealthCheckInterval is how often the pool checks the head and latency of all nodes.
+	HealthCheckInterval = 10 * time.Second
+	// MaxLagBlocks is how many blocks a node can be behind the highest head
+	// before it is considered lagging and no longer used.
+	MaxLagBlocks = 3
+
+	healthCheckTimeout = 5 * time.Second
+	// logDedupeDepth is how many blocks to remember delivered logs for
+	// to avoid duplicates after a subscription failover.
+	logDedupeDepth = 128
+)
+
+// EthClient is the ethereum client interface used by all components.
+// It is implemented by the ClientPool and by *ethclient.Client.
+type EthClient interface {
+	bind.ContractBackend
+	ethereum.ChainReader
+	ethereum.ChainStateReader
+	ethereum.TransactionReader
+	ethereum.ChainSyncReader
+	NetworkID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+var (
+	nodeHead = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "node_head",
+		Help:      "The latest block number reported by the node",
+	}, []string{"host"})
+	nodeLatency = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "node_latency_seconds",
+		Help:      "The latency of the last health check request to the node",
+	}, []string{"host"})
+	nodeLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "node_lag_blocks",
+		Help:      "How many blocks the node is behind the highest head of all nodes",
+	}, []string{"host"})
+	nodeUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "node_up",
+		Help:      "Whether the node is healthy and not lagging so it is used for routing calls",
+	}, []string{"host"})
+	nodeErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "node_errors_total",
+		Help:      "The total number of transport errors from the node",
+	}, []string{"host"})
+	nodeFailovers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "node_failovers_total",
+		Help:      "The total number of calls and subscriptions moved away from the node",
+	}, []string{"host"})
+)
+
+type poolNode struct {
+	url     string
+	host    string
+	client  *ethclient.Client
+	head    uint64
+	latency time.Duration
+	healthy bool
+	lagging bool
+}
+
+// ClientPool routes calls to the healthiest of multiple nodes.
+// It tracks the head and latency of every node and
+// a node that errors or falls behind the others is skipped
+// until it recovers. Subscriptions are moved to another node transparently.
+type ClientPool struct {
+	ctx    context.Context
+	close  context.CancelFunc
+	logger log.Logger
+	mtx    sync.Mutex
+	nodes  []*poolNode
+}
+
+// NewClientPool dials all the given node URLs and starts the health checks.
+// Nodes that can't be dialed are retried by the health checks
+// so it is enough for one node to be reachable at startup.
+func NewClientPool(ctx context.Context, logger log.Logger, nodeURLs []string) (*ClientPool, error) {
+	if len(nodeURLs) == 0 {
+		return nil, errors.New("no node URLs")
+	}
+	ctx, close := context.WithCancel(ctx)
+	self := &ClientPool{
+		ctx:    ctx,
+		close:  close,
+		logger: log.With(logger, "component", ComponentName),
+	}
+	for _, nodeURL := range nodeURLs {
+		nodeURL = strings.TrimSpace(nodeURL)
+		host := nodeURL
+		if u, err := url.Parse(nodeURL); err == nil && u.Host != "" {
+			host = u.Host // Don't expose the full URL as it often contains API keys.
+		}
+		self.nodes = append(self.nodes, &poolNode{url: nodeURL, host: host})
+	}
+
+	self.healthCheck()
+
+	var netID *big.Int
+	for _, node := range self.nodes {
+		if !node.healthy {
+			continue
+		}
+		id, err := node.client.NetworkID(ctx)
+		if err != nil {
+			level.Warn(self.logger).Log("msg", "get network ID", "host", node.host, "err", err)
+			continue
+		}
+		if netID != nil && netID.Cmp(id) != 0 {
+			self.Close()
+			return nil, errors.Errorf("nodes are on different networks:%v, %v", netID, id)
+		}
+		netID = id
+	}
+	if netID == nil {
+		self.Close()
+		return nil, errors.New("none of the nodes are available")
+	}
+
+	go self.healthLoop()
+	return self, nil
+}
+
+// Close stops the health checks and closes all node connections.
+func (self *ClientPool) Close() {
+	self.close()
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for _, node := range self.nodes {
+		if node.client != nil {
+			node.client.Close()
+		}
+	}
+}
+
+func (self *ClientPool) healthLoop() {
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-self.ctx.Done():
+			return
+		case <-ticker.C:
+			self.healthCheck()
+		}
+	}
+}
+
+// healthCheck queries the head of all nodes in parallel and
+// marks the ones that failed or are behind the highest head.
+func (self *ClientPool) healthCheck() {
+	self.mtx.Lock()
+	nodes := make([]poolNode, len(self.nodes))
+	for i, node := range self.nodes {
+		nodes[i] = *node
+	}
+	self.mtx.Unlock()
+
+	var wg sync.WaitGroup
+	for i := range nodes {
+		wg.Add(1)
+		go func(node *poolNode) {
+			defer wg.Done()
+			ctx, cncl := context.WithTimeout(self.ctx, healthCheckTimeout)
+			defer cncl()
+			if node.client == nil {
+				client, err := self.dial(ctx, node.url)
+				if err != nil {
+					level.Warn(self.logger).Log("msg", "dial node", "host", node.host, "err", err)
+					node.healthy = false
+					return
+				}
+				node.client = client
+			}
+			start := time.Now()
+			head, err := node.client.HeaderByNumber(ctx, nil)
+			if err != nil {
+				level.Warn(self.logger).Log("msg", "node health check", "host", node.host, "err", err)
+				nodeErrors.With(prometheus.Labels{"host": node.host}).Inc()
+				node.healthy = false
+				return
+			}
+			node.latency = time.Since(start)
+			node.head = head.Number.Uint64()
+			node.healthy = true
+		}(&nodes[i])
+	}
+	wg.Wait()
+
+	var maxHead uint64
+	for _, node := range nodes {
+		if node.healthy && node.head > maxHead {
+			maxHead = node.head
+		}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for i, node := range self.nodes {
+		checked := nodes[i]
+		if node.client == nil {
+			node.client = checked.client
+		}
+		node.head, node.latency, node.healthy = checked.head, checked.latency, checked.healthy
+		node.lagging = node.healthy && node.head+MaxLagBlocks < maxHead
+		if node.lagging {
+			level.Warn(self.logger).Log("msg", "node is lagging", "host", node.host, "head", node.head, "maxHead", maxHead)
+		}
+
+		labels := prometheus.Labels{"host": node.host}
+		nodeHead.With(labels).Set(float64(node.head))
+		nodeLatency.With(labels).Set(node.latency.Seconds())
+		if node.healthy {
+			nodeLag.With(labels).Set(float64(maxHead - node.head))
+		}
+		if self.usable(node) {
+			nodeUp.With(labels).Set(1)
+		} else {
+			nodeUp.With(labels).Set(0)
+		}
+	}
+}
+
+func (self *ClientPool) dial(ctx context.Context, nodeURL string) (*ethclient.Client, error) {
+	client, err := ethclient.DialContext(ctx, nodeURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "create rpc client instance")
+	}
+	if !strings.Contains(strings.ToLower(nodeURL), "arbitrum") { // Arbitrum nodes doesn't support sync checking.
+		// Issue #55, halt if client is still syncing with Ethereum network
+		s, err := client.SyncProgress(ctx)
+		if err != nil {
+			client.Close()
+			return nil, errors.Wrap(err, "determining if Ethereum client is syncing")
+		}
+		if s != nil {
+			client.Close()
+			return nil, errors.New("ethereum node is still syncing with the network")
+		}
+	}
+	return client, nil
+}
+
+// usable should be called with the lock held.
+func (self *ClientPool) usable(node *poolNode) bool {
+	return node.client != nil && node.healthy && !node.lagging
+}
+
+// hasBetter returns true when the node is not usable, but another node is.
+func (self *ClientPool) hasBetter(node *poolNode) bool {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.usable(node) {
+		return false
+	}
+	for _, n := range self.nodes {
+		if self.usable(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// ordered returns the nodes in the order these should be tried -
+// usable nodes by lowest latency and then the rest by highest head.
+func (self *ClientPool) ordered() []*poolNode {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	var usable, rest []*poolNode
+	for _, node := range self.nodes {
+		if self.usable(node) {
+			usable = append(usable, node)
+		} else if node.client != nil {
+			rest = append(rest, node)
+		}
+	}
+	sort.SliceStable(usable, func(i, j int) bool { return usable[i].latency < usable[j].latency })
+	sort.SliceStable(rest, func(i, j int) bool { return rest[i].head > rest[j].head })
+	return append(usable, rest...)
+}
+
+func (self *ClientPool) markFailed(node *poolNode, err error) {
+	level.Warn(self.logger).Log("msg", "node request failed so failing over", "host", node.host, "err", err)
+	nodeErrors.With(prometheus.Labels{"host": node.host}).Inc()
+	nodeFailovers.With(prometheus.Labels{"host": node.host}).Inc()
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	node.healthy = false
+	nodeUp.With(prometheus.Labels{"host": node.host}).Set(0)
+}
+
+// isTransportErr returns true for errors caused by the node being unavailable.
+// Errors returned by the node itself like reverts or not found are returned as is.
+func isTransportErr(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || err == ethereum.NotFound {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// do runs the call against the best node and fails over to the next one on transport errors.
+func (self *ClientPool) do(ctx context.Context, call func(*ethclient.Client) error) error {
+	nodes := self.ordered()
+	if len(nodes) == 0 {
+		return errors.New("none of the nodes are available")
+	}
+	var err error
+	for _, node := range nodes {
+		err = call(node.client)
+		if !isTransportErr(ctx, err) {
+			return err
+		}
+		self.markFailed(node, err)
+	}
+	return err
+}
+
+func (self *ClientPool) NetworkID(ctx context.Context) (id *big.Int, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		id, err = c.NetworkID(ctx)
+		return err
+	})
+	return id, err
+}
+
+func (self *ClientPool) BlockNumber(ctx context.Context) (n uint64, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		n, err = c.BlockNumber(ctx)
+		return err
+	})
+	return n, err
+}
+
+func (self *ClientPool) SyncProgress(ctx context.Context) (p *ethereum.SyncProgress, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		p, err = c.SyncProgress(ctx)
+		return err
+	})
+	return p, err
+}
+
+func (self *ClientPool) BlockByHash(ctx context.Context, hash common.Hash) (b *types.Block, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		b, err = c.BlockByHash(ctx, hash)
+		return err
+	})
+	return b, err
+}
+
+func (self *ClientPool) BlockByNumber(ctx context.Context, number *big.Int) (b *types.Block, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		b, err = c.BlockByNumber(ctx, number)
+		return err
+	})
+	return b, err
+}
+
+func (self *ClientPool) HeaderByHash(ctx context.Context, hash common.Hash) (h *types.Header, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		h, err = c.HeaderByHash(ctx, hash)
+		return err
+	})
+	return h, err
+}
+
+func (self *ClientPool) HeaderByNumber(ctx context.Context, number *big.Int) (h *types.Header, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		h, err = c.HeaderByNumber(ctx, number)
+		return err
+	})
+	return h, err
+}
+
+func (self *ClientPool) TransactionCount(ctx context.Context, blockHash common.Hash) (n uint, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		n, err = c.TransactionCount(ctx, blockHash)
+		return err
+	})
+	return n, err
+}
+
+func (self *ClientPool) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (tx *types.Transaction, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		tx, err = c.TransactionInBlock(ctx, blockHash, index)
+		return err
+	})
+	return tx, err
+}
+
+func (self *ClientPool) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		tx, isPending, err = c.TransactionByHash(ctx, hash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (self *ClientPool) TransactionReceipt(ctx context.Context, txHash common.Hash) (r *types.Receipt, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		r, err = c.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return r, err
+}
+
+func (self *ClientPool) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (b *big.Int, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		b, err = c.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return b, err
+}
+
+func (self *ClientPool) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) (s []byte, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		s, err = c.StorageAt(ctx, account, key, blockNumber)
+		return err
+	})
+	return s, err
+}
+
+func (self *ClientPool) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		code, err = c.CodeAt(ctx, account, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (self *ClientPool) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (n uint64, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		n, err = c.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	return n, err
+}
+
+func (self *ClientPool) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		code, err = c.PendingCodeAt(ctx, account)
+		return err
+	})
+	return code, err
+}
+
+func (self *ClientPool) PendingNonceAt(ctx context.Context, account common.Address) (n uint64, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		n, err = c.PendingNonceAt(ctx, account)
+		return err
+	})
+	return n, err
+}
+
+func (self *ClientPool) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (out []byte, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		out, err = c.CallContract(ctx, msg, blockNumber)
+		return err
+	})
+	return out, err
+}
+
+func (self *ClientPool) SuggestGasPrice(ctx context.Context) (p *big.Int, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		p, err = c.SuggestGasPrice(ctx)
+		return err
+	})
+	return p, err
+}
+
+func (self *ClientPool) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (gas uint64, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		gas, err = c.EstimateGas(ctx, msg)
+		return err
+	})
+	return gas, err
+}
+
+// SendTransaction fails over to the next node on transport errors.
+// The failed node might have still received and broadcast the transaction
+// so when the next node rejects it as already known or with a used nonce,
+// but has the same transaction it is not an error.
+func (self *ClientPool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	var failedOver bool
+	return self.do(ctx, func(c *ethclient.Client) error {
+		err := c.SendTransaction(ctx, tx)
+		if err != nil && failedOver {
+			if _, _, errKnown := c.TransactionByHash(ctx, tx.Hash()); errKnown == nil {
+				return nil
+			}
+		}
+		failedOver = true
+		return err
+	})
+}
+
+func (self *ClientPool) FilterLogs(ctx context.Context, q ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = self.do(ctx, func(c *ethclient.Client) (err error) {
+		logs, err = c.FilterLogs(ctx, q)
+		return err
+	})
+	return logs, err
+}
+
+// SubscribeNewHead subscribes to new heads on the best node and
+// moves the subscription to another node when the current one fails or lags.
+func (self *ClientPool) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	heads := make(chan *types.Header)
+	sub := newFailoverSub(ctx, self, func(c *ethclient.Client) (ethereum.Subscription, error) {
+		return c.SubscribeNewHead(ctx, heads)
+	}, nil, nil)
+	if err := sub.subscribe(); err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			select {
+			case <-sub.quit:
+				return
+			case head := <-heads:
+				select {
+				case ch <- head:
+				case <-sub.quit:
+					return
+				}
+			}
+		}
+	}()
+	go sub.loop()
+	return sub, nil
+}
+
+// SubscribeFilterLogs subscribes to logs on the best node and
+// moves the subscription to another node when the current one fails or lags.
+// After a failover the logs missed since the last delivered block or
+// since the subscription started are backfilled before the logs from the new node and
+// logs that were already delivered are skipped.
+func (self *ClientPool) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	head, err := self.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the head block")
+	}
+	var (
+		mtx sync.Mutex
+		// lastBlock is the block of the last delivered log and
+		// before the first delivery the block after the head at subscribe time.
+		lastBlock = head + 1
+		sub       *failoverSub
+		logs      = make(chan types.Log)
+		// backfills receives a channel for the missed logs on every failover and
+		// the logs from the new node are buffered until the missed logs are delivered.
+		backfills = make(chan chan []types.Log)
+		backfill  chan []types.Log
+	)
+	onFailover := func() {
+		backfill = make(chan []types.Log, 1)
+		select {
+		case backfills <- backfill:
+		case <-sub.quit:
+		}
+	}
+	onResubscribe := func(c *ethclient.Client) {
+		var missed []types.Log
+		if q.BlockHash == nil {
+			mtx.Lock()
+			from := lastBlock
+			mtx.Unlock()
+			bq := q
+			bq.FromBlock = new(big.Int).SetUint64(from)
+			bq.ToBlock = nil
+			var err error
+			missed, err = c.FilterLogs(ctx, bq)
+			if err != nil {
+				level.Error(self.logger).Log("msg", "backfilling logs after a failover", "err", err)
+			}
+		}
+		backfill <- missed
+	}
+	sub = newFailoverSub(ctx, self, func(c *ethclient.Client) (ethereum.Subscription, error) {
+		return c.SubscribeFilterLogs(ctx, q, logs)
+	}, onFailover, onResubscribe)
+	if err := sub.subscribe(); err != nil {
+		return nil, err
+	}
+	go func() {
+		type logKey struct {
+			block   common.Hash
+			index   uint
+			removed bool
+		}
+		delivered := make(map[logKey]uint64)
+		deliver := func(l types.Log) bool {
+			key := logKey{block: l.BlockHash, index: l.Index, removed: l.Removed}
+			if _, ok := delivered[key]; ok {
+				return true
+			}
+			delivered[key] = l.BlockNumber
+			mtx.Lock()
+			if l.BlockNumber > lastBlock {
+				lastBlock = l.BlockNumber
+				for k, block := range delivered {
+					if block+logDedupeDepth < lastBlock {
+						delete(delivered, k)
+					}
+				}
+			}
+			mtx.Unlock()
+			select {
+			case ch <- l:
+				return true
+			case <-sub.quit:
+				return false
+			}
+		}
+
+		var (
+			pending  chan []types.Log
+			buffered []types.Log
+		)
+		for {
+			// A failover waits until the backfill of the previous one is delivered.
+			var nextBackfill chan chan []types.Log
+			if pending == nil {
+				nextBackfill = backfills
+			}
+			select {
+			case <-sub.quit:
+				return
+			case pending = <-nextBackfill:
+			case missed := <-pending:
+				for _, l := range append(missed, buffered...) {
+					if !deliver(l) {
+						return
+					}
+				}
+				pending, buffered = nil, nil
+			case l := <-logs:
+				if pending != nil {
+					buffered = append(buffered, l)
+					continue
+				}
+				if !deliver(l) {
+					return
+				}
+			}
+		}
+	}()
+	go sub.loop()
+	return sub, nil
+}
+
+// failoverSub is a subscription that is moved
+// to another node when the current node fails or lags.
+type failoverSub struct {
+	ctx          context.Context
+	pool         *ClientPool
+	newSub       func(*ethclient.Client) (ethereum.Subscription, error)
+	onFailover   func()
+	onResubcribe func(*ethclient.Client)
+	node         *poolNode
+	inner        ethereum.Subscription
+	quit         chan struct{}
+	err          chan error
+	once         sync.Once
+}
+
+func newFailoverSub(
+	ctx context.Context,
+	pool *ClientPool,
+	newSub func(*ethclient.Client) (ethereum.Subscription, error),
+	onFailover func(),
+	onResubcribe func(*ethclient.Client),
+) *failoverSub {
+	return &failoverSub{
+		ctx:          ctx,
+		pool:         pool,
+		newSub:       newSub,
+		onFailover:   onFailover,
+		onResubcribe: onResubcribe,
+		quit:         make(chan struct{}),
+		err:          make(chan error, 1),
+	}
+}
+
+// subscribe tries all nodes in order until one succeeds.
+func (self *failoverSub) subscribe() error {
+	nodes := self.pool.ordered()
+	if len(nodes) == 0 {
+		return errors.New("none of the nodes are available")
+	}
+	var err error
+	for _, node := range nodes {
+		var sub ethereum.Subscription
+		sub, err = self.newSub(node.client)
+		if err == nil {
+			self.node, self.inner = node, sub
+			return nil
+		}
+		if !isTransportErr(self.ctx, err) {
+			return err
+		}
+		self.pool.markFailed(node, err)
+	}
+	return err
+}
+
+func (self *failoverSub) loop() {
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+	defer close(self.err)
+	// Stop the forwarding of the events whatever the reason for the exit.
+	defer self.Unsubscribe()
+	for {
+		var failed bool
+		select {
+		case <-self.quit:
+			self.inner.Unsubscribe()
+			return
+		case <-self.ctx.Done():
+			self.inner.Unsubscribe()
+			return
+		case <-self.pool.ctx.Done():
+			self.inner.Unsubscribe()
+			self.err <- errors.New("client pool closed")
+			return
+		case err := <-self.inner.Err():
+			self.pool.markFailed(self.node, errors.Wrap(err, "subscription"))
+			failed = true
+		case <-ticker.C:
+			failed = self.pool.hasBetter(self.node)
+			if failed {
+				level.Warn(self.pool.logger).Log("msg", "moving subscription from an unhealthy node", "host", self.node.host)
+				nodeFailovers.With(prometheus.Labels{"host": self.node.host}).Inc()
+				self.inner.Unsubscribe()
+			}
+		}
+		if !failed {
+			continue
+		}
+		if self.onFailover != nil {
+			self.onFailover()
+		}
+
+		// Resubscribe until it succeeds.
+		for {
+			err := self.subscribe()
+			if err == nil {
+				level.Info(self.pool.logger).Log("msg", "subscription moved", "host", self.node.host)
+				if self.onResubcribe != nil {
+					self.onResubcribe(self.node.client)
+				}
+				break
+			}
+			level.Error(self.pool.logger).Log("msg", "resubscribing", "err", err)
+			select {
+			case <-self.quit:
+				return
+			case <-self.ctx.Done():
+				return
+			case <-self.pool.ctx.Done():
+				self.err <- errors.New("client pool closed")
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+func (self *failoverSub) Unsubscribe() {
+	self.once.Do(func() { close(self.quit) })
+}
+
+func (self *failoverSub) Err() <-chan error {
+	return self.err
+}
@@ -0,0 +1,303 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// mockNode serves the few JSON-RPC methods used by the pool health checks.
+type mockNode struct {
+	head  uint64
+	delay time.Duration
+	calls int64
+}
+
+func (self *mockNode) Syncing() (interface{}, error) {
+	return false, nil
+}
+
+func (self *mockNode) BlockNumber() hexutil.Uint64 {
+	atomic.AddInt64(&self.calls, 1)
+	return hexutil.Uint64(self.head)
+}
+
+func (self *mockNode) GetBlockByNumber(ctx context.Context, number string, full bool) (*types.Header, error) {
+	time.Sleep(self.delay)
+	return &types.Header{Number: new(big.Int).SetUint64(self.head), Difficulty: big.NewInt(0)}, nil
+}
+
+type mockNet struct{}
+
+func (mockNet) Version() string {
+	return "1"
+}
+
+func newMockNode(t *testing.T, node *mockNode) *httptest.Server {
+	srv := rpc.NewServer()
+	testutil.Ok(t, srv.RegisterName("eth", node))
+	testutil.Ok(t, srv.RegisterName("net", mockNet{}))
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+	return httpSrv
+}
+
+func TestClientPoolFailover(t *testing.T) {
+	fast := &mockNode{head: 100}
+	slow := &mockNode{head: 100, delay: 50 * time.Millisecond}
+	fastSrv := newMockNode(t, fast)
+	slowSrv := newMockNode(t, slow)
+
+	pool, err := NewClientPool(context.Background(), logging.NewLogger(), []string{slowSrv.URL, fastSrv.URL})
+	testutil.Ok(t, err)
+	defer pool.Close()
+
+	ctx := context.Background()
+	_, err = pool.BlockNumber(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(1), atomic.LoadInt64(&fast.calls), "calls should be routed to the node with the lowest latency")
+	testutil.Equals(t, int64(0), atomic.LoadInt64(&slow.calls))
+
+	fastSrv.Close()
+	n, err := pool.BlockNumber(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(100), n)
+	testutil.Equals(t, int64(1), atomic.LoadInt64(&slow.calls), "calls should fail over when the node is down")
+}
+
+func TestClientPoolLagging(t *testing.T) {
+	synced := &mockNode{head: 100, delay: 50 * time.Millisecond}
+	lagging := &mockNode{head: 100 - MaxLagBlocks - 1}
+	syncedSrv := newMockNode(t, synced)
+	laggingSrv := newMockNode(t, lagging)
+
+	pool, err := NewClientPool(context.Background(), logging.NewLogger(), []string{laggingSrv.URL, syncedSrv.URL})
+	testutil.Ok(t, err)
+	defer pool.Close()
+
+	n, err := pool.BlockNumber(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(100), n, "a lagging node shouldn't be used even when it is faster")
+}
+
+func TestFailoverSubClosedWhileResubscribing(t *testing.T) {
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+	client := ethclient.NewClient(rpc.DialInProc(rpc.NewServer()))
+	pool := &ClientPool{
+		ctx:    ctx,
+		close:  cncl,
+		logger: logging.NewLogger(),
+		nodes:  []*poolNode{{url: "inproc", host: "inproc", client: client, healthy: true}},
+	}
+
+	inner := NewMockSubscription()
+	var calls int64
+	sub := newFailoverSub(context.Background(), pool, func(*ethclient.Client) (ethereum.Subscription, error) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			return inner, nil
+		}
+		return nil, errors.New("node down")
+	}, nil, nil)
+	testutil.Ok(t, sub.subscribe())
+	go sub.loop()
+
+	// Fail the subscription so that the loop keeps trying to resubscribe.
+	inner.Fail(errors.New("connection lost"))
+	for i := 0; atomic.LoadInt64(&calls) < 2; i++ {
+		testutil.Assert(t, i < 100, "the subscription should be retried")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	pool.Close()
+	select {
+	case err := <-sub.Err():
+		testutil.NotOk(t, err, "closing the pool should be reported")
+	case <-time.After(time.Second):
+		t.Fatal("the subscription should stop when the pool is closed")
+	}
+	select {
+	case _, ok := <-sub.Err():
+		testutil.Assert(t, !ok, "the error channel should be closed")
+	case <-time.After(time.Second):
+		t.Fatal("the error channel should be closed")
+	}
+	select {
+	case <-sub.quit:
+	default:
+		t.Fatal("the forwarding of the events should be stopped")
+	}
+}
+
+// mockLogNode serves the log methods used by the log subscriptions.
+type mockLogNode struct {
+	head uint64
+	// logs are returned by the backfill queries.
+	logs []types.Log
+	// live logs are sent to every new subscription.
+	live  []types.Log
+	delay time.Duration
+}
+
+func (self *mockLogNode) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(self.head)
+}
+
+func (self *mockLogNode) GetLogs(ctx context.Context, crit map[string]interface{}) ([]types.Log, error) {
+	time.Sleep(self.delay)
+	from, err := hexutil.DecodeUint64(crit["fromBlock"].(string))
+	if err != nil {
+		return nil, err
+	}
+	logs := []types.Log{}
+	for _, l := range self.logs {
+		if l.BlockNumber >= from {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (self *mockLogNode) Logs(ctx context.Context, crit map[string]interface{}) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	for _, l := range self.live {
+		if err := notifier.Notify(sub.ID, l); err != nil {
+			return nil, err
+		}
+	}
+	return sub, nil
+}
+
+func mockLog(block uint64) types.Log {
+	return types.Log{BlockNumber: block, BlockHash: common.BigToHash(new(big.Int).SetUint64(block)), Topics: []common.Hash{}}
+}
+
+func TestSubscribeFilterLogsBackfill(t *testing.T) {
+	failing := &mockLogNode{head: 10}
+	backup := &mockLogNode{
+		head:  10,
+		logs:  []types.Log{mockLog(11)},
+		live:  []types.Log{mockLog(12)},
+		delay: 50 * time.Millisecond,
+	}
+	failingSrv, backupSrv := rpc.NewServer(), rpc.NewServer()
+	testutil.Ok(t, failingSrv.RegisterName("eth", failing))
+	testutil.Ok(t, backupSrv.RegisterName("eth", backup))
+	defer backupSrv.Stop()
+
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+	pool := &ClientPool{
+		ctx:    ctx,
+		close:  cncl,
+		logger: logging.NewLogger(),
+		nodes: []*poolNode{
+			{host: "failing", client: ethclient.NewClient(rpc.DialInProc(failingSrv)), healthy: true},
+			{host: "backup", client: ethclient.NewClient(rpc.DialInProc(backupSrv)), healthy: true},
+		},
+	}
+
+	logs := make(chan types.Log)
+	sub, err := pool.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, logs)
+	testutil.Ok(t, err)
+
+	// The log of block 11 is missed before the first delivery
+	// and the log of block 12 arrives on the new node before the backfill completes.
+	failingSrv.Stop()
+	for _, block := range []uint64{11, 12} {
+		select {
+		case l := <-logs:
+			testutil.Equals(t, block, l.BlockNumber, "the missed logs should be delivered first")
+		case <-time.After(time.Second):
+			t.Fatalf("the log of block %v should be delivered", block)
+		}
+	}
+
+	sub.Unsubscribe()
+	select {
+	case _, ok := <-sub.Err():
+		testutil.Assert(t, !ok, "the error channel should be closed")
+	case <-time.After(time.Second):
+		t.Fatal("the error channel should be closed")
+	}
+}
+
+// mockTxNode accepts transactions into a mempool that is shared with the other nodes.
+type mockTxNode struct {
+	mtx     *sync.Mutex
+	mempool map[common.Hash]*types.Transaction
+}
+
+func (self *mockTxNode) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if _, ok := self.mempool[tx.Hash()]; ok {
+		return common.Hash{}, errors.New("already known")
+	}
+	self.mempool[tx.Hash()] = tx
+	return tx.Hash(), nil
+}
+
+func (self *mockTxNode) GetTransactionByHash(hash common.Hash) *types.Transaction {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.mempool[hash]
+}
+
+func TestSendTransactionFailover(t *testing.T) {
+	node := &mockTxNode{mtx: &sync.Mutex{}, mempool: make(map[common.Hash]*types.Transaction)}
+	srv := rpc.NewServer()
+	testutil.Ok(t, srv.RegisterName("eth", node))
+	// The dropping node receives the transaction, but the connection drops before the response.
+	dropping := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.ServeHTTP(httptest.NewRecorder(), r)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer dropping.Close()
+	backup := httptest.NewServer(srv)
+	defer backup.Close()
+
+	var nodes []*poolNode
+	for _, url := range []string{dropping.URL, backup.URL} {
+		client, err := ethclient.Dial(url)
+		testutil.Ok(t, err)
+		nodes = append(nodes, &poolNode{host: url, client: client, healthy: true})
+	}
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+	pool := &ClientPool{ctx: ctx, close: cncl, logger: logging.NewLogger(), nodes: nodes}
+
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	testutil.Ok(t, err)
+	testutil.Ok(t, pool.SendTransaction(context.Background(), tx))
+	testutil.Equals(t, 1, len(node.mempool))
+}
@@ -0,0 +1,211 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	KeystoreDirEnvName          = "ETH_KEYSTORE_DIR"
+	KeystorePasswordFileEnvName = "ETH_KEYSTORE_PASSWORD_FILE"
+	RemoteSignerURLEnvName      = "ETH_REMOTE_SIGNER_URL"
+)
+
+// Signer signs transactions on behalf of an account.
+// It allows keeping the private keys outside of the process memory
+// or at least encrypted at rest.
+type Signer interface {
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// KeySigner signs with a private key held in memory.
+type KeySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+func (self *KeySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), self.key)
+}
+
+// keystoreAccounts decrypts all geth keystore JSON files in a directory.
+func keystoreAccounts(dir string) ([]*Account, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading keystore dir")
+	}
+
+	var password *string
+	var accounts []*Account
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		keyJSON, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading keystore file:%v", file.Name())
+		}
+		if password == nil {
+			p, err := keystorePassword()
+			if err != nil {
+				return nil, err
+			}
+			password = &p
+		}
+		key, err := keystore.DecryptKey(keyJSON, *password)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decrypting keystore file:%v", file.Name())
+		}
+		accounts = append(accounts, &Account{Address: key.Address, Signer: NewKeySigner(key.PrivateKey)})
+	}
+	return accounts, nil
+}
+
+// keystorePassword reads the keystore password from the file set in the env
+// or prompts for it when the file is not set.
+func keystorePassword() (string, error) {
+	if path := os.Getenv(KeystorePasswordFileEnvName); path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrap(err, "reading keystore password file")
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.Errorf("keystore password file is not set with %v and stdin is not a terminal", KeystorePasswordFileEnvName)
+	}
+	fmt.Fprint(os.Stderr, "Keystore password: ")
+	b, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", errors.Wrap(err, "reading keystore password")
+	}
+	return string(b), nil
+}
+
+// RemoteSigner signs using a remote signer over JSON-RPC.
+// It supports clef(account_signTransaction) and
+// nodes with unlocked accounts(eth_signTransaction).
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+	method  string
+}
+
+func (self *RemoteSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	from := common.NewMixedcaseAddress(self.address)
+	args := remoteSignArgs{
+		From:     &from,
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: hexutil.Big(*tx.GasPrice()),
+		Value:    hexutil.Big(*tx.Value()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Data:     &data,
+		ChainID:  (*hexutil.Big)(chainID),
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+
+	ctx, cncl := context.WithTimeout(context.Background(), 2*time.Minute) // Clef might wait for a manual approval.
+	defer cncl()
+	var res struct {
+		Raw hexutil.Bytes      `json:"raw"`
+		Tx  *types.Transaction `json:"tx"`
+	}
+	if err := self.client.CallContext(ctx, &res, self.method, args); err != nil {
+		return nil, errors.Wrapf(err, "remote signer %v", self.method)
+	}
+	signed := res.Tx
+	if signed == nil {
+		signed = new(types.Transaction)
+		if err := signed.UnmarshalBinary(res.Raw); err != nil {
+			return nil, errors.Wrap(err, "decoding remote signed tx")
+		}
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, errors.Wrap(err, "recover remote signed tx sender")
+	}
+	if sender != self.address {
+		return nil, errors.Errorf("remote signer signed with a different account expected:%v, got:%v", self.address.Hex(), sender.Hex())
+	}
+	return signed, nil
+}
+
+// remoteSignArgs are the transaction arguments of the remote signers.
+// The addresses are pointers as only these are encoded as hex strings.
+type remoteSignArgs struct {
+	From     *common.MixedcaseAddress `json:"from"`
+	To       *common.MixedcaseAddress `json:"to"`
+	Gas      hexutil.Uint64           `json:"gas"`
+	GasPrice hexutil.Big              `json:"gasPrice"`
+	Value    hexutil.Big              `json:"value"`
+	Nonce    hexutil.Uint64           `json:"nonce"`
+	Data     *hexutil.Bytes           `json:"data"`
+	ChainID  *hexutil.Big             `json:"chainId,omitempty"`
+}
+
+// remoteSignerAccounts lists the accounts managed by a remote signer.
+// Clef exposes these with account_list and a node with eth_accounts.
+func remoteSignerAccounts(url string) ([]*Account, error) {
+	ctx, cncl := context.WithTimeout(context.Background(), time.Minute)
+	defer cncl()
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, errors.Wrap(err, "dial remote signer")
+	}
+
+	method := "account_signTransaction"
+	var addrs []common.Address
+	if err := client.CallContext(ctx, &addrs, "account_list"); err != nil {
+		if errC := client.CallContext(ctx, &addrs, "eth_accounts"); errC != nil {
+			return nil, errors.Wrapf(err, "listing remote signer accounts, eth_accounts fallback:%v", errC)
+		}
+		method = "eth_signTransaction"
+	}
+
+	accounts := make([]*Account, len(addrs))
+	for i, addr := range addrs {
+		accounts[i] = &Account{
+			Address: addr,
+			Signer:  &RemoteSigner{client: client, address: addr, method: method},
+		}
+	}
+	return accounts, nil
+}
+
+func privateKeyAccount(pkey string) (*Account, error) {
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(pkey), "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting private key to ECDSA")
+	}
+	return &Account{
+		Address: crypto.PubkeyToAddress(privateKey.PublicKey),
+		Signer:  NewKeySigner(privateKey),
+	}, nil
+}
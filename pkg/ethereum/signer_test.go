@@ -0,0 +1,172 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestKeystoreAccounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	testutil.Ok(t, err)
+	defer os.RemoveAll(dir)
+
+	privateKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	key := &keystore.Key{
+		Id:         uuid.New(),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+	keyJSON, err := keystore.EncryptKey(key, "pass", keystore.LightScryptN, keystore.LightScryptP)
+	testutil.Ok(t, err)
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "key.json"), keyJSON, 0600))
+
+	passFile := filepath.Join(dir, ".password")
+	testutil.Ok(t, ioutil.WriteFile(passFile, []byte("pass\n"), 0600))
+	testutil.Ok(t, os.Setenv(KeystorePasswordFileEnvName, passFile))
+	defer os.Unsetenv(KeystorePasswordFileEnvName)
+
+	accounts, err := keystoreAccounts(dir)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(accounts))
+	testutil.Equals(t, key.Address, accounts[0].Address)
+
+	chainID := big.NewInt(1)
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+	signed, err := accounts[0].NewTransactor(chainID).Signer(key.Address, tx)
+	testutil.Ok(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	testutil.Ok(t, err)
+	testutil.Equals(t, key.Address, sender)
+}
+
+// mockSigner signs the transactions with a private key.
+// The key can be of another account than the listed one to simulate a misbehaving signer.
+type mockSigner struct {
+	address common.Address
+	key     *ecdsa.PrivateKey
+	// rawOnly returns only the encoded transaction same as some nodes.
+	rawOnly bool
+}
+
+type signResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx,omitempty"`
+}
+
+func (self *mockSigner) sign(args remoteSignArgs) (*signResult, error) {
+	var to *common.Address
+	if args.To != nil {
+		addr := args.To.Address()
+		to = &addr
+	}
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    uint64(args.Nonce),
+		GasPrice: args.GasPrice.ToInt(),
+		Gas:      uint64(args.Gas),
+		To:       to,
+		Value:    args.Value.ToInt(),
+		Data:     *args.Data,
+	})
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(args.ChainID.ToInt()), self.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if self.rawOnly {
+		return &signResult{Raw: raw}, nil
+	}
+	return &signResult{Raw: raw, Tx: signed}, nil
+}
+
+// mockClef serves the account namespace of clef.
+type mockClef struct{ *mockSigner }
+
+func (self mockClef) List() []common.Address {
+	return []common.Address{self.address}
+}
+
+func (self mockClef) SignTransaction(args remoteSignArgs) (*signResult, error) {
+	return self.sign(args)
+}
+
+// mockNodeSigner serves the eth namespace of a node with an unlocked account.
+type mockNodeSigner struct{ *mockSigner }
+
+func (self mockNodeSigner) Accounts() []common.Address {
+	return []common.Address{self.address}
+}
+
+func (self mockNodeSigner) SignTransaction(args remoteSignArgs) (*signResult, error) {
+	return self.sign(args)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	otherKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	for _, tc := range []struct {
+		name      string
+		namespace string
+		service   interface{}
+		method    string
+		valid     bool
+	}{
+		{"clef", "account", mockClef{&mockSigner{address: address, key: key}}, "account_signTransaction", true},
+		{"node", "eth", mockNodeSigner{&mockSigner{address: address, key: key, rawOnly: true}}, "eth_signTransaction", true},
+		{"wrong signer", "account", mockClef{&mockSigner{address: address, key: otherKey}}, "account_signTransaction", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := rpc.NewServer()
+			testutil.Ok(t, srv.RegisterName(tc.namespace, tc.service))
+			httpSrv := httptest.NewServer(srv)
+			defer httpSrv.Close()
+
+			accounts, err := remoteSignerAccounts(httpSrv.URL)
+			testutil.Ok(t, err)
+			testutil.Equals(t, 1, len(accounts))
+			testutil.Equals(t, address, accounts[0].Address)
+			testutil.Equals(t, tc.method, accounts[0].Signer.(*RemoteSigner).method)
+
+			chainID := big.NewInt(31337)
+			to := common.HexToAddress("0x1")
+			tx := types.NewTransaction(3, to, big.NewInt(5), 21000, big.NewInt(1), []byte{1, 2})
+			signed, err := accounts[0].NewTransactor(chainID).Signer(address, tx)
+			if !tc.valid {
+				testutil.NotOk(t, err, "a transaction signed by another account should be rejected")
+				return
+			}
+			testutil.Ok(t, err)
+			sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+			testutil.Ok(t, err)
+			testutil.Equals(t, address, sender)
+			testutil.Equals(t, tx.Nonce(), signed.Nonce())
+			testutil.Equals(t, to, *signed.To())
+			testutil.Equals(t, tx.Value(), signed.Value())
+			testutil.Equals(t, tx.Data(), signed.Data())
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package events
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// chainRetention is how many blocks behind the head are tracked for reorgs.
+const chainRetention = 128
+
+// item is an event or a head waiting for its confirmation depth.
+type item struct {
+	number uint64
+	hash   common.Hash
+	key    string
+	depth  uint64
+	send   func(removed bool)
+}
+
+// chain follows the canonical chain by the parent hashes of the new heads.
+type chain struct {
+	client  Backend
+	hashes  map[uint64]common.Hash
+	headNum uint64
+	tail    uint64
+}
+
+func newChain(client Backend) *chain {
+	return &chain{
+		client: client,
+		hashes: make(map[uint64]common.Hash),
+	}
+}
+
+func (self *chain) head() uint64 {
+	return self.headNum
+}
+
+func (self *chain) known(number uint64) (common.Hash, bool) {
+	hash, ok := self.hashes[number]
+	return hash, ok
+}
+
+// canonical returns the hash of the canonical block at the given height.
+// The blocks older than the tracked ones are looked up in the fetched hashes
+// and it returns false when these don't have it.
+func (self *chain) canonical(number uint64, fetched map[uint64]common.Hash) (common.Hash, bool) {
+	if hash, ok := self.hashes[number]; ok {
+		return hash, true
+	}
+	if number > self.headNum {
+		return common.Hash{}, true
+	}
+	hash, ok := fetched[number]
+	return hash, ok
+}
+
+// copy returns a chain with the same tracked blocks
+// so that the parents of a new head can be fetched without holding the hub lock.
+func (self *chain) copy() *chain {
+	c := &chain{
+		client:  self.client,
+		hashes:  make(map[uint64]common.Hash, len(self.hashes)),
+		headNum: self.headNum,
+		tail:    self.tail,
+	}
+	for num, hash := range self.hashes {
+		c.hashes[num] = hash
+	}
+	return c
+}
+
+// parents fetches the headers of the ancestors of the new head that are not tracked yet
+// starting from its parent and stopping at a known block or the tail.
+// On an error it returns the headers fetched so far.
+func (self *chain) parents(ctx context.Context, header *types.Header) ([]*types.Header, error) {
+	n := header.Number.Uint64()
+	if len(self.hashes) == 0 {
+		return nil, nil
+	}
+	if known, ok := self.hashes[n]; ok && known == header.Hash() {
+		return nil, nil
+	}
+	var parents []*types.Header
+	parent := header.ParentHash
+	for num := n; num > self.tail; {
+		num--
+		if known, ok := self.hashes[num]; ok && known == parent {
+			break
+		}
+		parentHeader, err := self.client.HeaderByHash(ctx, parent)
+		if err != nil {
+			return parents, errors.Wrapf(err, "getting parent header:%v", parent)
+		}
+		parents = append(parents, parentHeader)
+		parent = parentHeader.ParentHash
+	}
+	return parents, nil
+}
+
+// add sets the new head and walks back its ancestors
+// until reaching a known block using the parents fetched for it.
+// Ancestors that replace
+// already known blocks mean that there was a reorg and
+// in this case it returns the number of the common ancestor.
+func (self *chain) add(header *types.Header, parents []*types.Header) (reorged bool, ancestor uint64, err error) {
+	n := header.Number.Uint64()
+	hash := header.Hash()
+	if len(self.hashes) == 0 {
+		self.hashes[n] = hash
+		self.headNum, self.tail = n, n
+		return false, 0, nil
+	}
+	if known, ok := self.hashes[n]; ok && known == hash {
+		return false, 0, nil
+	}
+
+	lowestChanged := uint64(0)
+	changed := func(num uint64) {
+		if !reorged || num < lowestChanged {
+			lowestChanged = num
+		}
+		reorged = true
+	}
+
+	// A reorg to a shorter chain drops the blocks above the new head.
+	for num := n + 1; num <= self.headNum; num++ {
+		if _, ok := self.hashes[num]; ok {
+			delete(self.hashes, num)
+			changed(num)
+		}
+	}
+	if known, ok := self.hashes[n]; ok && known != hash {
+		changed(n)
+	}
+	self.hashes[n] = hash
+
+	parent := header.ParentHash
+	for i, num := 0, n; num > self.tail; i++ {
+		num--
+		known, ok := self.hashes[num]
+		if ok && known == parent {
+			break
+		}
+		if i >= len(parents) {
+			return reorged, lowestChanged - 1, errors.Errorf("missing parent header:%v", parent)
+		}
+		if ok {
+			changed(num)
+		}
+		self.hashes[num] = parent
+		parent = parents[i].ParentHash
+	}
+
+	self.headNum = n
+	if n > chainRetention && n-chainRetention > self.tail {
+		for num := self.tail; num < n-chainRetention; num++ {
+			delete(self.hashes, num)
+		}
+		self.tail = n - chainRetention
+	}
+	if reorged {
+		return true, lowestChanged - 1, nil
+	}
+	return false, 0, nil
+}
@@ -0,0 +1,639 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+const ComponentName = "eventHub"
+
+const (
+	resubscribeDelay = 5 * time.Second
+	// consumerBuffer allows consumers to do some slow processing
+	// without blocking the delivery to the other consumers.
+	consumerBuffer  = 100
+	dedupeCacheSize = 1000
+)
+
+type Config struct {
+	LogLevel string
+}
+
+// Backend is the part of the ethereum client used by the hub.
+type Backend interface {
+	ethereum.LogFilterer
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// Head is a new chain head.
+// Removed is set when a head that was already delivered is no longer canonical.
+type Head struct {
+	*types.Header
+	Removed bool
+}
+
+// consumer receives the logs with a given topic after the required confirmation depth.
+type consumer struct {
+	topic   common.Hash
+	depth   uint64
+	deliver func(types.Log) error
+}
+
+// Hub holds a single log subscription per contract and
+// fans out the parsed events to all registered consumers.
+// It tracks the last processed block for every contract and
+// after a reconnect backfills the logs missed while the subscription was down.
+// All consumers should register before the hub is started
+// otherwise they will miss the events emitted before registering.
+//
+// Every consumer states the confirmation depth it needs and
+// receives the events only after the block has this many confirmations.
+// The hub tracks the recent canonical chain by following the parent hashes of the new heads
+// and when an already delivered event is no longer in the canonical chain it is sent again with Raw.Removed set.
+// Events that are reorged out before reaching the depth are never delivered.
+// Depth 0 delivers the events as soon as these are received
+// together with the removed events as reported by the node.
+type Hub struct {
+	ctx       context.Context
+	close     context.CancelFunc
+	logger    log.Logger
+	client    Backend
+	contracts []common.Address
+
+	abiTellor     abi.ABI
+	abiITellor    abi.ABI
+	filterTellor  *tellor.TellorFilterer
+	filterITellor *tellor.ITellorFilterer
+	delivered     gcache.Cache
+
+	consumersMtx sync.RWMutex
+	consumers    []consumer
+	heads        []chan *Head
+	headsDepth   []uint64
+
+	// sendMtx keeps the order of the released events.
+	sendMtx   sync.Mutex
+	mtx       sync.Mutex
+	lastBlock map[common.Address]uint64
+	chain     *chain
+	pending   []*item
+	confirmed []*item
+}
+
+func New(
+	logger log.Logger,
+	ctx context.Context,
+	cfg Config,
+	client Backend,
+	contracts ...common.Address,
+) (*Hub, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	abiTellor, err := abi.JSON(strings.NewReader(tellor.TellorABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "abi read")
+	}
+	abiITellor, err := abi.JSON(strings.NewReader(tellor.ITellorABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "abi read")
+	}
+	// The address is used only when watching so the parsing works for logs from any contract.
+	filterTellor, err := tellor.NewTellorFilterer(common.Address{}, client)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting filter instance")
+	}
+	filterITellor, err := tellor.NewITellorFilterer(common.Address{}, client)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting filter instance")
+	}
+
+	ctx, close := context.WithCancel(ctx)
+	return &Hub{
+		ctx:           ctx,
+		close:         close,
+		logger:        log.With(logger, "component", ComponentName),
+		client:        client,
+		contracts:     contracts,
+		abiTellor:     abiTellor,
+		abiITellor:    abiITellor,
+		filterTellor:  filterTellor,
+		filterITellor: filterITellor,
+		delivered:     gcache.New(dedupeCacheSize).LRU().Build(),
+		lastBlock:     make(map[common.Address]uint64),
+		chain:         newChain(client),
+	}, nil
+}
+
+func (self *Hub) register(topic common.Hash, depth uint64, deliver func(types.Log) error) {
+	self.consumersMtx.Lock()
+	defer self.consumersMtx.Unlock()
+	self.consumers = append(self.consumers, consumer{topic: topic, depth: depth, deliver: deliver})
+}
+
+// NewChallenge returns a channel that receives all NewChallenge events
+// after the given confirmation depth.
+func (self *Hub) NewChallenge(depth uint64) <-chan *tellor.ITellorNewChallenge {
+	ch := make(chan *tellor.ITellorNewChallenge, consumerBuffer)
+	self.register(self.abiITellor.Events["NewChallenge"].ID, depth, func(l types.Log) error {
+		event, err := self.filterITellor.ParseNewChallenge(l)
+		if err != nil {
+			return errors.Wrap(err, "parsing NewChallenge")
+		}
+		select {
+		case ch <- event:
+		case <-self.ctx.Done():
+		}
+		return nil
+	})
+	return ch
+}
+
+// NonceSubmitted returns a channel that receives all NonceSubmitted events
+// after the given confirmation depth.
+func (self *Hub) NonceSubmitted(depth uint64) <-chan *tellor.TellorNonceSubmitted {
+	ch := make(chan *tellor.TellorNonceSubmitted, consumerBuffer)
+	self.register(self.abiTellor.Events["NonceSubmitted"].ID, depth, func(l types.Log) error {
+		event, err := self.filterTellor.ParseNonceSubmitted(l)
+		if err != nil {
+			return errors.Wrap(err, "parsing NonceSubmitted")
+		}
+		select {
+		case ch <- event:
+		case <-self.ctx.Done():
+		}
+		return nil
+	})
+	return ch
+}
+
+// Transferred returns a channel that receives all token Transferred events
+// after the given confirmation depth.
+func (self *Hub) Transferred(depth uint64) <-chan *tellor.TellorTransferred {
+	ch := make(chan *tellor.TellorTransferred, consumerBuffer)
+	self.register(self.abiTellor.Events["Transfer"].ID, depth, func(l types.Log) error {
+		event, err := self.filterTellor.ParseTransferred(l)
+		if err != nil {
+			return errors.Wrap(err, "parsing Transferred")
+		}
+		select {
+		case ch <- event:
+		case <-self.ctx.Done():
+		}
+		return nil
+	})
+	return ch
+}
+
+// NewDispute returns a channel that receives all NewDispute events
+// after the given confirmation depth.
+func (self *Hub) NewDispute(depth uint64) <-chan *tellor.ITellorNewDispute {
+	ch := make(chan *tellor.ITellorNewDispute, consumerBuffer)
+	self.register(self.abiITellor.Events["NewDispute"].ID, depth, func(l types.Log) error {
+		event, err := self.filterITellor.ParseNewDispute(l)
+		if err != nil {
+			return errors.Wrap(err, "parsing NewDispute")
+		}
+		select {
+		case ch <- event:
+		case <-self.ctx.Done():
+		}
+		return nil
+	})
+	return ch
+}
+
+// Heads returns a channel that receives all canonical chain heads
+// after the given confirmation depth.
+func (self *Hub) Heads(depth uint64) <-chan *Head {
+	self.consumersMtx.Lock()
+	defer self.consumersMtx.Unlock()
+	ch := make(chan *Head, consumerBuffer)
+	self.heads = append(self.heads, ch)
+	self.headsDepth = append(self.headsDepth, depth)
+	return ch
+}
+
+// LastBlock returns the last processed block for the contract.
+// After a reorg it is moved back to the common ancestor
+// so that the next backfill includes the logs from the new canonical blocks.
+func (self *Hub) LastBlock(contract common.Address) uint64 {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.lastBlock[contract]
+}
+
+func (self *Hub) Start() error {
+	level.Info(self.logger).Log("msg", "starting", "contracts", fmt.Sprintf("%v", self.contracts))
+	var wg sync.WaitGroup
+	for _, contract := range self.contracts {
+		wg.Add(1)
+		go func(contract common.Address) {
+			defer wg.Done()
+			self.watchLogs(contract)
+		}(contract)
+	}
+
+	self.consumersMtx.RLock()
+	watchHeads := len(self.heads) > 0
+	for _, c := range self.consumers {
+		watchHeads = watchHeads || c.depth > 0
+	}
+	self.consumersMtx.RUnlock()
+	if watchHeads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			self.watchHeads()
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (self *Hub) Stop() {
+	self.close()
+}
+
+// watchLogs keeps a subscription for all logs of the contract and
+// resubscribes on errors until the hub is stopped.
+func (self *Hub) watchLogs(contract common.Address) {
+	logger := log.With(self.logger, "contract", contract.Hex())
+	logs := make(chan types.Log)
+	var sub ethereum.Subscription
+	for {
+		var err error
+		sub, err = self.subscribeLogs(contract, logs)
+		if err == nil {
+			break
+		}
+		level.Error(logger).Log("msg", "subscribing to logs", "err", err)
+		select {
+		case <-self.ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+	defer func() { sub.Unsubscribe() }()
+
+	for {
+		select {
+		case <-self.ctx.Done():
+			return
+		case err := <-sub.Err():
+			level.Error(logger).Log("msg", "subscription error", "err", err)
+			for {
+				sub, err = self.subscribeLogs(contract, logs)
+				if err == nil {
+					break
+				}
+				level.Error(logger).Log("msg", "re-subscribing to logs", "err", err)
+				select {
+				case <-self.ctx.Done():
+					return
+				case <-time.After(resubscribeDelay):
+				}
+			}
+			level.Info(logger).Log("msg", "re-subscribed to logs")
+			self.backfill(logger, contract)
+		case l := <-logs:
+			self.process(logger, contract, l)
+		}
+	}
+}
+
+// subscribeLogs subscribes to all logs of the contract and
+// on the first subscription records the current block as the last processed one.
+func (self *Hub) subscribeLogs(contract common.Address, logs chan types.Log) (ethereum.Subscription, error) {
+	sub, err := self.client.SubscribeFilterLogs(self.ctx, ethereum.FilterQuery{Addresses: []common.Address{contract}}, logs)
+	if err != nil {
+		return nil, err
+	}
+	if self.LastBlock(contract) == 0 {
+		block, err := self.client.BlockNumber(self.ctx)
+		if err != nil {
+			sub.Unsubscribe()
+			return nil, errors.Wrap(err, "getting current block number")
+		}
+		self.setLastBlock(contract, block)
+	}
+	return sub, nil
+}
+
+// backfill delivers the logs emitted since the last processed block.
+// The logs that were already delivered are skipped.
+func (self *Hub) backfill(logger log.Logger, contract common.Address) {
+	from := self.LastBlock(contract)
+	logs, err := self.client.FilterLogs(self.ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{contract},
+		FromBlock: new(big.Int).SetUint64(from),
+	})
+	if err != nil {
+		level.Error(logger).Log("msg", "backfilling missed logs", "from", from, "err", err)
+		return
+	}
+	level.Info(logger).Log("msg", "backfilling missed logs", "from", from, "count", len(logs))
+	for _, l := range logs {
+		self.process(logger, contract, l)
+	}
+}
+
+func (self *Hub) setLastBlock(contract common.Address, block uint64) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if block > self.lastBlock[contract] {
+		self.lastBlock[contract] = block
+	}
+}
+
+func logKey(l types.Log) string {
+	return fmt.Sprintf("%v:%v", l.BlockHash.Hex(), l.Index)
+}
+
+func (self *Hub) process(logger log.Logger, contract common.Address, l types.Log) {
+	key := fmt.Sprintf("%v:%v", logKey(l), l.Removed)
+	if self.delivered.Has(key) {
+		level.Debug(logger).Log("msg", "skipping already processed log", "tx", l.TxHash, "block", l.BlockNumber)
+		return
+	}
+	self.dispatch(logger, l)
+	if err := self.delivered.Set(key, struct{}{}); err != nil {
+		level.Error(logger).Log("msg", "adding log to the cache", "err", err)
+	}
+	self.setLastBlock(contract, l.BlockNumber)
+}
+
+// dispatch delivers the log directly to the consumers without a confirmation depth
+// and queues it for all others.
+func (self *Hub) dispatch(logger log.Logger, l types.Log) {
+	if len(l.Topics) == 0 {
+		return
+	}
+	self.consumersMtx.RLock()
+	defer self.consumersMtx.RUnlock()
+
+	var queued bool
+	for _, c := range self.consumers {
+		if c.topic != l.Topics[0] {
+			continue
+		}
+		if c.depth == 0 {
+			if err := c.deliver(l); err != nil {
+				level.Error(logger).Log("msg", "delivering log", "tx", l.TxHash, "err", err)
+			}
+			continue
+		}
+		queued = true
+		if l.Removed {
+			self.dropPending(logKey(l))
+			continue
+		}
+		deliver, l := c.deliver, l
+		self.addPending(&item{
+			number: l.BlockNumber,
+			hash:   l.BlockHash,
+			key:    logKey(l),
+			depth:  c.depth,
+			send: func(removed bool) {
+				l.Removed = removed
+				if err := deliver(l); err != nil {
+					level.Error(logger).Log("msg", "delivering log", "tx", l.TxHash, "err", err)
+				}
+			},
+		})
+	}
+	if queued {
+		self.release()
+	}
+}
+
+// watchHeads keeps a subscription for new heads and
+// resubscribes on errors until the hub is stopped.
+func (self *Hub) watchHeads() {
+	heads := make(chan *types.Header)
+	var sub ethereum.Subscription
+	var err error
+	for {
+		for {
+			sub, err = self.client.SubscribeNewHead(self.ctx, heads)
+			if err == nil {
+				break
+			}
+			level.Error(self.logger).Log("msg", "subscribing to new heads", "err", err)
+			select {
+			case <-self.ctx.Done():
+				return
+			case <-time.After(resubscribeDelay):
+			}
+		}
+
+	loop:
+		for {
+			select {
+			case <-self.ctx.Done():
+				sub.Unsubscribe()
+				return
+			case err := <-sub.Err():
+				level.Error(self.logger).Log("msg", "new heads subscription error", "err", err)
+				break loop
+			case head := <-heads:
+				self.newHead(head)
+			}
+		}
+	}
+}
+
+func (self *Hub) newHead(head *types.Header) {
+	self.consumersMtx.RLock()
+	for i, ch := range self.heads {
+		if self.headsDepth[i] == 0 {
+			select {
+			case ch <- &Head{Header: head}:
+			case <-self.ctx.Done():
+			}
+			continue
+		}
+		ch, h := ch, head
+		self.addPending(&item{
+			number: h.Number.Uint64(),
+			hash:   h.Hash(),
+			key:    h.Hash().Hex(),
+			depth:  self.headsDepth[i],
+			send: func(removed bool) {
+				select {
+				case ch <- &Head{Header: h, Removed: removed}:
+				case <-self.ctx.Done():
+				}
+			},
+		})
+	}
+	self.consumersMtx.RUnlock()
+
+	// The parents are fetched without the lock as after a downtime
+	// these can be many and the consumers would block meanwhile.
+	self.mtx.Lock()
+	tracked := self.chain.copy()
+	self.mtx.Unlock()
+	parents, err := tracked.parents(self.ctx, head)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "getting the parent headers", "err", err)
+	}
+
+	self.mtx.Lock()
+	reorged, ancestor, err := self.chain.add(head, parents)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "following the chain", "err", err)
+	}
+	if reorged {
+		level.Info(self.logger).Log("msg", "chain reorg", "ancestor", ancestor, "head", head.Number)
+		// Move the cursor back so a backfill reads the logs from the new canonical blocks.
+		for contract, block := range self.lastBlock {
+			if block > ancestor {
+				self.lastBlock[contract] = ancestor
+			}
+		}
+	}
+	self.mtx.Unlock()
+
+	self.release()
+}
+
+func (self *Hub) addPending(i *item) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.pending = append(self.pending, i)
+}
+
+func (self *Hub) dropPending(key string) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	pending := self.pending[:0]
+	for _, i := range self.pending {
+		if i.key != key {
+			pending = append(pending, i)
+		}
+	}
+	self.pending = pending
+}
+
+// release delivers the pending items that reached their confirmation depth,
+// drops the ones that are no longer canonical and
+// retracts the delivered ones that were reorged out.
+// The items are selected under the lock and sent after it is released
+// so that a slow consumer doesn't block the hub.
+// The sends of concurrent releases are serialized to keep the events in order.
+func (self *Hub) release() {
+	self.sendMtx.Lock()
+	defer self.sendMtx.Unlock()
+
+	released, retracted := self.releasable()
+	for _, i := range released {
+		i.send(false)
+	}
+	for _, i := range retracted {
+		i.send(true)
+	}
+}
+
+// fetchCanonical gets the hashes of the canonical blocks for the pending items
+// that reached their confirmation depth, but are older than the tracked chain.
+// The headers are fetched without holding the lock.
+func (self *Hub) fetchCanonical() map[uint64]common.Hash {
+	self.mtx.Lock()
+	head := self.chain.head()
+	var numbers []uint64
+	for _, i := range self.pending {
+		if head == 0 || head+1 < i.number+i.depth || i.number > head {
+			continue
+		}
+		if _, ok := self.chain.known(i.number); !ok {
+			numbers = append(numbers, i.number)
+		}
+	}
+	self.mtx.Unlock()
+
+	fetched := make(map[uint64]common.Hash)
+	for _, number := range numbers {
+		if _, ok := fetched[number]; ok {
+			continue
+		}
+		header, err := self.client.HeaderByNumber(self.ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			level.Error(self.logger).Log("msg", "getting canonical block", "block", number, "err", err)
+			continue
+		}
+		fetched[number] = header.Hash()
+	}
+	return fetched
+}
+
+// releasable moves the pending items that reached their confirmation depth to the confirmed ones
+// and returns these together with the confirmed items that were reorged out.
+func (self *Hub) releasable() (released, retracted []*item) {
+	fetched := self.fetchCanonical()
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	head := self.chain.head()
+	if head == 0 {
+		return nil, nil
+	}
+
+	pending := self.pending[:0]
+	for _, i := range self.pending {
+		if head+1 < i.number+i.depth {
+			pending = append(pending, i)
+			continue
+		}
+		canonical, ok := self.chain.canonical(i.number, fetched)
+		if !ok {
+			pending = append(pending, i)
+			continue
+		}
+		if canonical != i.hash {
+			level.Debug(self.logger).Log("msg", "dropping event from a non canonical block", "block", i.number, "hash", i.hash)
+			continue
+		}
+		released = append(released, i)
+		self.confirmed = append(self.confirmed, i)
+	}
+	self.pending = pending
+
+	confirmed := self.confirmed[:0]
+	for _, i := range self.confirmed {
+		if i.number+chainRetention < head {
+			continue // Too old to be reorged.
+		}
+		canonical, ok := self.chain.known(i.number)
+		if ok && canonical != i.hash {
+			level.Info(self.logger).Log("msg", "retracting event from a reorged block", "block", i.number, "hash", i.hash)
+			retracted = append(retracted, i)
+			continue
+		}
+		confirmed = append(confirmed, i)
+	}
+	self.confirmed = confirmed
+	return released, retracted
+}
@@ -0,0 +1,384 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package events
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	tEthereum "github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// mockBackend keeps all logs in memory and
+// allows dropping the active subscription to simulate an outage.
+type mockBackend struct {
+	mtx   sync.Mutex
+	logs  []types.Log
+	subCh chan<- types.Log
+	sub   *tEthereum.MockSubscription
+	subs  int
+}
+
+func (self *mockBackend) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	var logs []types.Log
+	for _, l := range self.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func (self *mockBackend) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.subs++
+	self.subCh = ch
+	self.sub = tEthereum.NewMockSubscription()
+	return self.sub, nil
+}
+
+func (self *mockBackend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return tEthereum.NewMockSubscription(), nil
+}
+
+func (self *mockBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return 1, nil
+}
+
+func (self *mockBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return nil, ethereum.NotFound
+}
+
+func (self *mockBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return nil, ethereum.NotFound
+}
+
+// emit adds the log to the chain and sends it to the subscription when it is active.
+func (self *mockBackend) emit(l types.Log, live bool) {
+	self.mtx.Lock()
+	self.logs = append(self.logs, l)
+	ch := self.subCh
+	self.mtx.Unlock()
+	if live {
+		ch <- l
+	}
+}
+
+func (self *mockBackend) drop() {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.sub.Fail(errors.New("connection lost"))
+	self.subCh = nil
+}
+
+func (self *mockBackend) subscriptions() int {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.subs
+}
+
+func transferLog(hub *Hub, block uint64, to common.Address) types.Log {
+	return transferLogAt(hub, block, common.BigToHash(big.NewInt(int64(block))), to)
+}
+
+func transferLogAt(hub *Hub, block uint64, blockHash common.Hash, to common.Address) types.Log {
+	value := common.BigToHash(big.NewInt(1e18))
+	return types.Log{
+		Topics: []common.Hash{
+			hub.abiTellor.Events["Transfer"].ID,
+			{}, // Minted from the zero address.
+			common.BytesToHash(to.Bytes()),
+		},
+		Data:        value.Bytes(),
+		BlockNumber: block,
+		BlockHash:   blockHash,
+	}
+}
+
+func receive(t *testing.T, ch <-chan *tellor.TellorTransferred) *tellor.TellorTransferred {
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for an event")
+	}
+	return nil
+}
+
+func TestHubBackfill(t *testing.T) {
+	contract := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	backend := &mockBackend{}
+	hub, err := New(logging.NewLogger(), context.Background(), Config{LogLevel: "info"}, backend, contract)
+	testutil.Ok(t, err)
+	consumer1 := hub.Transferred(0)
+	consumer2 := hub.Transferred(0)
+
+	go func() {
+		_ = hub.Start()
+	}()
+	defer hub.Stop()
+
+	for backend.subscriptions() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	to := common.HexToAddress("0x1")
+	backend.emit(transferLog(hub, 2, to), true)
+	for _, consumer := range []<-chan *tellor.TellorTransferred{consumer1, consumer2} {
+		event := receive(t, consumer)
+		testutil.Equals(t, uint64(2), event.Raw.BlockNumber)
+		testutil.Equals(t, to, event.To)
+	}
+
+	// Logs missed by the subscription should be backfilled after the reconnect.
+	backend.emit(transferLog(hub, 3, to), false)
+	backend.drop()
+	testutil.Equals(t, uint64(3), receive(t, consumer1).Raw.BlockNumber)
+	testutil.Equals(t, 2, backend.subscriptions())
+	testutil.Equals(t, uint64(3), hub.LastBlock(contract))
+
+	select {
+	case event := <-consumer1:
+		t.Fatalf("already delivered events shouldn't be delivered again, block:%v", event.Raw.BlockNumber)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// mockChain is a simulated chain with forks.
+type mockChain struct {
+	mockBackend
+	headers   map[common.Hash]*types.Header
+	canonical map[uint64]common.Hash
+}
+
+func newMockChain() *mockChain {
+	return &mockChain{
+		headers:   make(map[common.Hash]*types.Header),
+		canonical: make(map[uint64]common.Hash),
+	}
+}
+
+// extend creates count blocks on top of the parent and
+// makes the last one the canonical head.
+func (self *mockChain) extend(parent *types.Header, fork string, count int) []*types.Header {
+	var blocks []*types.Header
+	for i := 0; i < count; i++ {
+		h := &types.Header{
+			Number:     big.NewInt(1),
+			Difficulty: big.NewInt(0),
+			Extra:      []byte(fork),
+		}
+		if parent != nil {
+			h.Number = new(big.Int).Add(parent.Number, big.NewInt(1))
+			h.ParentHash = parent.Hash()
+		}
+		self.headers[h.Hash()] = h
+		blocks = append(blocks, h)
+		parent = h
+	}
+	for h := parent; h != nil; h = self.headers[h.ParentHash] {
+		self.canonical[h.Number.Uint64()] = h.Hash()
+	}
+	return blocks
+}
+
+func (self *mockChain) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	h, ok := self.headers[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return h, nil
+}
+
+func (self *mockChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return self.HeaderByHash(ctx, self.canonical[number.Uint64()])
+}
+
+func receiveHead(t *testing.T, ch <-chan *Head) *Head {
+	select {
+	case head := <-ch:
+		return head
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a head")
+	}
+	return nil
+}
+
+func nothingReceived(t *testing.T, ch <-chan *tellor.TellorTransferred) {
+	select {
+	case event := <-ch:
+		t.Fatalf("unexpected event block:%v, removed:%v", event.Raw.BlockNumber, event.Raw.Removed)
+	default:
+	}
+}
+
+func TestHubConfirmations(t *testing.T) {
+	contract := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	chain := newMockChain()
+	logger := logging.NewLogger()
+	hub, err := New(logger, context.Background(), Config{LogLevel: "info"}, chain, contract)
+	testutil.Ok(t, err)
+	immediate := hub.Transferred(0)
+	confirmed := hub.Transferred(3)
+	heads := hub.Heads(2)
+
+	to := common.HexToAddress("0x1")
+	a := chain.extend(nil, "a", 5)
+	hub.newHead(a[0])
+
+	l := transferLogAt(hub, 2, a[1].Hash(), to)
+	hub.process(logger, contract, l)
+	testutil.Equals(t, uint64(2), receive(t, immediate).Raw.BlockNumber)
+	nothingReceived(t, confirmed)
+
+	hub.newHead(a[1])
+	hub.newHead(a[2])
+	nothingReceived(t, confirmed)
+	hub.newHead(a[3])
+	event := receive(t, confirmed)
+	testutil.Equals(t, a[1].Hash(), event.Raw.BlockHash)
+	testutil.Assert(t, !event.Raw.Removed, "the event shouldn't be marked as removed")
+	for i := 0; i < 3; i++ {
+		head := receiveHead(t, heads)
+		testutil.Equals(t, a[i].Hash(), head.Hash())
+	}
+
+	// An event reorged out before reaching the confirmation depth is never delivered.
+	hub.process(logger, contract, transferLogAt(hub, 5, a[4].Hash(), to))
+	hub.newHead(a[4])
+	testutil.Equals(t, a[3].Hash(), receiveHead(t, heads).Hash())
+	b := chain.extend(a[3], "b", 3)
+	hub.newHead(b[2])
+	nothingReceived(t, confirmed)
+	testutil.Equals(t, 0, len(heads), "the reorged head shouldn't be delivered")
+
+	// An already delivered event from a reorged block is retracted.
+	c := chain.extend(a[0], "c", 7)
+	hub.newHead(c[6])
+	event = receive(t, confirmed)
+	testutil.Equals(t, a[1].Hash(), event.Raw.BlockHash)
+	testutil.Assert(t, event.Raw.Removed, "the reorged event should be marked as removed")
+	nothingReceived(t, confirmed)
+	for _, retracted := range a[1:4] {
+		head := receiveHead(t, heads)
+		testutil.Equals(t, retracted.Hash(), head.Hash())
+		testutil.Assert(t, head.Removed, "the reorged head should be marked as removed")
+	}
+	testutil.Equals(t, uint64(1), hub.LastBlock(contract), "the cursor should be moved back to the common ancestor")
+}
+
+func TestHubSlowConsumer(t *testing.T) {
+	contract := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	chain := newMockChain()
+	hub, err := New(logging.NewLogger(), context.Background(), Config{LogLevel: "info"}, chain, contract)
+	testutil.Ok(t, err)
+	heads := hub.Heads(1)
+
+	// A consumer that doesn't read blocks the sending when its buffer is full.
+	blocks := chain.extend(nil, "a", consumerBuffer+3)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, h := range blocks {
+			hub.newHead(h)
+		}
+	}()
+	timeout := time.After(5 * time.Second)
+	for len(heads) < consumerBuffer {
+		select {
+		case <-timeout:
+			t.Fatal("timeout waiting for the consumer buffer to fill")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	// The hub state is still accessible while the sending is blocked.
+	lastBlock := make(chan uint64)
+	go func() { lastBlock <- hub.LastBlock(contract) }()
+	select {
+	case <-lastBlock:
+	case <-time.After(time.Second):
+		t.Fatal("the hub is blocked by a slow consumer")
+	}
+
+	hub.Stop()
+	<-done
+}
+
+// mockSlowChain blocks the header requests until released.
+type mockSlowChain struct {
+	*mockChain
+	fetching chan struct{}
+	release  chan struct{}
+}
+
+func (self *mockSlowChain) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	select {
+	case self.fetching <- struct{}{}:
+	default:
+	}
+	<-self.release
+	return self.mockChain.HeaderByHash(ctx, hash)
+}
+
+func TestHubSlowHeaders(t *testing.T) {
+	contract := common.HexToAddress("0x88dF592F8eb5D7Bd38bFeF7dEb0fBc02cf3778a0")
+	chain := &mockSlowChain{
+		mockChain: newMockChain(),
+		fetching:  make(chan struct{}, 1),
+		release:   make(chan struct{}),
+	}
+	hub, err := New(logging.NewLogger(), context.Background(), Config{LogLevel: "info"}, chain, contract)
+	testutil.Ok(t, err)
+	heads := hub.Heads(0)
+
+	blocks := chain.extend(nil, "a", 10)
+	hub.newHead(blocks[0])
+	receiveHead(t, heads)
+
+	// The head after a downtime needs all the missed parents.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		hub.newHead(blocks[9])
+	}()
+	select {
+	case <-chain.fetching:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the parent headers fetch")
+	}
+
+	// The hub state is still accessible while the parents are fetched.
+	lastBlock := make(chan uint64)
+	go func() { lastBlock <- hub.LastBlock(contract) }()
+	select {
+	case <-lastBlock:
+	case <-time.After(time.Second):
+		t.Fatal("the hub is blocked by the parent headers fetch")
+	}
+
+	close(chain.release)
+	<-done
+	testutil.Equals(t, blocks[9].Hash(), receiveHead(t, heads).Hash())
+	hub.mtx.Lock()
+	defer hub.mtx.Unlock()
+	for _, h := range blocks {
+		hash, ok := hub.chain.known(h.Number.Uint64())
+		testutil.Assert(t, ok, "block %v should be tracked", h.Number)
+		testutil.Equals(t, h.Hash(), hash)
+	}
+}
@@ -9,11 +9,11 @@ import (
 	"math/big"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/web"
 )
@@ -27,7 +27,7 @@ type Config struct {
 type GasStation struct {
 	netID  int64
 	cfg    Config
-	client *ethclient.Client
+	client ethereum.EthClient
 	logger log.Logger
 }
 
@@ -38,7 +38,7 @@ type GasStationModel struct {
 	Average float32 `json:"average"`
 }
 
-func New(logger log.Logger, cfg Config, client *ethclient.Client) (*GasStation, error) {
+func New(logger log.Logger, cfg Config, client ethereum.EthClient) (*GasStation, error) {
 	ctx, cncl := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cncl()
 	netID, err := client.NetworkID(ctx)
@@ -0,0 +1,91 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+// SolutionWindow is the time after the last new value in which a solution needs to match the difficulty.
+// After it the contract accepts any nonce.
+const SolutionWindow = anySolutionPeriod
+
+// BackendRate is the measured hash rate of a mining backend.
+type BackendRate struct {
+	Name     string
+	HashRate float64
+}
+
+// Bench mines a synthetic challenge that has practically no solution
+// for the given duration and returns the hash rate of every backend.
+func Bench(logger log.Logger, ctx context.Context, cfg Config, duration time.Duration) ([]BackendRate, error) {
+	group, err := SetupMiningGroup(logger, ctx, cfg, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "setup MiningGroup")
+	}
+
+	challenge := &MiningChallenge{
+		Challenge:  make([]byte, 32),
+		Difficulty: big.NewInt(math.MaxInt64),
+	}
+	input := make(chan *Work)
+	output := make(chan *Result, 1)
+	ctx, cncl := context.WithCancel(ctx)
+	defer cncl()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		group.Mine(ctx, input, output)
+	}()
+
+	started := time.Now()
+	select {
+	case input <- &Work{Challenge: challenge, PublicAddr: "0x0000000000000000000000000000000000000000", N: math.MaxInt64}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	cncl()
+	<-done
+	elapsed := time.Since(started).Seconds()
+
+	rates := make([]BackendRate, 0, len(group.Backends))
+	for _, b := range group.Backends {
+		rates = append(rates, BackendRate{Name: b.Name(), HashRate: float64(b.TotalHashes) / elapsed})
+	}
+	return rates, nil
+}
+
+// ExpectedSolveTime returns the average time to find a solution.
+// Every hash has a 1/difficulty chance to be a solution.
+func ExpectedSolveTime(hashRate float64, difficulty *big.Int) time.Duration {
+	if hashRate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	diff, _ := new(big.Float).SetInt(difficulty).Float64()
+	seconds := diff / hashRate
+	if seconds >= float64(math.MaxInt64)/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// SolveProbability returns the chance to find at least one solution within the window.
+func SolveProbability(hashRate float64, difficulty *big.Int, window time.Duration) float64 {
+	diff, _ := new(big.Float).SetInt(difficulty).Float64()
+	if diff <= 0 {
+		return 1
+	}
+	return -math.Expm1(-hashRate * window.Seconds() / diff)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+	"github.com/pkg/errors"
+
+	// nolint:staticcheck
+	"golang.org/x/crypto/ripemd160"
+)
+
+// cancelCheckInterval is how many nonces are checked between checks for a canceled context.
+const cancelCheckInterval = 1024
+
+// FastCpuMiner is a CPU hasher that doesn't allocate for every checked nonce.
+// It reuses the hash states, increments the decimal nonce in place and
+// checks the difficulty with fixed width 256 bit arithmetic.
+// It finds the same solutions as the CpuMiner.
+type FastCpuMiner struct {
+	id int64
+
+	keccak    crypto.KeccakState
+	ripemd    hash.Hash
+	sha       hash.Hash
+	input     []byte
+	keccakOut [32]byte
+	ripemdOut []byte
+	shaOut    []byte
+	x         uint256.Int
+}
+
+func NewFastCpuMiner(id int64) *FastCpuMiner {
+	return &FastCpuMiner{
+		id:        id,
+		keccak:    crypto.NewKeccakState(),
+		ripemd:    ripemd160.New(),
+		sha:       sha256.New(),
+		ripemdOut: make([]byte, 0, ripemd160.Size),
+		shaOut:    make([]byte, 0, sha256.Size),
+	}
+}
+
+func (c *FastCpuMiner) StepSize() uint64 {
+	return 1
+}
+
+func (c *FastCpuMiner) Name() string {
+	return fmt.Sprintf("FastCPU %d", c.id)
+}
+
+func (c *FastCpuMiner) CheckRange(anySolution context.Context, hash *HashSettings, start uint64, n uint64) (string, uint64, error) {
+	difficulty, overflow := uint256.FromBig(hash.difficulty)
+	if overflow || difficulty.IsZero() {
+		return "", 0, errors.Errorf("invalid difficulty:%v", hash.difficulty)
+	}
+
+	// Keep enough space for the longest uint64 so the nonce never reallocates.
+	baseLen := len(hash.prefix)
+	if cap(c.input) < baseLen+20 {
+		c.input = make([]byte, 0, baseLen+20)
+	}
+	c.input = append(c.input[:0], hash.prefix...)
+	c.input = strconv.AppendUint(c.input, start, 10)
+
+	for i := uint64(0); i < n; i++ {
+		if i%cancelCheckInterval == 0 {
+			select {
+			case <-anySolution.Done():
+				return "any", n, nil
+			default:
+			}
+		}
+		if c.check(difficulty) {
+			return string(c.input[baseLen:]), i + 1, nil
+		}
+		c.input = incrementDecimal(c.input, baseLen)
+	}
+	return "", n, nil
+}
+
+// check returns true when the hash of the current input is divisible by the difficulty.
+func (c *FastCpuMiner) check(difficulty *uint256.Int) bool {
+	c.keccak.Reset()
+	c.keccak.Write(c.input)
+	c.keccak.Read(c.keccakOut[:]) // nolint:errcheck
+
+	c.ripemd.Reset()
+	c.ripemd.Write(c.keccakOut[:])
+	c.ripemdOut = c.ripemd.Sum(c.ripemdOut[:0])
+
+	c.sha.Reset()
+	c.sha.Write(c.ripemdOut)
+	c.shaOut = c.sha.Sum(c.shaOut[:0])
+
+	c.x.SetBytes(c.shaOut)
+	c.x.Mod(&c.x, difficulty)
+	return c.x.IsZero()
+}
+
+// incrementDecimal adds one to the decimal number stored in buf[from:].
+// The buffer grows by one digit only when all digits are 9.
+func incrementDecimal(buf []byte, from int) []byte {
+	for i := len(buf) - 1; i >= from; i-- {
+		if buf[i] != '9' {
+			buf[i]++
+			return buf
+		}
+		buf[i] = '0'
+	}
+	buf = append(buf, '0')
+	buf[from] = '1'
+	return buf
+}
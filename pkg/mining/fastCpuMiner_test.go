@@ -0,0 +1,78 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func testHashSettings(difficulty int64) *HashSettings {
+	challenge := &MiningChallenge{
+		Challenge:  make([]byte, 32),
+		Difficulty: big.NewInt(difficulty),
+	}
+	challenge.Challenge[31] = 0x13
+	return NewHashSettings(challenge, "0xabcd012345678901234567890123456789012345")
+}
+
+func TestIncrementDecimal(t *testing.T) {
+	for _, start := range []uint64{0, 8, 9, 99, 1099, 12345, math.MaxUint32} {
+		buf := strconv.AppendUint([]byte("prefix"), start, 10)
+		buf = incrementDecimal(buf, len("prefix"))
+		testutil.Equals(t, "prefix"+strconv.FormatUint(start+1, 10), string(buf))
+	}
+}
+
+func TestFastCpuMinerMatchesHashFn(t *testing.T) {
+	for _, difficulty := range []int64{1, 2, 7, 100, 1e6} {
+		hash := testHashSettings(difficulty)
+		miner := NewFastCpuMiner(0)
+		for start := uint64(0); start < 200; start++ {
+			input := append(append([]byte{}, hash.prefix...), strconv.FormatUint(start, 10)...)
+			exp, err := hashFn(input)
+			testutil.Ok(t, err)
+			exp.Mod(exp, hash.difficulty)
+
+			nonce, _, err := miner.CheckRange(context.Background(), hash, start, 1)
+			testutil.Ok(t, err)
+			testutil.Equals(t, exp.Sign() == 0, nonce != "", "difficulty:%v nonce:%v", difficulty, start)
+		}
+	}
+}
+
+func TestFastCpuMinerMatchesCpuMiner(t *testing.T) {
+	hash := testHashSettings(500)
+	for _, start := range []uint64{0, 95, 9990, 1e12} {
+		expNonce, expN, err := NewCpuMiner(0).CheckRange(context.Background(), hash, start, 10000)
+		testutil.Ok(t, err)
+		nonce, n, err := NewFastCpuMiner(0).CheckRange(context.Background(), hash, start, 10000)
+		testutil.Ok(t, err)
+		testutil.Assert(t, expNonce != "", "the test range should contain a solution")
+		testutil.Equals(t, expNonce, nonce)
+		testutil.Equals(t, expN, n)
+	}
+}
+
+func benchmarkHasher(b *testing.B, hasher Hasher) {
+	// A difficulty high enough to never find a solution.
+	hash := testHashSettings(math.MaxInt64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	_, _, err := hasher.CheckRange(context.Background(), hash, 0, uint64(b.N))
+	testutil.Ok(b, err)
+}
+
+func BenchmarkCpuMiner(b *testing.B) {
+	benchmarkHasher(b, NewCpuMiner(0))
+}
+
+func BenchmarkFastCpuMiner(b *testing.B) {
+	benchmarkHasher(b, NewFastCpuMiner(0))
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
@@ -20,6 +22,25 @@ import (
 
 const ComponentName = "miner"
 
+var (
+	hashRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "hash_rate",
+		Help:      "The hash rate of each mining backend in hashes per second",
+	},
+		[]string{"addr", "backend"},
+	)
+	hashesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "hashes_total",
+		Help:      "The total number of hashes checked by each mining backend",
+	},
+		[]string{"addr", "backend"},
+	)
+)
+
 type HashSettings struct {
 	prefix     []byte
 	difficulty *big.Int
@@ -43,6 +64,10 @@ type Backend struct {
 	TotalHashes      uint64
 	HashSincePrint   uint64
 	HashRateEstimate float64
+
+	// The part of the current work nonce range assigned to this backend.
+	next uint64
+	end  uint64
 }
 
 // MiningChallenge holds information about a PoW challenge.
@@ -76,6 +101,8 @@ type MiningGroup struct {
 	LastPrinted      time.Time
 	logger           log.Logger
 	contractInstance *contracts.ITellor
+	// addr is the public address of the current work used to label the hash rate metrics.
+	addr string
 }
 
 func NewMiningGroup(logger log.Logger, ctx context.Context, cfg Config, hashers []Hasher, contractInstance *contracts.ITellor) (*MiningGroup, error) {
@@ -126,7 +153,8 @@ func (b *Backend) doWork(anySolution context.Context, close context.CancelFunc,
 	}
 }
 
-func formatHashRate(rate float64) string {
+// FormatHashRate returns the hash rate in a human readable form.
+func FormatHashRate(rate float64) string {
 	letters := " KMGTQ"
 	i := 0
 	//purposely made this 10k instead of 1k. That way you won't get single digit rates
@@ -165,20 +193,25 @@ func (g *MiningGroup) PrintHashRateSummary() {
 	now := time.Now()
 	delta := now.Sub(g.LastPrinted).Seconds()
 	totalHashrate := float64(totalHashes) / delta
-	level.Info(g.logger).Log("msg", "check total hashrate", "totalHashrate", formatHashRate(totalHashrate))
+	level.Info(g.logger).Log("msg", "check total hashrate", "totalHashrate", FormatHashRate(totalHashrate))
 	for _, b := range g.Backends {
-		hashRate := float64(b.HashSincePrint) / delta
+		rate := float64(b.HashSincePrint) / delta
 		level.Debug(g.logger).Log(
 			"msg", "print hash values",
-			"hashRate", fmt.Sprintf("%8s", formatHashRate(hashRate)),
-			"avgHashRate", fmt.Sprintf("%4.1f%%", (hashRate/totalHashrate)*100),
+			"hashRate", fmt.Sprintf("%8s", FormatHashRate(rate)),
+			"avgHashRate", fmt.Sprintf("%4.1f%%", (rate/totalHashrate)*100),
 			"name", b.Name(),
 		)
+		lbls := prometheus.Labels{"addr": g.addr, "backend": b.Name()}
+		hashRate.With(lbls).(prometheus.Gauge).Set(rate)
+		hashesTotal.With(lbls).(prometheus.Counter).Add(float64(b.HashSincePrint))
 		b.HashSincePrint = 0
 	}
 	g.LastPrinted = now
 }
 
+// Work is a nonce range to search for a solution of the challenge.
+// A nil work pauses the mining until the next work.
 type Work struct {
 	Challenge  *MiningChallenge
 	PublicAddr string
@@ -191,8 +224,25 @@ type Result struct {
 	Nonce string
 }
 
-// dispatches a chunk and returns the number of hashes chosen.
-func (b *Backend) dispatchWork(parentCtx context.Context, timeOfLastNewValue *big.Int, hash *HashSettings, start uint64, resultCh chan *backendResult) uint64 {
+// splitRange gives every backend its own part of the work nonce range
+// so that no two backends check the same nonce.
+// The last backend also gets the remainder of the division.
+func (g *MiningGroup) splitRange(work *Work) {
+	span := work.N / uint64(len(g.Backends))
+	start := work.Start
+	for i, b := range g.Backends {
+		b.next = start
+		b.end = start + span
+		if i == len(g.Backends)-1 {
+			b.end = work.Start + work.N
+		}
+		start = b.end
+	}
+}
+
+// dispatches a chunk from the backend range and returns the number of hashes chosen.
+// After the deadline any nonce is a valid solution.
+func (b *Backend) dispatchWork(parentCtx context.Context, deadline time.Time, hash *HashSettings, resultCh chan *backendResult) uint64 {
 	target := b.HashRateEstimate * targetChunkTime.Seconds()
 	step := b.StepSize()
 	nsteps := uint64(math.Round(target / float64(step)))
@@ -200,8 +250,18 @@ func (b *Backend) dispatchWork(parentCtx context.Context, timeOfLastNewValue *bi
 		nsteps = 1
 	}
 	n := nsteps * step
-	tm := time.Unix(timeOfLastNewValue.Int64(), 0)
-	anySolution, close := context.WithDeadline(parentCtx, tm.Add(15*time.Minute))
+	if n > b.end-b.next {
+		n = b.end - b.next
+	}
+	start := b.next
+	b.next += n
+	var anySolution context.Context
+	var close context.CancelFunc
+	if deadline.IsZero() {
+		anySolution, close = context.WithCancel(parentCtx)
+	} else {
+		anySolution, close = context.WithDeadline(parentCtx, deadline)
+	}
 	go b.doWork(anySolution, close, hash, start, n, resultCh)
 	return n
 }
@@ -224,7 +284,6 @@ func (g *MiningGroup) getTimeOfLastNewValue() *big.Int {
 }
 
 func (g *MiningGroup) Mine(ctx context.Context, input chan *Work, output chan *Result) {
-	sent := uint64(0)
 	recv := uint64(0)
 	timeStarted := time.Now()
 	g.LastPrinted = timeStarted
@@ -261,10 +320,16 @@ func (g *MiningGroup) Mine(ctx context.Context, input chan *Work, output chan *R
 			return
 		// Read in a new work block.
 		case work := <-input:
-			sent = 0
 			recv = 0
+			if work == nil {
+				currWork = nil
+				currHashSettings = nil
+				break
+			}
 			currWork = work
 			currHashSettings = NewHashSettings(work.Challenge, work.PublicAddr)
+			g.addr = work.PublicAddr
+			g.splitRange(work)
 
 		// Read in a result from one of the miners.
 		case result := <-resultChannel:
@@ -317,10 +382,19 @@ func (g *MiningGroup) Mine(ctx context.Context, input chan *Work, output chan *R
 			}
 		}
 		if currWork != nil {
-			for sent < currWork.N && len(idleWorkers) > 0 {
+			for i := len(idleWorkers); i > 0; i-- {
 				worker := <-idleWorkers
-				timeOfLastNewValue := g.getTimeOfLastNewValue()
-				sent += worker.dispatchWork(ctx, timeOfLastNewValue, currHashSettings, currWork.Start+sent, resultChannel)
+				if worker.next >= worker.end {
+					idleWorkers <- worker // Its range is done so it waits for the next work.
+					continue
+				}
+				// 15min after the last submit any solution will work.
+				// Remote workers don't have a contract instance so these always search for a real solution.
+				var deadline time.Time
+				if g.contractInstance != nil {
+					deadline = time.Unix(g.getTimeOfLastNewValue().Int64(), 0).Add(15 * time.Minute)
+				}
+				worker.dispatchWork(ctx, deadline, currHashSettings, resultChannel)
 			}
 		}
 	}
@@ -3,6 +3,17 @@
 
 package mining
 
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
 // import (
 // 	"context"
 
@@ -196,3 +207,54 @@ package mining
 // 		testutil.Ok(b, err)
 // 	}
 // }
+
+func TestSplitRange(t *testing.T) {
+	var hashers []Hasher
+	for i := 0; i < 3; i++ {
+		hashers = append(hashers, NewCpuMiner(int64(i)))
+	}
+	group := &MiningGroup{}
+	for _, hasher := range hashers {
+		group.Backends = append(group.Backends, &Backend{Hasher: hasher, HashRateEstimate: rateInitialGuess})
+	}
+
+	work := &Work{Start: 1000, N: 100}
+	group.splitRange(work)
+	next := work.Start
+	for _, b := range group.Backends {
+		testutil.Equals(t, next, b.next, "ranges shouldn't overlap or leave gaps")
+		testutil.Assert(t, b.end > b.next, "every backend should get a non empty range")
+		next = b.end
+	}
+	testutil.Equals(t, work.Start+work.N, next, "ranges should cover the whole work")
+
+	// Chunks never go past the end of the backend range.
+	b := group.Backends[0]
+	b.HashRateEstimate = 1e9
+	end := b.end
+	resultCh := make(chan *backendResult, 1)
+	n := b.dispatchWork(context.Background(), time.Now().Add(time.Minute), &HashSettings{prefix: make([]byte, 52), difficulty: big.NewInt(math.MaxInt64)}, resultCh)
+	testutil.Equals(t, uint64(33), n)
+	testutil.Equals(t, end, b.next)
+	testutil.Ok(t, (<-resultCh).err)
+}
+
+func TestBench(t *testing.T) {
+	rates, err := Bench(logging.NewLogger(), context.Background(), Config{LogLevel: "info", Heartbeat: time.Minute, NumProcessors: 2}, 500*time.Millisecond)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(rates))
+	for _, rate := range rates {
+		testutil.Assert(t, rate.HashRate > 0, "backend %v should report a hash rate", rate.Name)
+	}
+}
+
+func TestSolveEstimates(t *testing.T) {
+	difficulty := big.NewInt(1e9)
+	testutil.Equals(t, 1000*time.Second, ExpectedSolveTime(1e6, difficulty))
+	testutil.Equals(t, time.Duration(math.MaxInt64), ExpectedSolveTime(0, difficulty))
+
+	// On average one solution per window gives 1-1/e chance for at least one.
+	p := SolveProbability(1e9/SolutionWindow.Seconds(), difficulty, SolutionWindow)
+	testutil.Assert(t, math.Abs(p-(1-1/math.E)) < 1e-9, "unexpected probability:%v", p)
+	testutil.Equals(t, 0.0, SolveProbability(0, difficulty, SolutionWindow))
+}
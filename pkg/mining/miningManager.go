@@ -4,35 +4,61 @@
 package mining
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"runtime"
 	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/ethereum"
 	"github.com/tellor-io/telliot/pkg/logging"
 )
 
 type Config struct {
-	LogLevel  string
-	Heartbeat time.Duration
+	LogLevel      string
+	Heartbeat     time.Duration
+	NumProcessors int `help:"Number of CPU hashers for every mining account. 0 uses the number of CPU cores."`
+	Pool          PoolConfig
+}
+
+// Processors returns the number of CPU hashers resolving 0 to the number of CPU cores of this host.
+func (self Config) Processors() int {
+	if self.NumProcessors == 0 {
+		return runtime.NumCPU()
+	}
+	return self.NumProcessors
+}
+
+// Miner finds solutions for the work from the input and sends them to the output.
+// It is implemented by the local MiningGroup and the PoolServer for remote workers.
+type Miner interface {
+	Mine(ctx context.Context, input chan *Work, output chan *Result)
 }
 
 type SolutionSink interface {
 	Submit(context.Context, *Result) (*types.Transaction, error)
 }
 
-const NumProcessors = 1
+// SolutionDiscarder removes the pending state of a solution that won't be submitted.
+type SolutionDiscarder interface {
+	Discard(*Result)
+}
 
 func SetupMiningGroup(logger log.Logger, ctx context.Context, cfg Config, contractInstance *contracts.ITellor) (*MiningGroup, error) {
 	var hashers []Hasher
-	level.Info(logger).Log("msg", "starting CPU mining", "threads", NumProcessors)
-	for i := 0; i < NumProcessors; i++ {
-		hashers = append(hashers, NewCpuMiner(int64(i)))
+	processors := cfg.Processors()
+	if processors < 1 {
+		return nil, errors.Errorf("invalid number of processors:%v", cfg.NumProcessors)
+	}
+	level.Info(logger).Log("msg", "starting CPU mining", "threads", processors)
+	for i := 0; i < processors; i++ {
+		hashers = append(hashers, NewFastCpuMiner(int64(i)))
 	}
 	miningGrp, err := NewMiningGroup(logger, ctx, cfg, hashers, contractInstance)
 	if err != nil {
@@ -51,16 +77,21 @@ type MiningMgr struct {
 	ctx              context.Context
 	close            context.CancelFunc
 	logger           log.Logger
-	ethClient        *ethclient.Client
-	group            *MiningGroup
+	ethClient        ethereum.EthClient
+	group            Miner
 	taskerCh         chan *Work
 	submitterCh      chan *Result
 	contractInstance *contracts.ITellor
 	toMineInput      chan *Work
 	solutionOutput   chan *Result
+	verifier         *verifier
+	recovered        *Result
+	discarder        SolutionDiscarder
 }
 
 // NewMiningManager is the MiningMgr constructor.
+// When a pool server is given the work is mined by the remote workers
+// connected to the pool instead of the local hashers.
 func NewMiningManager(
 	logger log.Logger,
 	ctx context.Context,
@@ -68,7 +99,8 @@ func NewMiningManager(
 	contractInstance *contracts.ITellor,
 	taskerCh chan *Work,
 	submitterCh chan *Result,
-	client *ethclient.Client,
+	client ethereum.EthClient,
+	pool *PoolServer,
 ) (*MiningMgr, error) {
 
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
@@ -77,9 +109,12 @@ func NewMiningManager(
 	}
 	logger = log.With(logger, "component", ComponentName)
 
-	group, err := SetupMiningGroup(logger, ctx, cfg, contractInstance)
-	if err != nil {
-		return nil, errors.Wrap(err, "setup MiningGroup")
+	var group Miner = pool
+	if pool == nil {
+		group, err = SetupMiningGroup(logger, ctx, cfg, contractInstance)
+		if err != nil {
+			return nil, errors.Wrap(err, "setup MiningGroup")
+		}
 	}
 
 	ctx, close := context.WithCancel(ctx)
@@ -94,6 +129,7 @@ func NewMiningManager(
 		ethClient:        client,
 		toMineInput:      make(chan *Work),
 		solutionOutput:   make(chan *Result),
+		verifier:         newVerifier(logger, contractInstance),
 	}
 	return mng, nil
 }
@@ -112,12 +148,38 @@ func (mgr *MiningMgr) Start() error {
 
 		// Found a solution.
 		case solution := <-mgr.solutionOutput:
-			level.Info(mgr.logger).Log("msg", "sending the solution to the submitter")
-			mgr.submitterCh <- solution
+			if _, err := mgr.forward(solution); err != nil {
+				return err
+			}
 
 		// Listen for new work from the tasker and send for mining.
 		case work := <-mgr.taskerCh:
-			mgr.toMineInput <- work
+			if recovered := mgr.recovered; recovered != nil && work != nil {
+				mgr.recovered = nil
+				if bytes.Equal(recovered.Work.Challenge.Challenge, work.Challenge.Challenge) && recovered.Work.PublicAddr == work.PublicAddr {
+					level.Info(mgr.logger).Log("msg", "sending the solution found before the restart", "nonce", recovered.Nonce)
+					sent, err := mgr.forward(&Result{Work: work, Nonce: recovered.Nonce})
+					if err != nil {
+						return err
+					}
+					if sent {
+						continue
+					}
+				} else {
+					level.Info(mgr.logger).Log("msg", "discarding the solution found before the restart as the challenge has changed", "nonce", recovered.Nonce)
+				}
+				mgr.discarder.Discard(recovered)
+			}
+			// The mining group exits on shutdown so it might not receive the work.
+			select {
+			case mgr.toMineInput <- work:
+			case <-mgr.ctx.Done():
+				return mgr.ctx.Err()
+			}
+			if work == nil {
+				level.Info(mgr.logger).Log("msg", "paused mining until the account can submit")
+				continue
+			}
 			level.Info(mgr.logger).Log("msg", "sent new challenge to the mining group",
 				"challenge", fmt.Sprintf("%x", work.Challenge.Challenge),
 				"difficulty", work.Challenge.Difficulty,
@@ -128,6 +190,31 @@ func (mgr *MiningMgr) Start() error {
 
 }
 
+// AddSolution sets a solution found before a restart.
+// It is sent to the submitter when the tasker sends its challenge instead of mining the challenge again.
+// When the challenge has changed or the solution is dropped it is removed with the discarder.
+func (mgr *MiningMgr) AddSolution(result *Result, discarder SolutionDiscarder) {
+	mgr.recovered = result
+	mgr.discarder = discarder
+}
+
+// forward sends a verified solution to the submitter.
+// It returns false when the solution is dropped.
+func (mgr *MiningMgr) forward(solution *Result) (bool, error) {
+	if reason := mgr.verifier.verify(mgr.ctx, solution); reason != "" {
+		level.Warn(mgr.logger).Log("msg", "dropping solution", "reason", reason, "nonce", solution.Nonce)
+		solutionsDropped.With(prometheus.Labels{"addr": solution.Work.PublicAddr, "reason": reason}).(prometheus.Counter).Inc()
+		return false, nil
+	}
+	level.Info(mgr.logger).Log("msg", "sending the solution to the submitter")
+	select {
+	case mgr.submitterCh <- solution:
+		return true, nil
+	case <-mgr.ctx.Done():
+		return false, mgr.ctx.Err()
+	}
+}
+
 // Stop will take care of stopping the miner component.
 func (mgr *MiningMgr) Stop() {
 	mgr.close()
@@ -0,0 +1,512 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+// The pool protocol is a stratum like line delimited JSON-RPC over TCP.
+// A worker sends a subscribe request and
+// the pool replies with a notify whenever the worker should switch to a new job.
+// Every notify carries its own nonce range so no two workers check the same nonces.
+// Found nonces are sent back with a submit request.
+const (
+	MethodSubscribe = "mining.subscribe"
+	MethodNotify    = "mining.notify"
+	MethodSubmit    = "mining.submit"
+)
+
+// poolWorkerRange is the size of the nonce range given to a worker for each job.
+const poolWorkerRange = 1 << 40
+
+// poolWriteTimeout is how long to wait for a write to a worker before dropping it.
+const poolWriteTimeout = 10 * time.Second
+
+var (
+	poolWorkers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "pool_workers",
+		Help:      "The number of remote workers connected to the mining pool",
+	})
+	poolShares = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "telliot",
+		Subsystem: ComponentName,
+		Name:      "pool_shares_total",
+		Help:      "The number of nonces submitted by remote workers by result",
+	},
+		[]string{"worker", "result"},
+	)
+)
+
+type PoolConfig struct {
+	Enabled    bool   `help:"Serve the mining work to remote workers instead of mining locally. The pool has no authentication so it should be reachable only from trusted networks."`
+	ListenHost string `help:"The address the mining pool listens on."`
+	ListenPort uint   `help:"The port the mining pool listens on."`
+	// Workers are the worker names with their own label in the share metrics.
+	// The names are set by the workers so the others are counted together to keep the number of series bounded.
+	Workers []string `help:"Worker names with their own label in the share metrics. The shares of the other workers are counted under the other label."`
+}
+
+// poolOtherWorkers is the share metrics label for the workers that are not in the config.
+const poolOtherWorkers = "other"
+
+// PoolRequest is a request or a notification in the pool protocol.
+type PoolRequest struct {
+	ID     *uint64         `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// PoolResponse is a reply to a pool request.
+type PoolResponse struct {
+	ID     *uint64     `json:"id"`
+	Result interface{} `json:"result"`
+	Error  *string     `json:"error"`
+}
+
+// PoolJob is sent with the notify method.
+// The prefix is the hash input before the nonce so
+// hashers that don't know the telliot format can still work on it.
+type PoolJob struct {
+	ID         string        `json:"id"`
+	Challenge  hexutil.Bytes `json:"challenge"`
+	Difficulty string        `json:"difficulty"`
+	Prefix     hexutil.Bytes `json:"prefix"`
+	PublicAddr string        `json:"address"`
+	Start      uint64        `json:"start"`
+	N          uint64        `json:"n"`
+}
+
+// PoolSubmit is sent with the submit method.
+type PoolSubmit struct {
+	JobID string `json:"id"`
+	Nonce string `json:"nonce"`
+}
+
+type poolJob struct {
+	id     string
+	seq    uint64
+	work   *Work
+	hash   *HashSettings
+	output chan *Result
+	// next is the offset from the work start of the next nonce range to give to a worker.
+	next uint64
+}
+
+type poolConn struct {
+	name    string
+	seq     uint64
+	conn    net.Conn
+	timeout time.Duration
+	// job and assigned are guarded by the pool mutex.
+	job *poolJob
+	// assigned increases with every job assigned to the worker.
+	assigned uint64
+
+	mtx sync.Mutex
+	enc *json.Encoder
+	// notified is the assignment of the last sent notify.
+	notified uint64
+}
+
+func (self *poolConn) send(msg interface{}) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.write(msg)
+}
+
+// notify sends the job of the given assignment unless a newer one was already sent.
+func (self *poolConn) notify(assigned uint64, msg interface{}) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if assigned <= self.notified {
+		return nil
+	}
+	self.notified = assigned
+	return self.write(msg)
+}
+
+// write should be called with the mutex held.
+func (self *poolConn) write(msg interface{}) error {
+	if err := self.conn.SetWriteDeadline(time.Now().Add(self.timeout)); err != nil {
+		return err
+	}
+	return self.enc.Encode(msg)
+}
+
+// poolNotify is a job notification waiting to be sent to a worker.
+type poolNotify struct {
+	w        *poolConn
+	assigned uint64
+	msg      PoolRequest
+}
+
+// PoolServer serves the mining work to remote workers and
+// forwards the valid solutions to the submitters.
+// It implements the same Mine method as the MiningGroup so
+// the MiningMgr uses it in place of the local hashers.
+type PoolServer struct {
+	ctx      context.Context
+	close    context.CancelFunc
+	logger   log.Logger
+	listener net.Listener
+	// writeTimeout is how long to wait for a write to a worker before dropping it.
+	writeTimeout time.Duration
+	// labeled are the worker names with their own label in the share metrics.
+	labeled map[string]bool
+
+	mtx     sync.Mutex
+	seq     uint64
+	jobs    map[string]*poolJob
+	workers map[*poolConn]struct{}
+}
+
+func NewPoolServer(logger log.Logger, ctx context.Context, cfg Config) (*PoolServer, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.Pool.ListenHost, cfg.Pool.ListenPort))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating pool listener")
+	}
+	labeled := make(map[string]bool)
+	for _, name := range cfg.Pool.Workers {
+		labeled[name] = true
+	}
+	ctx, close := context.WithCancel(ctx)
+	return &PoolServer{
+		ctx:          ctx,
+		close:        close,
+		logger:       log.With(logger, "component", ComponentName),
+		listener:     listener,
+		writeTimeout: poolWriteTimeout,
+		labeled:      labeled,
+		jobs:         make(map[string]*poolJob),
+		workers:      make(map[*poolConn]struct{}),
+	}, nil
+}
+
+// Addr returns the address the pool listens on.
+func (self *PoolServer) Addr() net.Addr {
+	return self.listener.Addr()
+}
+
+func (self *PoolServer) Start() error {
+	level.Info(self.logger).Log("msg", "starting mining pool", "addr", self.listener.Addr())
+	for {
+		conn, err := self.listener.Accept()
+		if err != nil {
+			select {
+			case <-self.ctx.Done():
+				return nil
+			default:
+			}
+			return errors.Wrap(err, "accepting pool connection")
+		}
+		go self.serve(conn)
+	}
+}
+
+func (self *PoolServer) Stop() {
+	self.close()
+	if err := self.listener.Close(); err != nil {
+		level.Error(self.logger).Log("msg", "closing pool listener", "err", err)
+	}
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for w := range self.workers {
+		w.conn.Close()
+	}
+}
+
+// Mine publishes every work from the input to the connected workers and
+// sends the valid solutions to the output.
+func (self *PoolServer) Mine(ctx context.Context, input chan *Work, output chan *Result) {
+	var current *poolJob
+	for {
+		select {
+		case <-ctx.Done():
+			self.removeJob(current)
+			return
+		case work := <-input:
+			// The workers of a paused account are spread to the other jobs.
+			self.removeJob(current)
+			current = nil
+			if work != nil {
+				current = self.addJob(work, output)
+			}
+		}
+	}
+}
+
+func (self *PoolServer) addJob(work *Work, output chan *Result) *poolJob {
+	self.mtx.Lock()
+	self.seq++
+	job := &poolJob{
+		id:     strconv.FormatUint(self.seq, 10),
+		seq:    self.seq,
+		work:   work,
+		hash:   NewHashSettings(work.Challenge, work.PublicAddr),
+		output: output,
+	}
+	self.jobs[job.id] = job
+	level.Info(self.logger).Log("msg", "new pool job", "id", job.id, "addr", work.PublicAddr, "workers", len(self.workers))
+	notifies := self.assign()
+	self.mtx.Unlock()
+
+	self.notify(notifies)
+	return job
+}
+
+func (self *PoolServer) removeJob(job *poolJob) {
+	if job == nil {
+		return
+	}
+	self.mtx.Lock()
+	if _, ok := self.jobs[job.id]; !ok {
+		self.mtx.Unlock()
+		return
+	}
+	delete(self.jobs, job.id)
+	notifies := self.assign()
+	self.mtx.Unlock()
+
+	self.notify(notifies)
+}
+
+// assign spreads the workers evenly between all active jobs and
+// returns the notifies for the workers that need to switch to another job.
+// It should be called with the mutex held and
+// the notifies should be sent after the mutex is released.
+func (self *PoolServer) assign() []poolNotify {
+	if len(self.jobs) == 0 || len(self.workers) == 0 {
+		return nil
+	}
+	jobs := make([]*poolJob, 0, len(self.jobs))
+	for _, job := range self.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].seq < jobs[j].seq })
+	workers := make([]*poolConn, 0, len(self.workers))
+	for w := range self.workers {
+		workers = append(workers, w)
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].seq < workers[j].seq })
+
+	var notifies []poolNotify
+	for i, w := range workers {
+		job := jobs[i%len(jobs)]
+		if w.job == job {
+			continue
+		}
+		w.job = job
+		w.assigned++
+		n := uint64(poolWorkerRange)
+		if job.work.N-job.next < n {
+			n = job.work.N - job.next
+		}
+		notify := PoolJob{
+			ID:         job.id,
+			Challenge:  job.work.Challenge.Challenge,
+			Difficulty: job.work.Challenge.Difficulty.String(),
+			Prefix:     job.hash.prefix,
+			PublicAddr: job.work.PublicAddr,
+			Start:      job.work.Start + job.next,
+			N:          n,
+		}
+		job.next += n
+		params, err := json.Marshal([]PoolJob{notify})
+		if err != nil {
+			level.Error(self.logger).Log("msg", "marshaling notify", "err", err)
+			continue
+		}
+		notifies = append(notifies, poolNotify{w: w, assigned: w.assigned, msg: PoolRequest{Method: MethodNotify, Params: params}})
+	}
+	return notifies
+}
+
+// notify sends the notifies to all workers in parallel so that a slow worker doesn't delay the others.
+// A worker that fails to receive its notify is disconnected and
+// its job is given to the other workers.
+func (self *PoolServer) notify(notifies []poolNotify) {
+	var wg sync.WaitGroup
+	for _, n := range notifies {
+		wg.Add(1)
+		go func(n poolNotify) {
+			defer wg.Done()
+			if err := n.w.notify(n.assigned, n.msg); err != nil {
+				level.Error(self.logger).Log("msg", "sending notify, dropping the worker", "worker", n.w.name, "err", err)
+				n.w.conn.Close()
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+func (self *PoolServer) serve(conn net.Conn) {
+	w := &poolConn{conn: conn, enc: json.NewEncoder(conn), name: conn.RemoteAddr().String(), timeout: self.writeTimeout}
+	logger := log.With(self.logger, "remote", conn.RemoteAddr())
+	defer func() {
+		conn.Close()
+		self.mtx.Lock()
+		var notifies []poolNotify
+		if _, ok := self.workers[w]; ok {
+			delete(self.workers, w)
+			poolWorkers.Dec()
+			level.Info(logger).Log("msg", "worker disconnected", "worker", w.name)
+			// Make sure that no job is left without workers.
+			notifies = self.assign()
+		}
+		self.mtx.Unlock()
+		self.notify(notifies)
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var req PoolRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			level.Error(logger).Log("msg", "decoding request", "err", err)
+			return
+		}
+		result, err := self.handle(w, req)
+		resp := PoolResponse{ID: req.ID, Result: result}
+		if err != nil {
+			msg := err.Error()
+			resp.Error = &msg
+		}
+		if err := w.send(resp); err != nil {
+			level.Error(logger).Log("msg", "sending response", "err", err)
+			return
+		}
+		// Send the first job only after the subscribe reply.
+		if req.Method == MethodSubscribe && err == nil {
+			self.mtx.Lock()
+			notifies := self.assign()
+			self.mtx.Unlock()
+			self.notify(notifies)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		level.Debug(logger).Log("msg", "reading from worker", "err", err)
+	}
+}
+
+func (self *PoolServer) handle(w *poolConn, req PoolRequest) (interface{}, error) {
+	switch req.Method {
+	case MethodSubscribe:
+		var params []string
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, errors.Wrap(err, "decoding params")
+		}
+		self.mtx.Lock()
+		defer self.mtx.Unlock()
+		if len(params) > 0 && params[0] != "" {
+			w.name = params[0]
+		}
+		if _, ok := self.workers[w]; !ok {
+			self.seq++
+			w.seq = self.seq
+			self.workers[w] = struct{}{}
+			poolWorkers.Inc()
+			level.Info(self.logger).Log("msg", "worker subscribed", "worker", w.name, "remote", w.conn.RemoteAddr())
+		}
+		return true, nil
+	case MethodSubmit:
+		var params []PoolSubmit
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 1 {
+			return nil, errors.New("invalid submit params")
+		}
+		if err := self.submit(w, params[0]); err != nil {
+			poolShares.With(prometheus.Labels{"worker": self.label(w), "result": "rejected"}).(prometheus.Counter).Inc()
+			level.Warn(self.logger).Log("msg", "rejected solution", "worker", w.name, "job", params[0].JobID, "err", err)
+			return false, err
+		}
+		poolShares.With(prometheus.Labels{"worker": self.label(w), "result": "accepted"}).(prometheus.Counter).Inc()
+		return true, nil
+	default:
+		return nil, errors.Errorf("unknown method:%v", req.Method)
+	}
+}
+
+// label returns the share metrics label of the worker.
+func (self *PoolServer) label(w *poolConn) string {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.labeled[w.name] {
+		return w.name
+	}
+	return poolOtherWorkers
+}
+
+// submit checks the nonce with the reference hash function and
+// forwards it to the submitter of the job account.
+func (self *PoolServer) submit(w *poolConn, sub PoolSubmit) error {
+	self.mtx.Lock()
+	job, ok := self.jobs[sub.JobID]
+	self.mtx.Unlock()
+	if !ok {
+		return errors.New("stale job")
+	}
+	if !ValidNonce(job.hash, sub.Nonce) {
+		return errors.New("invalid solution")
+	}
+
+	self.mtx.Lock()
+	if _, ok := self.jobs[job.id]; !ok {
+		self.mtx.Unlock()
+		return errors.New("stale job")
+	}
+	// The job is done so move the workers to the other jobs.
+	delete(self.jobs, job.id)
+	notifies := self.assign()
+	self.mtx.Unlock()
+	self.notify(notifies)
+
+	level.Info(self.logger).Log("msg", "found solution by a remote worker",
+		"worker", w.name,
+		"addr", job.work.PublicAddr,
+		"challenge", fmt.Sprintf("%x", job.work.Challenge.Challenge),
+		"solution", sub.Nonce,
+	)
+	select {
+	case job.output <- &Result{Work: job.work, Nonce: sub.Nonce}:
+	case <-self.ctx.Done():
+	}
+	return nil
+}
+
+// ValidNonce checks the nonce using the reference hash function.
+func ValidNonce(hash *HashSettings, nonce string) bool {
+	if _, err := strconv.ParseUint(nonce, 10, 64); err != nil {
+		return false
+	}
+	input := make([]byte, 0, len(hash.prefix)+len(nonce))
+	input = append(input, hash.prefix...)
+	input = append(input, nonce...)
+	result, err := hashFn(input)
+	if err != nil {
+		return false
+	}
+	return result.Mod(result, hash.difficulty).Cmp(big.NewInt(0)) == 0
+}
@@ -0,0 +1,200 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+const testAddr = "0xabcd012345678901234567890123456789012345"
+
+func newTestPool(t *testing.T) (*PoolServer, chan *Work, chan *Result) {
+	cfg := Config{LogLevel: "info", NumProcessors: 2, Pool: PoolConfig{ListenHost: "127.0.0.1"}}
+	pool, err := NewPoolServer(logging.NewLogger(), context.Background(), cfg)
+	testutil.Ok(t, err)
+	go func() {
+		testutil.Ok(t, pool.Start())
+	}()
+	t.Cleanup(pool.Stop)
+
+	ctx, cncl := context.WithCancel(context.Background())
+	t.Cleanup(cncl)
+	input := make(chan *Work)
+	output := make(chan *Result)
+	go pool.Mine(ctx, input, output)
+	return pool, input, output
+}
+
+func testWork(difficulty int64) *Work {
+	challenge := &MiningChallenge{
+		Challenge:  make([]byte, 32),
+		Difficulty: big.NewInt(difficulty),
+	}
+	challenge.Challenge[0] = 0x42
+	return &Work{Challenge: challenge, PublicAddr: testAddr, Start: 0, N: math.MaxInt64}
+}
+
+// rawWorker speaks the pool protocol directly.
+type rawWorker struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+func dialRawWorker(t *testing.T, pool *PoolServer) *rawWorker {
+	conn, err := net.Dial("tcp", pool.Addr().String())
+	testutil.Ok(t, err)
+	t.Cleanup(func() { conn.Close() })
+	w := &rawWorker{conn: conn, scanner: bufio.NewScanner(conn)}
+	w.send(t, MethodSubscribe, []string{"raw"})
+	return w
+}
+
+func (self *rawWorker) send(t *testing.T, method string, params interface{}) {
+	p, err := json.Marshal(params)
+	testutil.Ok(t, err)
+	id := uint64(1)
+	testutil.Ok(t, json.NewEncoder(self.conn).Encode(PoolRequest{ID: &id, Method: method, Params: p}))
+}
+
+func (self *rawWorker) read(t *testing.T) *poolMessage {
+	testutil.Ok(t, self.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	testutil.Assert(t, self.scanner.Scan(), "reading from the pool: %v", self.scanner.Err())
+	msg := &poolMessage{}
+	testutil.Ok(t, json.Unmarshal(self.scanner.Bytes(), msg))
+	return msg
+}
+
+// readNotify skips the replies until the next job.
+func (self *rawWorker) readNotify(t *testing.T) *PoolJob {
+	for {
+		msg := self.read(t)
+		if msg.Method != MethodNotify {
+			continue
+		}
+		job, _, err := parseJob(msg.Params)
+		testutil.Ok(t, err)
+		return job
+	}
+}
+
+func TestPoolWorker(t *testing.T) {
+	pool, input, output := newTestPool(t)
+
+	cfg := Config{LogLevel: "info", NumProcessors: 2}
+	worker, err := NewWorker(logging.NewLogger(), context.Background(), cfg, pool.Addr().String(), "test")
+	testutil.Ok(t, err)
+	go func() {
+		testutil.Ok(t, worker.Start())
+	}()
+	defer worker.Stop()
+
+	work := testWork(1000)
+	input <- work
+	select {
+	case result := <-output:
+		testutil.Equals(t, work, result.Work)
+		testutil.Assert(t, ValidNonce(NewHashSettings(work.Challenge, work.PublicAddr), result.Nonce), "the pool should forward only valid solutions")
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for a solution from the worker")
+	}
+}
+
+func TestPoolRanges(t *testing.T) {
+	pool, input, _ := newTestPool(t)
+	w1 := dialRawWorker(t, pool)
+	w2 := dialRawWorker(t, pool)
+
+	input <- testWork(math.MaxInt64)
+	job1 := w1.readNotify(t)
+	job2 := w2.readNotify(t)
+	testutil.Equals(t, job1.ID, job2.ID)
+	testutil.Assert(t, job1.Start+job1.N <= job2.Start || job2.Start+job2.N <= job1.Start, "workers should get disjoint nonce ranges")
+}
+
+func TestPoolRejectsInvalidSolutions(t *testing.T) {
+	pool, input, output := newTestPool(t)
+	w := dialRawWorker(t, pool)
+
+	work := testWork(math.MaxInt64)
+	input <- work
+	job := w.readNotify(t)
+
+	for _, nonce := range []string{"1", "not a number"} {
+		w.send(t, MethodSubmit, []PoolSubmit{{JobID: job.ID, Nonce: nonce}})
+		msg := w.read(t)
+		testutil.Assert(t, msg.Error != nil, "an invalid solution should be rejected")
+	}
+
+	w.send(t, MethodSubmit, []PoolSubmit{{JobID: "unknown", Nonce: "1"}})
+	msg := w.read(t)
+	testutil.Assert(t, msg.Error != nil && *msg.Error == "stale job", "a solution for an unknown job should be rejected")
+
+	select {
+	case result := <-output:
+		t.Fatalf("unexpected result:%v", result.Nonce)
+	default:
+	}
+}
+
+// pipeRawWorker connects a worker through an in-memory pipe which blocks the writes until the worker reads.
+func pipeRawWorker(t *testing.T, pool *PoolServer) *rawWorker {
+	server, conn := net.Pipe()
+	t.Cleanup(func() { conn.Close() })
+	go pool.serve(server)
+	w := &rawWorker{conn: conn, scanner: bufio.NewScanner(conn)}
+	w.send(t, MethodSubscribe, []string{"raw"})
+	testutil.Assert(t, w.read(t).Error == nil, "the subscribe should succeed")
+	return w
+}
+
+func TestPoolDropsStalledWorkers(t *testing.T) {
+	pool, input, _ := newTestPool(t)
+	pool.writeTimeout = 500 * time.Millisecond
+
+	// Doesn't read anything after the subscribe.
+	pipeRawWorker(t, pool)
+	w := pipeRawWorker(t, pool)
+
+	input <- testWork(math.MaxInt64)
+	start := time.Now()
+	w.readNotify(t)
+	testutil.Assert(t, time.Since(start) < pool.writeTimeout, "a stalled worker shouldn't delay the notifies of the other workers")
+
+	for i := 0; ; i++ {
+		pool.mtx.Lock()
+		workers := len(pool.workers)
+		pool.mtx.Unlock()
+		if workers == 1 {
+			break
+		}
+		testutil.Assert(t, i < 50, "the stalled worker should be dropped")
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// The pool isn't blocked by the stalled worker.
+	input <- testWork(math.MaxInt64)
+	w.readNotify(t)
+}
+
+func TestPoolShareLabel(t *testing.T) {
+	cfg := Config{LogLevel: "info", Pool: PoolConfig{ListenHost: "127.0.0.1", Workers: []string{"rig1"}}}
+	pool, err := NewPoolServer(logging.NewLogger(), context.Background(), cfg)
+	testutil.Ok(t, err)
+	defer pool.Stop()
+
+	testutil.Equals(t, "rig1", pool.label(&poolConn{name: "rig1"}))
+	// The names are chosen by the workers so the unknown ones share a label.
+	testutil.Equals(t, poolOtherWorkers, pool.label(&poolConn{name: "rig2"}))
+	testutil.Equals(t, poolOtherWorkers, pool.label(&poolConn{}))
+}
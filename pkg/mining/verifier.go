@@ -0,0 +1,108 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+)
+
+// Reasons for dropping a solution before it reaches the submitter.
+const (
+	DropEmpty     = "empty"
+	DropInvalid   = "invalid"
+	DropStale     = "stale"
+	DropDuplicate = "duplicate"
+)
+
+// anySolutionPeriod is the time after the last new value when the contract accepts any nonce.
+const anySolutionPeriod = 15 * time.Minute
+
+var solutionsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "telliot",
+	Subsystem: ComponentName,
+	Name:      "solutions_dropped_total",
+	Help:      "The number of solutions not sent to the submitter by reason",
+},
+	[]string{"addr", "reason"},
+)
+
+// verifier checks the solutions before these are sent to the submitter so
+// it doesn't cancel pending submits for solutions that would be reverted.
+type verifier struct {
+	logger             log.Logger
+	currentChallenge   func(context.Context) ([]byte, error)
+	timeOfLastNewValue func(context.Context) (time.Time, error)
+	// forwarded holds the challenges with an already forwarded solution.
+	forwarded gcache.Cache
+}
+
+func newVerifier(logger log.Logger, contractInstance *contracts.ITellor) *verifier {
+	return &verifier{
+		logger: logger,
+		currentChallenge: func(ctx context.Context) ([]byte, error) {
+			vars, err := contractInstance.GetNewCurrentVariables(&bind.CallOpts{Context: ctx})
+			if err != nil {
+				return nil, err
+			}
+			return vars.Challenge[:], nil
+		},
+		timeOfLastNewValue: func(ctx context.Context) (time.Time, error) {
+			t, err := contractInstance.GetUintVar(&bind.CallOpts{Context: ctx}, ethereum.Keccak256([]byte("_TIME_OF_LAST_NEW_VALUE")))
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(t.Int64(), 0), nil
+		},
+		forwarded: gcache.New(20).LRU().Build(),
+	}
+}
+
+// verify returns the reason to drop the solution or
+// an empty string when it should be sent to the submitter.
+// When the contract can't be checked the solution is not dropped and
+// the submitter does its own checks before submitting.
+func (self *verifier) verify(ctx context.Context, solution *Result) string {
+	if solution.Nonce == "" {
+		return DropEmpty
+	}
+	logger := log.With(self.logger, "challenge", fmt.Sprintf("%x", solution.Work.Challenge.Challenge), "nonce", solution.Nonce)
+
+	if !ValidNonce(NewHashSettings(solution.Work.Challenge, solution.Work.PublicAddr), solution.Nonce) {
+		last, err := self.timeOfLastNewValue(ctx)
+		if err != nil {
+			level.Error(logger).Log("msg", "getting time of last new value", "err", err)
+		} else if time.Since(last) < anySolutionPeriod {
+			return DropInvalid
+		}
+	}
+
+	current, err := self.currentChallenge(ctx)
+	if err != nil {
+		level.Error(logger).Log("msg", "getting current challenge", "err", err)
+	} else if !bytes.Equal(current, solution.Work.Challenge.Challenge) {
+		return DropStale
+	}
+
+	key := fmt.Sprintf("%x", solution.Work.Challenge.Challenge)
+	if self.forwarded.Has(key) {
+		return DropDuplicate
+	}
+	if err := self.forwarded.Set(key, struct{}{}); err != nil {
+		level.Error(logger).Log("msg", "adding challenge to the cache", "err", errors.Wrap(err, "cache set"))
+	}
+	return ""
+}
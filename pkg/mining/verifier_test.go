@@ -0,0 +1,75 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// findNonce returns the first valid nonce for the work.
+func findNonce(t *testing.T, work *Work) string {
+	hash := NewHashSettings(work.Challenge, work.PublicAddr)
+	for i := uint64(0); i < 1e6; i++ {
+		nonce := strconv.FormatUint(i, 10)
+		if ValidNonce(hash, nonce) {
+			return nonce
+		}
+	}
+	t.Fatal("no valid nonce found")
+	return ""
+}
+
+func TestVerifier(t *testing.T) {
+	work := testWork(100)
+	current := work.Challenge.Challenge
+	lastNewValue := time.Now()
+	var contractErr error
+	v := &verifier{
+		logger: logging.NewLogger(),
+		currentChallenge: func(context.Context) ([]byte, error) {
+			return current, contractErr
+		},
+		timeOfLastNewValue: func(context.Context) (time.Time, error) {
+			return lastNewValue, contractErr
+		},
+		forwarded: gcache.New(20).LRU().Build(),
+	}
+	ctx := context.Background()
+	nonce := findNonce(t, work)
+	invalid := "0"
+	for ValidNonce(NewHashSettings(work.Challenge, work.PublicAddr), invalid) {
+		invalid += "0"
+	}
+
+	testutil.Equals(t, DropEmpty, v.verify(ctx, &Result{Work: work}))
+	testutil.Equals(t, DropInvalid, v.verify(ctx, &Result{Work: work, Nonce: invalid}))
+
+	current = []byte("another challenge")
+	testutil.Equals(t, DropStale, v.verify(ctx, &Result{Work: work, Nonce: nonce}))
+
+	current = work.Challenge.Challenge
+	testutil.Equals(t, "", v.verify(ctx, &Result{Work: work, Nonce: nonce}))
+	testutil.Equals(t, DropDuplicate, v.verify(ctx, &Result{Work: work, Nonce: nonce}), "only the first solution for a challenge should be forwarded")
+
+	// Any nonce is valid after the any solution period and
+	// solutions are not dropped when the contract can't be checked.
+	other := testWork(100)
+	other.Challenge.Challenge[1] = 0x1
+	current = other.Challenge.Challenge
+	lastNewValue = time.Now().Add(-anySolutionPeriod)
+	testutil.Equals(t, "", v.verify(ctx, &Result{Work: other, Nonce: "any"}))
+
+	contractErr = errors.New("node down")
+	other = testWork(100)
+	other.Challenge.Challenge[2] = 0x1
+	testutil.Equals(t, "", v.verify(ctx, &Result{Work: other, Nonce: findNonce(t, other)}))
+}
@@ -0,0 +1,239 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package mining
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+const reconnectDelay = 5 * time.Second
+
+// poolMessage is any message sent by the pool.
+// Notifications have a method and replies have a result or an error.
+type poolMessage struct {
+	ID     *uint64         `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *string         `json:"error"`
+}
+
+// Worker mines the jobs of a remote pool with the local CPU hashers
+// and sends the found solutions back to the pool.
+type Worker struct {
+	ctx      context.Context
+	close    context.CancelFunc
+	logger   log.Logger
+	poolAddr string
+	name     string
+	group    *MiningGroup
+	input    chan *Work
+	output   chan *Result
+	reqID    uint64
+}
+
+func NewWorker(logger log.Logger, ctx context.Context, cfg Config, poolAddr string, name string) (*Worker, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	logger = log.With(logger, "component", ComponentName)
+
+	// No contract instance as the worker doesn't connect to a node.
+	group, err := SetupMiningGroup(logger, ctx, cfg, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "setup MiningGroup")
+	}
+
+	ctx, close := context.WithCancel(ctx)
+	return &Worker{
+		ctx:      ctx,
+		close:    close,
+		logger:   logger,
+		poolAddr: poolAddr,
+		name:     name,
+		group:    group,
+		input:    make(chan *Work),
+		output:   make(chan *Result),
+	}, nil
+}
+
+// Start connects to the pool and reconnects when the connection is lost.
+func (self *Worker) Start() error {
+	level.Info(self.logger).Log("msg", "starting worker", "pool", self.poolAddr, "name", self.name)
+	go self.group.Mine(self.ctx, self.input, self.output)
+
+	for {
+		err := self.run()
+		if self.ctx.Err() != nil {
+			return nil
+		}
+		level.Error(self.logger).Log("msg", "pool connection lost, reconnecting", "err", err)
+		select {
+		case <-self.ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (self *Worker) Stop() {
+	self.close()
+}
+
+func (self *Worker) run() error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(self.ctx, "tcp", self.poolAddr)
+	if err != nil {
+		return errors.Wrap(err, "connecting to the pool")
+	}
+	ctx, cncl := context.WithCancel(self.ctx)
+	defer cncl()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	msgs := make(chan *poolMessage)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			msg := &poolMessage{}
+			if err := json.Unmarshal(scanner.Bytes(), msg); err != nil {
+				readErr <- errors.Wrap(err, "decoding pool message")
+				return
+			}
+			select {
+			case msgs <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			readErr <- errors.Wrap(err, "reading from the pool")
+			return
+		}
+		readErr <- errors.New("connection closed by the pool")
+	}()
+
+	enc := json.NewEncoder(conn)
+	if err := self.request(enc, MethodSubscribe, []string{self.name}); err != nil {
+		return errors.Wrap(err, "subscribing")
+	}
+
+	// The work currently mined and its pool job ID.
+	var current *Work
+	var currentID string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			return err
+		case result := <-self.output:
+			if err := self.submit(enc, result, current, currentID); err != nil {
+				return err
+			}
+		case msg := <-msgs:
+			if msg.Method != MethodNotify {
+				if msg.Error != nil {
+					level.Warn(self.logger).Log("msg", "pool rejected the request", "id", msg.ID, "err", *msg.Error)
+				} else {
+					level.Debug(self.logger).Log("msg", "pool reply", "id", msg.ID, "result", string(msg.Result))
+				}
+				continue
+			}
+			job, work, err := parseJob(msg.Params)
+			if err != nil {
+				return err
+			}
+			level.Info(self.logger).Log("msg", "new job from the pool", "id", job.ID, "addr", job.PublicAddr, "difficulty", job.Difficulty, "start", job.Start)
+			current, currentID = work, job.ID
+			// The mining group might be sending a result so
+			// keep reading the results until it takes the new work.
+			for sent := false; !sent; {
+				select {
+				case <-ctx.Done():
+					return nil
+				case self.input <- work:
+					sent = true
+				case result := <-self.output:
+					if err := self.submit(enc, result, current, currentID); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// submit sends the solution to the pool when it is for the current job.
+func (self *Worker) submit(enc *json.Encoder, result *Result, current *Work, currentID string) error {
+	if result.Nonce == "" {
+		level.Info(self.logger).Log("msg", "nonce range exhausted without a solution")
+		return nil
+	}
+	if result.Work != current {
+		level.Debug(self.logger).Log("msg", "skipping solution for an old job")
+		return nil
+	}
+	level.Info(self.logger).Log("msg", "found solution", "job", currentID, "nonce", result.Nonce)
+	return self.request(enc, MethodSubmit, []PoolSubmit{{JobID: currentID, Nonce: result.Nonce}})
+}
+
+func (self *Worker) request(enc *json.Encoder, method string, params interface{}) error {
+	p, err := json.Marshal(params)
+	if err != nil {
+		return errors.Wrap(err, "marshal params")
+	}
+	self.reqID++
+	id := self.reqID
+	if err := enc.Encode(PoolRequest{ID: &id, Method: method, Params: p}); err != nil {
+		return errors.Wrapf(err, "sending %v", method)
+	}
+	return nil
+}
+
+// parseJob converts a notify to a mining work and
+// checks that the prefix matches the one the local hashers will use.
+func parseJob(params json.RawMessage) (*PoolJob, *Work, error) {
+	var jobs []PoolJob
+	if err := json.Unmarshal(params, &jobs); err != nil || len(jobs) != 1 {
+		return nil, nil, errors.New("invalid notify params")
+	}
+	job := jobs[0]
+	if !common.IsHexAddress(job.PublicAddr) {
+		return nil, nil, errors.Errorf("invalid address:%v", job.PublicAddr)
+	}
+	difficulty, ok := big.NewInt(0).SetString(job.Difficulty, 10)
+	if !ok || difficulty.Sign() <= 0 {
+		return nil, nil, errors.Errorf("invalid difficulty:%v", job.Difficulty)
+	}
+	work := &Work{
+		Challenge: &MiningChallenge{
+			Challenge:  job.Challenge,
+			Difficulty: difficulty,
+		},
+		PublicAddr: job.PublicAddr,
+		Start:      job.Start,
+		N:          job.N,
+	}
+	if !bytes.Equal(NewHashSettings(work.Challenge, work.PublicAddr).prefix, job.Prefix) {
+		return nil, nil, errors.Errorf("the job prefix doesn't match the challenge and address, job:%v", job.ID)
+	}
+	return &job, work, nil
+}
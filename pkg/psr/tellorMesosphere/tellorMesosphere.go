@@ -11,6 +11,7 @@ import (
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/aggregator"
+	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
 )
 
 const (
@@ -51,16 +52,8 @@ func (self *Psr) getValue(reqID int64, ts time.Time) (float64, error) {
 		return val, nil
 	}
 
-	var conf float64
-	switch reqID {
-	case 1:
-		val, conf, err = self.aggregator.MedianAt("ETH/USD", ts)
-	case 2:
-		val, conf, err = self.aggregator.MedianAt("BTC/USD", ts)
-	default:
-		return 0, errors.Errorf("undeclared request ID:%v", reqID)
-	}
-
+	// Mesosphere uses the same data IDs as the Tellor oracle.
+	val, conf, err := psrTellor.Value(self.aggregator, reqID, ts)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,922 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+// Package simulation provides an in-process chain with a stub Tellor contract
+// so that all mining components can run end-to-end in tests.
+package simulation
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	eth "github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/mining"
+)
+
+const (
+	ComponentName = "simulation"
+	// NetworkID is the id of a local hardhat network
+	// so that the contract addresses resolve to the ones of a development chain.
+	NetworkID = 31337
+
+	blockGasLimit = 30_000_000
+	transferGas   = params.TxGas
+	// contractGas is the gas used by every call to the stub contract.
+	// It is close to the gas used by a submitMiningSolution in the Tellor contract.
+	contractGas = 250_000
+)
+
+// GasPrice is the price suggested by the simulated chain.
+var GasPrice = big.NewInt(params.GWei)
+
+// Config is the initial state of the simulated chain.
+type Config struct {
+	// Difficulty of all challenges.
+	Difficulty *big.Int
+	// RequestIDs of all challenges.
+	RequestIDs [5]*big.Int
+	// Accounts are funded with the balance.
+	Accounts []common.Address
+	Balance  *big.Int
+	// Tokens is the TRB balance of the accounts in the Tellor contract.
+	Tokens *big.Int
+	// Stakers are the accounts staked in the Tellor contract.
+	Stakers []common.Address
+	// MinerTimeLimit is the minimum time between the submits of the same miner.
+	// Defaults to the 15 minutes of the Tellor contract.
+	MinerTimeLimit time.Duration
+}
+
+// state is the state of the chain after a block.
+type state struct {
+	nonces   map[common.Address]uint64
+	balances map[common.Address]*big.Int
+	tellor   *tellorState
+}
+
+func (self *state) copy() *state {
+	cpy := &state{
+		nonces:   make(map[common.Address]uint64, len(self.nonces)),
+		balances: make(map[common.Address]*big.Int, len(self.balances)),
+		tellor:   self.tellor.copy(),
+	}
+	for k, v := range self.nonces {
+		cpy.nonces[k] = v
+	}
+	for k, v := range self.balances {
+		cpy.balances[k] = v
+	}
+	return cpy
+}
+
+func (self *state) balance(addr common.Address) *big.Int {
+	if balance, ok := self.balances[addr]; ok {
+		return balance
+	}
+	return big.NewInt(0)
+}
+
+type block struct {
+	header   *types.Header
+	txs      types.Transactions
+	receipts []*types.Receipt
+	state    *state
+}
+
+func (self *block) ethBlock() *types.Block {
+	return types.NewBlockWithHeader(self.header).WithBody(self.txs, nil)
+}
+
+// subscription is ended by the subscriber or when its context is canceled.
+type subscription struct {
+	ctx  context.Context
+	quit chan struct{}
+	err  chan error
+	once sync.Once
+}
+
+func newSubscription(ctx context.Context) *subscription {
+	return &subscription{
+		ctx:  ctx,
+		quit: make(chan struct{}),
+		err:  make(chan error),
+	}
+}
+
+func (self *subscription) Unsubscribe() {
+	self.once.Do(func() {
+		close(self.quit)
+		close(self.err)
+	})
+}
+
+func (self *subscription) Err() <-chan error {
+	return self.err
+}
+
+func (self *subscription) done() bool {
+	select {
+	case <-self.quit:
+		return true
+	case <-self.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+type logSubscription struct {
+	*subscription
+	query ethereum.FilterQuery
+	ch    chan<- types.Log
+}
+
+type headSubscription struct {
+	*subscription
+	ch chan<- *types.Header
+}
+
+// notification is a head or a log waiting to be sent to a subscriber.
+type notification struct {
+	sub  *subscription
+	send func()
+}
+
+// Backend is an in-memory chain that implements the ethereum client interface.
+// Transactions are mined only when calling Commit and
+// Reorg replaces the blocks after a given ancestor.
+// The Tellor contract is a stub at the address for the hardhat network and
+// all other addresses are plain accounts.
+type Backend struct {
+	// notifyMtx keeps the order of the notifications when
+	// the chain is modified from multiple goroutines.
+	notifyMtx sync.Mutex
+	mtx       sync.Mutex
+	logger    log.Logger
+	chainID   *big.Int
+	signer    types.Signer
+	tellor    *tellorStub
+	// blocks is the canonical chain.
+	blocks []*block
+	// byHash has all blocks including the ones that were reorged out.
+	byHash    map[common.Hash]*block
+	pending   types.Transactions
+	timeShift time.Duration
+	forks     uint64
+	headSubs  []*headSubscription
+	logSubs   []*logSubscription
+}
+
+func NewBackend(logger log.Logger, cfg Config) (*Backend, error) {
+	if cfg.MinerTimeLimit == 0 {
+		cfg.MinerTimeLimit = defaultMinerTimeLimit
+	}
+	tellor, err := newTellorStub(common.HexToAddress(contracts.TellorAddressHardhat), cfg.MinerTimeLimit)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Difficulty == nil || cfg.Difficulty.Sign() <= 0 {
+		return nil, errors.New("the difficulty should be greater than 0")
+	}
+	for i, id := range cfg.RequestIDs {
+		if id == nil {
+			return nil, errors.Errorf("missing request ID at index:%v", i)
+		}
+	}
+
+	genesisTime := uint64(time.Now().Unix())
+	st := &state{
+		nonces:   make(map[common.Address]uint64),
+		balances: make(map[common.Address]*big.Int),
+		tellor: &tellorState{
+			requestIDs:         cfg.RequestIDs,
+			difficulty:         cfg.Difficulty,
+			timeOfLastNewValue: genesisTime,
+			lastSubmit:         make(map[common.Address]uint64),
+			stakers:            make(map[common.Address]int64),
+			balances:           make(map[common.Address]*big.Int),
+		},
+	}
+	st.tellor.challenge = crypto.Keccak256Hash([]byte("simulation genesis"))
+	for _, addr := range cfg.Accounts {
+		if cfg.Balance != nil {
+			st.balances[addr] = cfg.Balance
+		}
+		if cfg.Tokens != nil {
+			st.tellor.balances[addr] = cfg.Tokens
+		}
+	}
+	for _, addr := range cfg.Stakers {
+		st.tellor.stakers[addr] = stakerStatusStaked
+	}
+
+	genesis := &block{
+		header: &types.Header{
+			Number:     big.NewInt(0),
+			Time:       genesisTime,
+			Difficulty: big.NewInt(1),
+			GasLimit:   blockGasLimit,
+		},
+		state: st,
+	}
+	chainID := big.NewInt(NetworkID)
+	return &Backend{
+		logger:  log.With(logger, "component", ComponentName),
+		chainID: chainID,
+		signer:  types.LatestSignerForChainID(chainID),
+		tellor:  tellor,
+		blocks:  []*block{genesis},
+		byHash:  map[common.Hash]*block{genesis.header.Hash(): genesis},
+	}, nil
+}
+
+// TellorAddress is the address of the stub Tellor contract.
+func (self *Backend) TellorAddress() common.Address {
+	return self.tellor.address
+}
+
+// AdjustTime moves the time of the following blocks forward.
+func (self *Backend) AdjustTime(d time.Duration) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.timeShift += d
+}
+
+// Now returns the time of the chain including the adjustments.
+func (self *Backend) Now() time.Time {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return time.Now().Add(self.timeShift)
+}
+
+// NewValues returns all values recorded in the Tellor contract on the canonical chain.
+func (self *Backend) NewValues() []NewValue {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return append([]NewValue(nil), self.head().state.tellor.newValues...)
+}
+
+// CurrentChallenge returns the current challenge in the Tellor contract on the canonical chain.
+func (self *Backend) CurrentChallenge() [32]byte {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.head().state.tellor.challenge
+}
+
+// Pending returns the number of transactions waiting to be mined.
+func (self *Backend) Pending() int {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return len(self.pending)
+}
+
+// Commit mines a new block with all executable pending transactions and
+// sends the new head and its logs to the subscribers.
+func (self *Backend) Commit() *types.Header {
+	self.notifyMtx.Lock()
+	defer self.notifyMtx.Unlock()
+
+	self.mtx.Lock()
+	b := self.mine()
+	var notifications []notification
+	for _, r := range b.receipts {
+		for _, l := range r.Logs {
+			notifications = append(notifications, self.logNotifications(*l)...)
+		}
+	}
+	notifications = append(notifications, self.headNotifications(b.header)...)
+	self.mtx.Unlock()
+
+	level.Debug(self.logger).Log("msg", "new block", "number", b.header.Number, "hash", b.header.Hash(), "txs", len(b.txs))
+	notify(notifications)
+	return types.CopyHeader(b.header)
+}
+
+// Reorg drops all blocks after the ancestor from the canonical chain and
+// sends their logs to the subscribers again with Removed set.
+// Same as in a real node the transactions of the dropped blocks return to the pending pool and
+// the following commits build a new chain on top of the ancestor.
+// The new head is sent to the subscribers with the next commit.
+func (self *Backend) Reorg(ancestor uint64) error {
+	self.notifyMtx.Lock()
+	defer self.notifyMtx.Unlock()
+
+	self.mtx.Lock()
+	head := self.head().header.Number.Uint64()
+	if ancestor >= head {
+		self.mtx.Unlock()
+		return errors.Errorf("the reorg ancestor:%v should be lower than the head:%v", ancestor, head)
+	}
+	dropped := self.blocks[ancestor+1:]
+	self.blocks = self.blocks[:ancestor+1]
+	self.forks++
+
+	var reinject types.Transactions
+	var notifications []notification
+	// Same as a real node send the removed logs starting from the newest.
+	for i := len(dropped) - 1; i >= 0; i-- {
+		for j := len(dropped[i].receipts) - 1; j >= 0; j-- {
+			logs := dropped[i].receipts[j].Logs
+			for k := len(logs) - 1; k >= 0; k-- {
+				l := *logs[k]
+				l.Removed = true
+				notifications = append(notifications, self.logNotifications(l)...)
+			}
+		}
+	}
+	for _, b := range dropped {
+		reinject = append(reinject, b.txs...)
+	}
+	self.pending = append(reinject, self.pending...)
+	self.mtx.Unlock()
+
+	level.Info(self.logger).Log("msg", "chain reorg", "ancestor", ancestor, "dropped", len(dropped), "reinjected", len(reinject))
+	notify(notifications)
+	return nil
+}
+
+func notify(notifications []notification) {
+	for _, n := range notifications {
+		if !n.sub.done() {
+			n.send()
+		}
+	}
+}
+
+func (self *Backend) head() *block {
+	return self.blocks[len(self.blocks)-1]
+}
+
+// mine executes the pending transactions in a new block and adds it to the canonical chain.
+// Transactions with a nonce gap stay in the pool and the ones with an already used nonce are dropped.
+func (self *Backend) mine() *block {
+	parent := self.head()
+	extra := make([]byte, 8)
+	binary.BigEndian.PutUint64(extra, self.forks)
+	header := &types.Header{
+		ParentHash: parent.header.Hash(),
+		Number:     new(big.Int).Add(parent.header.Number, big.NewInt(1)),
+		Time:       uint64(time.Now().Add(self.timeShift).Unix()),
+		Difficulty: big.NewInt(1),
+		GasLimit:   blockGasLimit,
+		Extra:      extra,
+	}
+	if header.Time <= parent.header.Time {
+		header.Time = parent.header.Time + 1
+	}
+	b := &block{header: header, state: parent.state.copy()}
+
+	for executed := true; executed; {
+		executed = false
+		pending := self.pending[:0]
+		for _, tx := range self.pending {
+			from, err := types.Sender(self.signer, tx)
+			if err != nil {
+				continue
+			}
+			nonce := b.state.nonces[from]
+			if tx.Nonce() > nonce {
+				pending = append(pending, tx)
+				continue
+			}
+			if tx.Nonce() < nonce {
+				continue
+			}
+			receipt, err := self.apply(b, tx, from)
+			if err != nil {
+				level.Warn(self.logger).Log("msg", "dropping transaction", "tx", tx.Hash(), "err", err)
+				continue
+			}
+			b.txs = append(b.txs, tx)
+			b.receipts = append(b.receipts, receipt)
+			executed = true
+		}
+		self.pending = pending
+	}
+
+	// All fields that change the hash are set so
+	// the receipts and logs can reference the block.
+	hash := header.Hash()
+	var logIndex uint
+	for _, r := range b.receipts {
+		r.BlockHash = hash
+		for _, l := range r.Logs {
+			l.BlockHash = hash
+			l.Index = logIndex
+			logIndex++
+		}
+	}
+	self.blocks = append(self.blocks, b)
+	self.byHash[hash] = b
+	return b
+}
+
+// apply executes the transaction and updates the block state.
+// It returns an error when the transaction can't be included in the block.
+func (self *Backend) apply(b *block, tx *types.Transaction, from common.Address) (*types.Receipt, error) {
+	st := b.state
+	maxCost := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+	maxCost.Add(maxCost, tx.Value())
+	if st.balance(from).Cmp(maxCost) < 0 {
+		return nil, errors.Errorf("insufficient funds for gas * price + value: address %v have %v want %v", from.Hex(), st.balance(from), maxCost)
+	}
+	st.nonces[from]++
+
+	number := b.header.Number.Uint64()
+	receipt := &types.Receipt{
+		Type:             tx.Type(),
+		Status:           types.ReceiptStatusSuccessful,
+		TxHash:           tx.Hash(),
+		BlockNumber:      b.header.Number,
+		TransactionIndex: uint(len(b.txs)),
+	}
+	receipt.GasUsed = transferGas
+	if tx.To() == nil || *tx.To() == self.tellor.address {
+		receipt.GasUsed = contractGas
+	}
+	if receipt.GasUsed > tx.Gas() {
+		receipt.GasUsed = tx.Gas()
+		receipt.Status = types.ReceiptStatusFailed
+	}
+
+	switch {
+	case receipt.Status == types.ReceiptStatusFailed:
+	case tx.To() == nil:
+		// Deploying contracts is not supported.
+		receipt.Status = types.ReceiptStatusFailed
+	case *tx.To() == self.tellor.address:
+		tellorState := st.tellor.copy()
+		env := &env{
+			from:       from,
+			number:     number,
+			time:       b.header.Time,
+			parentHash: b.header.ParentHash,
+		}
+		if _, err := self.tellor.call(tellorState, env, tx.Data()); err != nil {
+			level.Debug(self.logger).Log("msg", "transaction reverted", "tx", tx.Hash(), "err", err)
+			receipt.Status = types.ReceiptStatusFailed
+			break
+		}
+		st.tellor = tellorState
+		for _, l := range env.logs {
+			l.BlockNumber = number
+			l.TxHash = tx.Hash()
+			l.TxIndex = receipt.TransactionIndex
+			receipt.Logs = append(receipt.Logs, l)
+		}
+	default:
+		st.balances[from] = new(big.Int).Sub(st.balance(from), tx.Value())
+		st.balances[*tx.To()] = new(big.Int).Add(st.balance(*tx.To()), tx.Value())
+	}
+
+	fee := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(receipt.GasUsed))
+	st.balances[from] = new(big.Int).Sub(st.balance(from), fee)
+
+	b.header.GasUsed += receipt.GasUsed
+	receipt.CumulativeGasUsed = b.header.GasUsed
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	return receipt, nil
+}
+
+func (self *Backend) logNotifications(l types.Log) []notification {
+	var notifications []notification
+	subs := self.logSubs[:0]
+	for _, sub := range self.logSubs {
+		if sub.done() {
+			continue
+		}
+		subs = append(subs, sub)
+		if !filterLog(sub.query, &l) {
+			continue
+		}
+		sub := sub
+		notifications = append(notifications, notification{
+			sub: sub.subscription,
+			send: func() {
+				select {
+				case sub.ch <- l:
+				case <-sub.quit:
+				case <-sub.ctx.Done():
+				}
+			},
+		})
+	}
+	self.logSubs = subs
+	return notifications
+}
+
+func (self *Backend) headNotifications(header *types.Header) []notification {
+	var notifications []notification
+	subs := self.headSubs[:0]
+	for _, sub := range self.headSubs {
+		if sub.done() {
+			continue
+		}
+		subs = append(subs, sub)
+		sub, header := sub, types.CopyHeader(header)
+		notifications = append(notifications, notification{
+			sub: sub.subscription,
+			send: func() {
+				select {
+				case sub.ch <- header:
+				case <-sub.quit:
+				case <-sub.ctx.Done():
+				}
+			},
+		})
+	}
+	self.headSubs = subs
+	return notifications
+}
+
+func filterLog(q ethereum.FilterQuery, l *types.Log) bool {
+	if len(q.Addresses) > 0 {
+		var found bool
+		for _, addr := range q.Addresses {
+			found = found || addr == l.Address
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.Topics) > len(l.Topics) {
+		return false
+	}
+	for i, topics := range q.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		var found bool
+		for _, topic := range topics {
+			found = found || topic == l.Topics[i]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// blockAt returns the canonical block at the given number or the head when the number is nil.
+func (self *Backend) blockAt(number *big.Int) (*block, error) {
+	if number == nil {
+		return self.head(), nil
+	}
+	if !number.IsUint64() || number.Uint64() >= uint64(len(self.blocks)) {
+		return nil, ethereum.NotFound
+	}
+	return self.blocks[number.Uint64()], nil
+}
+
+// canonical returns the block with the hash when it is part of the canonical chain.
+func (self *Backend) canonical(hash common.Hash) (*block, bool) {
+	b, ok := self.byHash[hash]
+	if !ok {
+		return nil, false
+	}
+	n := b.header.Number.Uint64()
+	return b, n < uint64(len(self.blocks)) && self.blocks[n] == b
+}
+
+func (self *Backend) NetworkID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(self.chainID), nil
+}
+
+func (self *Backend) BlockNumber(ctx context.Context) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.head().header.Number.Uint64(), nil
+}
+
+func (self *Backend) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	return nil, nil
+}
+
+func (self *Backend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, ok := self.byHash[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return b.ethBlock(), nil
+}
+
+func (self *Backend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, err := self.blockAt(number)
+	if err != nil {
+		return nil, err
+	}
+	return b.ethBlock(), nil
+}
+
+func (self *Backend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, ok := self.byHash[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return types.CopyHeader(b.header), nil
+}
+
+func (self *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, err := self.blockAt(number)
+	if err != nil {
+		return nil, err
+	}
+	return types.CopyHeader(b.header), nil
+}
+
+func (self *Backend) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, ok := self.byHash[blockHash]
+	if !ok {
+		return 0, ethereum.NotFound
+	}
+	return uint(len(b.txs)), nil
+}
+
+func (self *Backend) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (*types.Transaction, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, ok := self.byHash[blockHash]
+	if !ok || index >= uint(len(b.txs)) {
+		return nil, ethereum.NotFound
+	}
+	return b.txs[index], nil
+}
+
+func (self *Backend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for _, tx := range self.pending {
+		if tx.Hash() == hash {
+			return tx, true, nil
+		}
+	}
+	for _, b := range self.blocks {
+		for _, tx := range b.txs {
+			if tx.Hash() == hash {
+				return tx, false, nil
+			}
+		}
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func (self *Backend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for _, b := range self.blocks {
+		for _, r := range b.receipts {
+			if r.TxHash == hash {
+				cpy := *r
+				return &cpy, nil
+			}
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (self *Backend) BalanceAt(ctx context.Context, account common.Address, number *big.Int) (*big.Int, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, err := self.blockAt(number)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(b.state.balance(account)), nil
+}
+
+func (self *Backend) StorageAt(ctx context.Context, account common.Address, key common.Hash, number *big.Int) ([]byte, error) {
+	return nil, errors.New("storage access is not supported by the simulation")
+}
+
+func (self *Backend) CodeAt(ctx context.Context, account common.Address, number *big.Int) ([]byte, error) {
+	if account == self.tellor.address {
+		// Any code so that the contract bindings know that the contract exists.
+		return []byte{0x1}, nil
+	}
+	return nil, nil
+}
+
+func (self *Backend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return self.CodeAt(ctx, account, nil)
+}
+
+func (self *Backend) NonceAt(ctx context.Context, account common.Address, number *big.Int) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, err := self.blockAt(number)
+	if err != nil {
+		return 0, err
+	}
+	return b.state.nonces[account], nil
+}
+
+func (self *Backend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	nonce := self.head().state.nonces[account]
+	for found := true; found; {
+		found = false
+		for _, tx := range self.pending {
+			if from, err := types.Sender(self.signer, tx); err == nil && from == account && tx.Nonce() == nonce {
+				nonce++
+				found = true
+			}
+		}
+	}
+	return nonce, nil
+}
+
+func (self *Backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(GasPrice), nil
+}
+
+// CallContract executes the call on a copy of the state at the given block.
+func (self *Backend) CallContract(ctx context.Context, call ethereum.CallMsg, number *big.Int) ([]byte, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	b, err := self.blockAt(number)
+	if err != nil {
+		return nil, err
+	}
+	return self.call(b, call)
+}
+
+func (self *Backend) call(b *block, call ethereum.CallMsg) ([]byte, error) {
+	if call.To == nil || *call.To != self.tellor.address {
+		return nil, nil
+	}
+	return self.tellor.call(b.state.tellor.copy(), &env{
+		from:       call.From,
+		number:     b.header.Number.Uint64() + 1,
+		time:       uint64(time.Now().Add(self.timeShift).Unix()),
+		parentHash: b.header.Hash(),
+	}, call.Data)
+}
+
+// EstimateGas returns the fixed gas usage of the simulated calls
+// or an error when the call would revert.
+func (self *Backend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if call.To == nil || *call.To != self.tellor.address {
+		return transferGas, nil
+	}
+	if _, err := self.call(self.head(), call); err != nil {
+		return 0, err
+	}
+	return contractGas, nil
+}
+
+// SendTransaction adds the transaction to the pending pool.
+// Same as a real node it rejects transactions with an already used nonce and
+// replaces a pending transaction with the same nonce only when the new one has a higher gas price.
+func (self *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	from, err := types.Sender(self.signer, tx)
+	if err != nil {
+		return errors.Wrap(err, "invalid transaction signature")
+	}
+	if tx.Nonce() < self.head().state.nonces[from] {
+		return errors.New("nonce too low")
+	}
+	maxCost := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+	maxCost.Add(maxCost, tx.Value())
+	if self.head().state.balance(from).Cmp(maxCost) < 0 {
+		return errors.New("insufficient funds for gas * price + value")
+	}
+	for i, p := range self.pending {
+		if pFrom, err := types.Sender(self.signer, p); err != nil || pFrom != from || p.Nonce() != tx.Nonce() {
+			continue
+		}
+		if tx.GasPrice().Cmp(p.GasPrice()) <= 0 {
+			return errors.New("replacement transaction underpriced")
+		}
+		self.pending[i] = tx
+		return nil
+	}
+	self.pending = append(self.pending, tx)
+	return nil
+}
+
+// FilterLogs returns the logs from the canonical chain.
+func (self *Backend) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	var blocks []*block
+	if q.BlockHash != nil {
+		b, ok := self.canonical(*q.BlockHash)
+		if !ok {
+			return nil, ethereum.NotFound
+		}
+		blocks = append(blocks, b)
+	} else {
+		from, to := uint64(0), self.head().header.Number.Uint64()
+		if q.FromBlock != nil {
+			from = q.FromBlock.Uint64()
+		}
+		if q.ToBlock != nil && q.ToBlock.Uint64() < to {
+			to = q.ToBlock.Uint64()
+		}
+		for n := from; n <= to; n++ {
+			blocks = append(blocks, self.blocks[n])
+		}
+	}
+
+	var logs []types.Log
+	for _, b := range blocks {
+		for _, r := range b.receipts {
+			for _, l := range r.Logs {
+				if filterLog(q, l) {
+					logs = append(logs, *l)
+				}
+			}
+		}
+	}
+	return logs, nil
+}
+
+func (self *Backend) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	sub := &logSubscription{subscription: newSubscription(ctx), query: q, ch: ch}
+	self.logSubs = append(self.logSubs, sub)
+	return sub, nil
+}
+
+func (self *Backend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	sub := &headSubscription{subscription: newSubscription(ctx), ch: ch}
+	self.headSubs = append(self.headSubs, sub)
+	return sub, nil
+}
+
+// SubmitValue sends a solution with a valid nonce for the current challenge from every account
+// with the values of its slot and mines these in a new block so that the value is recorded.
+// The accounts should be staked and able to submit.
+func (self *Backend) SubmitValue(accounts [slots]*eth.Account, values [slots][5]*big.Int) (*types.Header, error) {
+	contract, err := contracts.NewITellor(self)
+	if err != nil {
+		return nil, errors.Wrap(err, "create tellor contract instance")
+	}
+	vars, err := contract.GetNewCurrentVariables(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the current challenge")
+	}
+	for i, acc := range accounts {
+		hash := mining.NewHashSettings(&mining.MiningChallenge{
+			Challenge:  vars.Challenge[:],
+			Difficulty: vars.Difficutly,
+		}, acc.Address.Hex())
+		nonce := 0
+		for !mining.ValidNonce(hash, strconv.Itoa(nonce)) {
+			nonce++
+		}
+		opts := acc.NewTransactor(self.chainID)
+		opts.GasLimit = contractGas
+		if _, err := contract.SubmitMiningSolution(opts, strconv.Itoa(nonce), vars.RequestIds, values[i]); err != nil {
+			return nil, errors.Wrapf(err, "submitting the solution of slot:%v", i)
+		}
+	}
+	return self.Commit(), nil
+}
+
+// NewAccounts creates accounts with new private keys.
+func NewAccounts(n int) ([]*eth.Account, error) {
+	var accounts []*eth.Account
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "generating private key")
+		}
+		accounts = append(accounts, &eth.Account{
+			Address: crypto.PubkeyToAddress(key.PublicKey),
+			Signer:  eth.NewKeySigner(key),
+		})
+	}
+	return accounts, nil
+}
@@ -0,0 +1,319 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package simulation
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	eth "github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/mining"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+var testRequestIDs = [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}
+
+func newTestBackend(t *testing.T, accounts []*eth.Account, stakers int) (*Backend, *contracts.ITellor) {
+	cfg := Config{
+		Difficulty: big.NewInt(100),
+		RequestIDs: testRequestIDs,
+		Balance:    big.NewInt(1e18),
+	}
+	for i, acc := range accounts {
+		cfg.Accounts = append(cfg.Accounts, acc.Address)
+		if i < stakers {
+			cfg.Stakers = append(cfg.Stakers, acc.Address)
+		}
+	}
+	backend, err := NewBackend(logging.NewLogger(), cfg)
+	testutil.Ok(t, err)
+	contract, err := contracts.NewITellor(backend)
+	testutil.Ok(t, err)
+	testutil.Equals(t, backend.TellorAddress(), contract.Address)
+	return backend, contract
+}
+
+// submit sends a solution with a valid nonce for the current challenge.
+func submit(t *testing.T, contract *contracts.ITellor, acc *eth.Account) *types.Transaction {
+	vars, err := contract.GetNewCurrentVariables(nil)
+	testutil.Ok(t, err)
+	hash := mining.NewHashSettings(&mining.MiningChallenge{
+		Challenge:  vars.Challenge[:],
+		Difficulty: vars.Difficutly,
+	}, acc.Address.Hex())
+	nonce := ""
+	for i := 0; nonce == ""; i++ {
+		if mining.ValidNonce(hash, strconv.Itoa(i)) {
+			nonce = strconv.Itoa(i)
+		}
+	}
+	return submitNonce(t, contract, acc, nonce)
+}
+
+func submitNonce(t *testing.T, contract *contracts.ITellor, acc *eth.Account, nonce string) *types.Transaction {
+	values := [5]*big.Int{big.NewInt(10), big.NewInt(20), big.NewInt(30), big.NewInt(40), big.NewInt(50)}
+	opts := acc.NewTransactor(big.NewInt(NetworkID))
+	// Skip the gas estimation so that the reverted transactions are also mined.
+	opts.GasLimit = 3_000_000
+	tx, err := contract.SubmitMiningSolution(opts, nonce, testRequestIDs, values)
+	testutil.Ok(t, err)
+	return tx
+}
+
+func receiptStatus(t *testing.T, backend *Backend, tx *types.Transaction) uint64 {
+	receipt, err := backend.TransactionReceipt(context.Background(), tx.Hash())
+	testutil.Ok(t, err)
+	return receipt.Status
+}
+
+func TestTellorStub(t *testing.T) {
+	accounts, err := NewAccounts(6)
+	testutil.Ok(t, err)
+	backend, contract := newTestBackend(t, accounts, 5)
+	initial := backend.CurrentChallenge()
+
+	// Solutions are rejected for invalid nonces, unstaked miners and duplicate submits.
+	invalid := "0"
+	for mining.ValidNonce(mining.NewHashSettings(&mining.MiningChallenge{Challenge: initial[:], Difficulty: big.NewInt(100)}, accounts[0].Address.Hex()), invalid) {
+		invalid += "0"
+	}
+	txInvalid := submitNonce(t, contract, accounts[0], invalid)
+	txUnstaked := submit(t, contract, accounts[5])
+	backend.Commit()
+	testutil.Equals(t, types.ReceiptStatusFailed, receiptStatus(t, backend, txInvalid))
+	testutil.Equals(t, types.ReceiptStatusFailed, receiptStatus(t, backend, txUnstaked))
+
+	var txs []*types.Transaction
+	for i, acc := range accounts[:4] {
+		txs = append(txs, submit(t, contract, acc))
+		backend.Commit()
+		slot, err := contract.GetUintVar(nil, eth.Keccak256([]byte("_SLOT_PROGRESS")))
+		testutil.Ok(t, err)
+		testutil.Equals(t, int64(i+1), slot.Int64())
+	}
+	txDuplicate := submit(t, contract, accounts[0])
+	backend.Commit()
+	testutil.Equals(t, types.ReceiptStatusFailed, receiptStatus(t, backend, txDuplicate))
+
+	logs := make(chan types.Log, 10)
+	sub, err := backend.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, logs)
+	testutil.Ok(t, err)
+	defer sub.Unsubscribe()
+
+	txs = append(txs, submit(t, contract, accounts[4]))
+	header := backend.Commit()
+	for _, tx := range txs {
+		testutil.Equals(t, types.ReceiptStatusSuccessful, receiptStatus(t, backend, tx))
+	}
+
+	// The last slot records the value and starts a new challenge.
+	values := backend.NewValues()
+	testutil.Equals(t, 1, len(values))
+	testutil.Equals(t, initial, values[0].Challenge)
+	testutil.Equals(t, header.Number.Uint64(), values[0].Block)
+	for i, acc := range accounts[:5] {
+		testutil.Equals(t, acc.Address, values[0].Miners[i])
+	}
+	slot, err := contract.GetUintVar(nil, eth.Keccak256([]byte("_SLOT_PROGRESS")))
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(0), slot.Int64())
+	vars, err := contract.GetNewCurrentVariables(nil)
+	testutil.Ok(t, err)
+	testutil.Assert(t, vars.Challenge != initial, "the challenge should change after all slots are filled")
+
+	// NonceSubmitted, Transferred and NewChallenge.
+	var received []types.Log
+	for len(received) < 3 {
+		select {
+		case l := <-logs:
+			received = append(received, l)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for the logs")
+		}
+	}
+	event, err := contract.ParseNewChallenge(received[2])
+	testutil.Ok(t, err)
+	testutil.Equals(t, vars.Challenge, event.CurrentChallenge)
+	testutil.Equals(t, header.Hash(), event.Raw.BlockHash)
+
+	// A miner can submit only once per 15 minutes.
+	txEarly := submit(t, contract, accounts[0])
+	backend.Commit()
+	testutil.Equals(t, types.ReceiptStatusFailed, receiptStatus(t, backend, txEarly))
+	// The block times might be ahead of the clock as every block is at least a second after its parent.
+	backend.AdjustTime(16 * time.Minute)
+	txLate := submit(t, contract, accounts[0])
+	backend.Commit()
+	testutil.Equals(t, types.ReceiptStatusSuccessful, receiptStatus(t, backend, txLate))
+}
+
+func TestReorg(t *testing.T) {
+	accounts, err := NewAccounts(5)
+	testutil.Ok(t, err)
+	backend, contract := newTestBackend(t, accounts, len(accounts))
+	ctx := context.Background()
+
+	for _, acc := range accounts[:4] {
+		submit(t, contract, acc)
+	}
+	ancestor := backend.Commit()
+	submit(t, contract, accounts[4])
+	reorged := backend.Commit()
+	testutil.Equals(t, 1, len(backend.NewValues()))
+	challenge := backend.CurrentChallenge()
+
+	logs := make(chan types.Log, 10)
+	logsSub, err := backend.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{backend.TellorAddress()}}, logs)
+	testutil.Ok(t, err)
+	defer logsSub.Unsubscribe()
+	heads := make(chan *types.Header, 10)
+	headsSub, err := backend.SubscribeNewHead(ctx, heads)
+	testutil.Ok(t, err)
+	defer headsSub.Unsubscribe()
+
+	// The logs of the dropped block are sent again as removed and the state is reverted.
+	testutil.Ok(t, backend.Reorg(ancestor.Number.Uint64()))
+	for i := 0; i < 3; i++ {
+		l := <-logs
+		testutil.Assert(t, l.Removed, "the log should be removed")
+		testutil.Equals(t, reorged.Hash(), l.BlockHash)
+	}
+	testutil.Equals(t, 0, len(backend.NewValues()))
+	testutil.Equals(t, 1, backend.Pending())
+	head, err := backend.HeaderByNumber(ctx, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, ancestor.Hash(), head.Hash())
+
+	// The next commit includes the transaction again in a new block.
+	header := backend.Commit()
+	testutil.Assert(t, header.Hash() != reorged.Hash(), "the new block should have a different hash")
+	testutil.Equals(t, header.Hash(), (<-heads).Hash())
+	testutil.Equals(t, 1, len(backend.NewValues()))
+	testutil.Equals(t, header.Number.Uint64(), backend.NewValues()[0].Block)
+	testutil.Equals(t, challenge, backend.CurrentChallenge(), "same parent block so the same challenge")
+
+	l := <-logs
+	testutil.Assert(t, !l.Removed, "the log should not be removed")
+	testutil.Equals(t, header.Hash(), l.BlockHash)
+
+	logsInBlock, err := backend.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: header.Number})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(logsInBlock))
+	_, err = backend.FilterLogs(ctx, ethereum.FilterQuery{BlockHash: &[]common.Hash{reorged.Hash()}[0]})
+	testutil.Equals(t, ethereum.NotFound, err)
+}
+
+func TestDispute(t *testing.T) {
+	accounts, err := NewAccounts(7)
+	testutil.Ok(t, err)
+	cfg := Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: testRequestIDs,
+		Balance:    big.NewInt(1e18),
+		Tokens:     new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+	}
+	var miners [slots]*eth.Account
+	for i, acc := range accounts {
+		cfg.Accounts = append(cfg.Accounts, acc.Address)
+		if i < slots {
+			cfg.Stakers = append(cfg.Stakers, acc.Address)
+			miners[i] = acc
+		}
+	}
+	reporter, voter := accounts[5], accounts[6]
+	backend, err := NewBackend(logging.NewLogger(), cfg)
+	testutil.Ok(t, err)
+	contract, err := contracts.NewITellor(backend)
+	testutil.Ok(t, err)
+
+	var values [slots][5]*big.Int
+	for i := range values {
+		for j := range values[i] {
+			values[i][j] = big.NewInt(int64(50 - 10*i))
+		}
+	}
+	header, err := backend.SubmitValue(miners, values)
+	testutil.Ok(t, err)
+
+	// Same as the contract the value time is rounded down to the minute.
+	value := backend.NewValues()[0]
+	testutil.Equals(t, header.Time-header.Time%60, value.Time)
+	timestamp := new(big.Int).SetUint64(value.Time)
+	lastValue, err := contract.GetUintVar(nil, eth.Keccak256([]byte("_TIME_OF_LAST_NEW_VALUE")))
+	testutil.Ok(t, err)
+	testutil.Equals(t, timestamp, lastValue)
+
+	// The miners are sorted by value so the last miner is the first one.
+	sorted, err := contract.GetMinersByRequestIdAndTimestamp(nil, big.NewInt(1), timestamp)
+	testutil.Ok(t, err)
+	for i := range sorted {
+		testutil.Equals(t, miners[slots-1-i].Address, sorted[i])
+	}
+
+	opts := func(acc *eth.Account) *bind.TransactOpts {
+		opts := acc.NewTransactor(big.NewInt(NetworkID))
+		opts.GasLimit = contractGas
+		return opts
+	}
+	tx, err := contract.BeginDispute(opts(reporter), big.NewInt(1), timestamp, big.NewInt(0))
+	testutil.Ok(t, err)
+	backend.Commit()
+	testutil.Equals(t, types.ReceiptStatusSuccessful, receiptStatus(t, backend, tx))
+	inDispute, err := contract.ITellor.IsInDispute(nil, big.NewInt(1), timestamp)
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, inDispute)
+	status, _, err := contract.GetStakerInfo(nil, miners[slots-1].Address)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(stakerStatusOnDispute), status.Int64())
+
+	disputeID := big.NewInt(1)
+	tx, err = contract.Vote(opts(voter), disputeID, true)
+	testutil.Ok(t, err)
+	txDuplicate, err := contract.Vote(opts(voter), disputeID, true)
+	testutil.Ok(t, err)
+	txEarly, err := contract.TallyVotes(opts(reporter), disputeID)
+	testutil.Ok(t, err)
+	backend.Commit()
+	testutil.Equals(t, types.ReceiptStatusSuccessful, receiptStatus(t, backend, tx))
+	testutil.Equals(t, types.ReceiptStatusFailed, receiptStatus(t, backend, txDuplicate))
+	testutil.Equals(t, types.ReceiptStatusFailed, receiptStatus(t, backend, txEarly))
+	voted, err := contract.DidVote(nil, disputeID, voter.Address)
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, voted)
+
+	// The tally is allowed after the voting period and the unlock a day after the tally.
+	backend.AdjustTime(7*24*time.Hour + time.Minute)
+	tx, err = contract.TallyVotes(opts(reporter), disputeID)
+	testutil.Ok(t, err)
+	txEarly, err = contract.UnlockDisputeFee(opts(reporter), disputeID)
+	testutil.Ok(t, err)
+	backend.Commit()
+	testutil.Equals(t, types.ReceiptStatusSuccessful, receiptStatus(t, backend, tx))
+	testutil.Equals(t, types.ReceiptStatusFailed, receiptStatus(t, backend, txEarly))
+	_, executed, passed, _, _, _, _, _, _, err := contract.GetAllDisputeVars(nil, disputeID)
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, executed)
+	testutil.Equals(t, true, passed)
+
+	backend.AdjustTime(24*time.Hour + time.Minute)
+	tx, err = contract.UnlockDisputeFee(opts(reporter), disputeID)
+	testutil.Ok(t, err)
+	backend.Commit()
+	testutil.Equals(t, types.ReceiptStatusSuccessful, receiptStatus(t, backend, tx))
+	paid, err := contract.GetDisputeUintVars(nil, disputeID, eth.Keccak256([]byte("_PAID")))
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(1), paid.Int64())
+	balance, err := contract.BalanceOf(nil, reporter.Address)
+	testutil.Ok(t, err)
+	testutil.Equals(t, cfg.Tokens, balance)
+}
@@ -0,0 +1,300 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package simulation
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+)
+
+const (
+	// votingPeriod is how long a dispute can be voted on before the tally.
+	votingPeriod = uint64(7 * 24 * time.Hour / time.Second)
+	// unlockDelay is the time after the tally before the dispute fee can be unlocked.
+	unlockDelay = uint64(24 * time.Hour / time.Second)
+	// disputeWindow is how long after a value it can be disputed.
+	disputeWindow = uint64(24 * time.Hour / time.Second)
+
+	stakerStatusOnDispute = 3
+)
+
+var (
+	// DisputeFee is the TRB paid by the reporter to open a dispute.
+	DisputeFee = new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))
+
+	varDisputeFee   = ethereum.Keccak256([]byte("_DISPUTE_FEE"))
+	varDisputeCount = ethereum.Keccak256([]byte("_DISPUTE_COUNT"))
+
+	disputeVarRequestID        = ethereum.Keccak256([]byte("_REQUEST_ID"))
+	disputeVarTimestamp        = ethereum.Keccak256([]byte("_TIMESTAMP"))
+	disputeVarValue            = ethereum.Keccak256([]byte("_VALUE"))
+	disputeVarMinExecutionDate = ethereum.Keccak256([]byte("_MIN_EXECUTION_DATE"))
+	disputeVarNumberOfVotes    = ethereum.Keccak256([]byte("_NUMBER_OF_VOTES"))
+	disputeVarBlockNumber      = ethereum.Keccak256([]byte("_BLOCK_NUMBER"))
+	disputeVarMinerSlot        = ethereum.Keccak256([]byte("_MINER_SLOT"))
+	disputeVarFee              = ethereum.Keccak256([]byte("_FEE"))
+	disputeVarTallyDate        = ethereum.Keccak256([]byte("_TALLY_DATE"))
+	disputeVarPaid             = ethereum.Keccak256([]byte("_PAID"))
+)
+
+// disputeState is a dispute in the stub contract.
+// It is replaced instead of modified so that the state copies can share it.
+type disputeState struct {
+	hash             [32]byte
+	requestID        *big.Int
+	timestamp        *big.Int
+	value            *big.Int
+	miner            common.Address
+	reporter         common.Address
+	minerSlot        int64
+	blockNumber      uint64
+	minExecutionDate uint64
+	fee              *big.Int
+	executed         bool
+	passed           bool
+	tally            *big.Int
+	voters           map[common.Address]bool
+	tallyDate        uint64
+	paid             bool
+}
+
+func (self *disputeState) copy() *disputeState {
+	cpy := *self
+	cpy.voters = make(map[common.Address]bool, len(self.voters))
+	for k, v := range self.voters {
+		cpy.voters[k] = v
+	}
+	return &cpy
+}
+
+func (self *disputeState) uintVar(key [32]byte) *big.Int {
+	switch key {
+	case disputeVarRequestID:
+		return self.requestID
+	case disputeVarTimestamp:
+		return self.timestamp
+	case disputeVarValue:
+		return self.value
+	case disputeVarMinExecutionDate:
+		return new(big.Int).SetUint64(self.minExecutionDate)
+	case disputeVarNumberOfVotes:
+		return big.NewInt(int64(len(self.voters)))
+	case disputeVarBlockNumber:
+		return new(big.Int).SetUint64(self.blockNumber)
+	case disputeVarMinerSlot:
+		return big.NewInt(self.minerSlot)
+	case disputeVarFee:
+		return self.fee
+	case disputeVarTallyDate:
+		return new(big.Int).SetUint64(self.tallyDate)
+	case disputeVarPaid:
+		if self.paid {
+			return big.NewInt(1)
+		}
+	}
+	return big.NewInt(0)
+}
+
+// dispute returns the dispute with the given ID or nil when it doesn't exist.
+func (self *tellorState) dispute(id *big.Int) *disputeState {
+	if id.Sign() <= 0 || id.Cmp(big.NewInt(int64(len(self.disputes)))) > 0 {
+		return nil
+	}
+	return self.disputes[id.Int64()-1]
+}
+
+// value returns the recorded value with the request ID at the given timestamp and the index of the request ID in it.
+func (self *tellorState) value(requestID, timestamp *big.Int) (*NewValue, int) {
+	for i := range self.newValues {
+		v := &self.newValues[i]
+		if new(big.Int).SetUint64(v.Time).Cmp(timestamp) != 0 {
+			continue
+		}
+		for j, id := range v.RequestIDs {
+			if id.Cmp(requestID) == 0 {
+				return v, j
+			}
+		}
+	}
+	return nil, 0
+}
+
+// valueMiners returns the miners of the value sorted by their submitted value for the request ID
+// so that the index of a miner is the same as in the contract.
+func (self *tellorState) valueMiners(requestID, timestamp *big.Int) ([slots]common.Address, [slots]int) {
+	var miners [slots]common.Address
+	var order [slots]int
+	v, idx := self.value(requestID, timestamp)
+	if v == nil {
+		return miners, order
+	}
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order[:], func(i, j int) bool {
+		return v.Values[order[i]][idx].Cmp(v.Values[order[j]][idx]) < 0
+	})
+	for i, slot := range order {
+		miners[i] = v.Miners[slot]
+	}
+	return miners, order
+}
+
+func (self *tellorState) inDispute(requestID, timestamp *big.Int) bool {
+	for _, d := range self.disputes {
+		if d.requestID.Cmp(requestID) == 0 && d.timestamp.Cmp(timestamp) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *tellorState) balance(addr common.Address) *big.Int {
+	if balance, ok := self.balances[addr]; ok {
+		return balance
+	}
+	return big.NewInt(0)
+}
+
+func (self *tellorStub) beginDispute(state *tellorState, env *env, requestID, timestamp, minerIndex *big.Int) error {
+	v, idx := state.value(requestID, timestamp)
+	if v == nil {
+		return &revertError{reason: "Mined block is 0"}
+	}
+	if env.time-v.Time > disputeWindow {
+		return &revertError{reason: "The value was mined more than a day ago"}
+	}
+	if minerIndex.Cmp(big.NewInt(slots)) >= 0 {
+		return &revertError{reason: "Miner index is wrong"}
+	}
+	if state.inDispute(requestID, timestamp) {
+		return &revertError{reason: "Value already disputed"}
+	}
+	if state.balance(env.from).Cmp(DisputeFee) < 0 {
+		return &revertError{reason: "Balance is too low to cover dispute fee"}
+	}
+
+	miners, order := state.valueMiners(requestID, timestamp)
+	slot := order[minerIndex.Int64()]
+	miner := miners[minerIndex.Int64()]
+	d := &disputeState{
+		requestID:        requestID,
+		timestamp:        timestamp,
+		value:            v.Values[slot][idx],
+		miner:            miner,
+		reporter:         env.from,
+		minerSlot:        minerIndex.Int64(),
+		blockNumber:      env.number,
+		minExecutionDate: env.time + votingPeriod,
+		fee:              DisputeFee,
+		tally:            big.NewInt(0),
+		voters:           make(map[common.Address]bool),
+	}
+	copy(d.hash[:], crypto.Keccak256(miner.Bytes(), common.BigToHash(requestID).Bytes(), common.BigToHash(timestamp).Bytes()))
+	state.disputes = append(state.disputes, d)
+	state.balances[env.from] = new(big.Int).Sub(state.balance(env.from), DisputeFee)
+	state.stakers[miner] = stakerStatusOnDispute
+
+	id := big.NewInt(int64(len(state.disputes)))
+	return self.emit(env, self.abiITellor, "NewDispute",
+		[]common.Hash{common.BigToHash(id), common.BigToHash(requestID)},
+		timestamp, miner,
+	)
+}
+
+// vote adds the TRB balance of the voter to the tally.
+func (self *tellorStub) vote(state *tellorState, env *env, id *big.Int, support bool) error {
+	d := state.dispute(id)
+	if d == nil {
+		return &revertError{reason: "Dispute doesn't exist"}
+	}
+	if d.voters[env.from] {
+		return &revertError{reason: "Sender has already voted"}
+	}
+	if d.executed {
+		return &revertError{reason: "Dispute has been already executed"}
+	}
+	weight := state.balance(env.from)
+	if weight.Sign() == 0 {
+		return &revertError{reason: "User balance is 0"}
+	}
+	if state.stakers[env.from] == stakerStatusOnDispute {
+		return &revertError{reason: "Miner is under dispute"}
+	}
+
+	d = d.copy()
+	d.voters[env.from] = true
+	if support {
+		d.tally = new(big.Int).Add(d.tally, weight)
+	} else {
+		d.tally = new(big.Int).Sub(d.tally, weight)
+	}
+	state.disputes[id.Int64()-1] = d
+	return self.emit(env, self.abiITellor, "Voted",
+		[]common.Hash{common.BigToHash(id), common.BytesToHash(env.from.Bytes()), common.BigToHash(weight)},
+		support,
+	)
+}
+
+// tallyVotes ends the voting. A passed dispute removes the stake of the miner
+// and otherwise the miner is staked again.
+func (self *tellorStub) tallyVotes(state *tellorState, env *env, id *big.Int) error {
+	d := state.dispute(id)
+	if d == nil {
+		return &revertError{reason: "Dispute doesn't exist"}
+	}
+	if d.executed {
+		return &revertError{reason: "Dispute has been already executed"}
+	}
+	if env.time <= d.minExecutionDate {
+		return &revertError{reason: "Time for voting haven't elapsed"}
+	}
+
+	d = d.copy()
+	d.executed = true
+	d.passed = d.tally.Sign() > 0
+	d.tallyDate = env.time
+	state.disputes[id.Int64()-1] = d
+	if d.passed {
+		state.stakers[d.miner] = 0
+	} else {
+		state.stakers[d.miner] = stakerStatusStaked
+	}
+	return self.emit(env, self.abiITellor, "DisputeVoteTallied",
+		[]common.Hash{common.BigToHash(id), common.BytesToHash(d.miner.Bytes())},
+		d.tally, d.reporter, true,
+	)
+}
+
+// unlockDisputeFee pays the fee to the reporter when the dispute passed and otherwise to the miner.
+func (self *tellorStub) unlockDisputeFee(state *tellorState, env *env, id *big.Int) error {
+	d := state.dispute(id)
+	if d == nil {
+		return &revertError{reason: "Dispute doesn't exist"}
+	}
+	if !d.executed {
+		return &revertError{reason: "Dispute has not been tallied"}
+	}
+	if d.paid {
+		return &revertError{reason: "Dispute fee already paid"}
+	}
+	if env.time-d.tallyDate <= unlockDelay {
+		return &revertError{reason: "Time for voting haven't elapsed"}
+	}
+
+	d = d.copy()
+	d.paid = true
+	state.disputes[id.Int64()-1] = d
+	receiver := d.miner
+	if d.passed {
+		receiver = d.reporter
+	}
+	state.balances[receiver] = new(big.Int).Add(state.balance(receiver), d.fee)
+	return nil
+}
@@ -0,0 +1,344 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package simulation
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/mining"
+)
+
+const (
+	// slots is the number of submits needed for a new value.
+	slots = 5
+	// defaultMinerTimeLimit is the minimum time between the submits of the same miner.
+	defaultMinerTimeLimit = 15 * time.Minute
+	// anySolutionPeriod is the time after the last new value when the contract accepts any nonce.
+	anySolutionPeriod  = 15 * 60
+	stakerStatusStaked = 1
+)
+
+var (
+	// MinerReward is the amount of TRB minted to a miner for every accepted submit.
+	MinerReward = big.NewInt(1e18)
+
+	varSlotProgress       = ethereum.Keccak256([]byte("_SLOT_PROGRESS"))
+	varTimeOfLastNewValue = ethereum.Keccak256([]byte("_TIME_OF_LAST_NEW_VALUE"))
+	varDifficulty         = ethereum.Keccak256([]byte("_DIFFICULTY"))
+	varCurrentChallenge   = ethereum.Keccak256([]byte("_CURRENT_CHALLENGE"))
+)
+
+// NewValue is a value for a challenge that received all submits.
+type NewValue struct {
+	Block      uint64
+	Time       uint64
+	Challenge  [32]byte
+	RequestIDs [5]*big.Int
+	Miners     [slots]common.Address
+	// Values holds the submitted values of every miner for all request IDs.
+	Values [slots][5]*big.Int
+}
+
+// tellorState is the storage of the stub contract.
+// The big ints are never modified in place so
+// a copy of the state can share them.
+type tellorState struct {
+	challenge          [32]byte
+	requestIDs         [5]*big.Int
+	difficulty         *big.Int
+	slotProgress       uint64
+	timeOfLastNewValue uint64
+	miners             [slots]common.Address
+	values             [slots][5]*big.Int
+	lastSubmit         map[common.Address]uint64
+	stakers            map[common.Address]int64
+	balances           map[common.Address]*big.Int
+	newValues          []NewValue
+	// disputes are sorted by ID starting from 1.
+	disputes []*disputeState
+}
+
+func (self *tellorState) copy() *tellorState {
+	cpy := *self
+	cpy.lastSubmit = make(map[common.Address]uint64, len(self.lastSubmit))
+	for k, v := range self.lastSubmit {
+		cpy.lastSubmit[k] = v
+	}
+	cpy.stakers = make(map[common.Address]int64, len(self.stakers))
+	for k, v := range self.stakers {
+		cpy.stakers[k] = v
+	}
+	cpy.balances = make(map[common.Address]*big.Int, len(self.balances))
+	for k, v := range self.balances {
+		cpy.balances[k] = v
+	}
+	cpy.newValues = append([]NewValue(nil), self.newValues...)
+	cpy.disputes = append([]*disputeState(nil), self.disputes...)
+	return &cpy
+}
+
+// env is the context of a contract call.
+type env struct {
+	from       common.Address
+	number     uint64
+	time       uint64
+	parentHash common.Hash
+	logs       []*types.Log
+}
+
+// revertError is returned when the contract call reverts.
+type revertError struct {
+	reason string
+}
+
+func (e *revertError) Error() string {
+	return "execution reverted: " + e.reason
+}
+
+// tellorStub implements the parts of the Tellor oracle contract used by the miner.
+// It emits NewChallenge, accepts submitMiningSolution with the same checks as the contract,
+// rotates the slots and tracks _SLOT_PROGRESS.
+// Recorded values can be disputed, voted on, tallied and have their dispute fee unlocked.
+// The difficulty and the request IDs stay the same for all challenges.
+type tellorStub struct {
+	address common.Address
+	// minerTimeLimit is the minimum time in seconds between the submits of the same miner.
+	minerTimeLimit uint64
+	// abiITellor has all methods and the NewChallenge event.
+	abiITellor abi.ABI
+	// abiTellor has the NonceSubmitted and Transfer events.
+	abiTellor abi.ABI
+}
+
+func newTellorStub(address common.Address, minerTimeLimit time.Duration) (*tellorStub, error) {
+	abiITellor, err := abi.JSON(strings.NewReader(tellor.ITellorABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "parse ITellor abi")
+	}
+	abiTellor, err := abi.JSON(strings.NewReader(tellor.TellorABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "parse Tellor abi")
+	}
+	return &tellorStub{
+		address:        address,
+		minerTimeLimit: uint64(minerTimeLimit.Seconds()),
+		abiITellor:     abiITellor,
+		abiTellor:      abiTellor,
+	}, nil
+}
+
+// call executes the method from the input and returns the packed output.
+// The state is changed even when the call reverts so
+// the caller should use a copy and discard it on errors.
+func (self *tellorStub) call(state *tellorState, env *env, input []byte) ([]byte, error) {
+	if len(input) < 4 {
+		return nil, &revertError{reason: "missing method id"}
+	}
+	method, err := self.abiITellor.MethodById(input[:4])
+	if err != nil {
+		return nil, &revertError{reason: "method not supported by the simulation"}
+	}
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, &revertError{reason: "invalid input for method " + method.Name}
+	}
+
+	switch method.Name {
+	case "getNewCurrentVariables":
+		return method.Outputs.Pack(state.challenge, state.requestIDs, state.difficulty, big.NewInt(0))
+	case "getUintVar":
+		return method.Outputs.Pack(state.uintVar(args[0].([32]byte)))
+	case "getStakerInfo":
+		staker := args[0].(common.Address)
+		return method.Outputs.Pack(big.NewInt(state.stakers[staker]), big.NewInt(0))
+	case "balanceOf":
+		return method.Outputs.Pack(state.balance(args[0].(common.Address)))
+	case "didMine":
+		challenge, miner := args[0].([32]byte), args[1].(common.Address)
+		return method.Outputs.Pack(challenge == state.challenge && state.submitted(miner))
+	case "submitMiningSolution":
+		if err := self.submitMiningSolution(state, env, args[0].(string), args[1].([5]*big.Int), args[2].([5]*big.Int)); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	case "isInDispute":
+		return method.Outputs.Pack(state.inDispute(args[0].(*big.Int), args[1].(*big.Int)))
+	case "getMinersByRequestIdAndTimestamp":
+		miners, _ := state.valueMiners(args[0].(*big.Int), args[1].(*big.Int))
+		return method.Outputs.Pack(miners)
+	case "getAllDisputeVars":
+		d := state.dispute(args[0].(*big.Int))
+		if d == nil {
+			d = &disputeState{requestID: big.NewInt(0), timestamp: big.NewInt(0), value: big.NewInt(0), fee: big.NewInt(0), tally: big.NewInt(0)}
+		}
+		var uintVars [9]*big.Int
+		for i, key := range [][32]byte{
+			disputeVarRequestID,
+			disputeVarTimestamp,
+			disputeVarValue,
+			disputeVarMinExecutionDate,
+			disputeVarNumberOfVotes,
+			disputeVarBlockNumber,
+			disputeVarMinerSlot,
+			{}, // Quorum.
+			disputeVarFee,
+		} {
+			uintVars[i] = d.uintVar(key)
+		}
+		return method.Outputs.Pack(d.hash, d.executed, d.passed, false, d.miner, d.reporter, common.Address{}, uintVars, d.tally)
+	case "getDisputeUintVars":
+		d := state.dispute(args[0].(*big.Int))
+		if d == nil {
+			return method.Outputs.Pack(big.NewInt(0))
+		}
+		return method.Outputs.Pack(d.uintVar(args[1].([32]byte)))
+	case "didVote":
+		d := state.dispute(args[0].(*big.Int))
+		return method.Outputs.Pack(d != nil && d.voters[args[1].(common.Address)])
+	case "beginDispute":
+		return nil, self.beginDispute(state, env, args[0].(*big.Int), args[1].(*big.Int), args[2].(*big.Int))
+	case "vote":
+		return nil, self.vote(state, env, args[0].(*big.Int), args[1].(bool))
+	case "tallyVotes":
+		return nil, self.tallyVotes(state, env, args[0].(*big.Int))
+	case "unlockDisputeFee":
+		return nil, self.unlockDisputeFee(state, env, args[0].(*big.Int))
+	default:
+		return nil, &revertError{reason: "method not supported by the simulation:" + method.Name}
+	}
+}
+
+func (self *tellorState) uintVar(key [32]byte) *big.Int {
+	switch key {
+	case varSlotProgress:
+		return new(big.Int).SetUint64(self.slotProgress)
+	case varTimeOfLastNewValue:
+		return new(big.Int).SetUint64(self.timeOfLastNewValue)
+	case varDifficulty:
+		return self.difficulty
+	case varCurrentChallenge:
+		return new(big.Int).SetBytes(self.challenge[:])
+	case varDisputeFee:
+		return DisputeFee
+	case varDisputeCount:
+		return big.NewInt(int64(len(self.disputes)))
+	}
+	// The last submit time of a miner is stored under the hash of its address.
+	for miner, last := range self.lastSubmit {
+		if ethereum.Keccak256(common.BytesToHash(miner.Bytes()).Bytes()) == key {
+			return new(big.Int).SetUint64(last)
+		}
+	}
+	return big.NewInt(0)
+}
+
+func (self *tellorState) submitted(miner common.Address) bool {
+	for i := uint64(0); i < self.slotProgress; i++ {
+		if self.miners[i] == miner {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *tellorStub) submitMiningSolution(state *tellorState, env *env, nonce string, requestIDs, values [5]*big.Int) error {
+	if state.stakers[env.from] != stakerStatusStaked {
+		return &revertError{reason: "Miner status is not staker"}
+	}
+	for i, id := range requestIDs {
+		if id.Cmp(state.requestIDs[i]) != 0 {
+			return &revertError{reason: "Request ID is wrong"}
+		}
+	}
+	if env.time-state.lastSubmit[env.from] < self.minerTimeLimit {
+		return &revertError{reason: "Miner can only win rewards once per 15 min"}
+	}
+	if state.submitted(env.from) {
+		return &revertError{reason: "Miner already submitted the value"}
+	}
+	if env.time-state.timeOfLastNewValue < anySolutionPeriod {
+		hash := mining.NewHashSettings(&mining.MiningChallenge{
+			Challenge:  state.challenge[:],
+			Difficulty: state.difficulty,
+		}, env.from.Hex())
+		if !mining.ValidNonce(hash, nonce) {
+			return &revertError{reason: "Incorrect nonce for current challenge"}
+		}
+	}
+
+	slot := state.slotProgress
+	state.miners[slot] = env.from
+	state.values[slot] = values
+	state.lastSubmit[env.from] = env.time
+	if err := self.emit(env, self.abiTellor, "NonceSubmitted",
+		[]common.Hash{common.BytesToHash(env.from.Bytes()), state.challenge},
+		nonce, requestIDs, values, new(big.Int).SetUint64(slot),
+	); err != nil {
+		return err
+	}
+
+	state.balances[env.from] = new(big.Int).Add(state.balance(env.from), MinerReward)
+	if err := self.emit(env, self.abiTellor, "Transfer",
+		[]common.Hash{common.BytesToHash(self.address.Bytes()), common.BytesToHash(env.from.Bytes())},
+		MinerReward,
+	); err != nil {
+		return err
+	}
+
+	state.slotProgress++
+	if state.slotProgress < slots {
+		return nil
+	}
+	return self.newValue(state, env, nonce)
+}
+
+// newValue records the value for the current challenge and starts a new one.
+// Same as in the contract the value time is rounded down to the minute.
+func (self *tellorStub) newValue(state *tellorState, env *env, nonce string) error {
+	valueTime := env.time - env.time%60
+	state.newValues = append(state.newValues, NewValue{
+		Block:      env.number,
+		Time:       valueTime,
+		Challenge:  state.challenge,
+		RequestIDs: state.requestIDs,
+		Miners:     state.miners,
+		Values:     state.values,
+	})
+	state.slotProgress = 0
+	state.miners = [slots]common.Address{}
+	state.values = [slots][5]*big.Int{}
+	state.timeOfLastNewValue = valueTime
+	// The contract also uses the previous block hash so
+	// the same submits in a reorged chain give a different challenge.
+	copy(state.challenge[:], crypto.Keccak256([]byte(nonce), state.challenge[:], env.parentHash.Bytes()))
+
+	return self.emit(env, self.abiITellor, "NewChallenge",
+		[]common.Hash{state.challenge},
+		state.requestIDs, state.difficulty, big.NewInt(0),
+	)
+}
+
+// emit adds a log for the event with the given indexed topics and non indexed arguments.
+func (self *tellorStub) emit(env *env, contractABI abi.ABI, name string, topics []common.Hash, args ...interface{}) error {
+	event := contractABI.Events[name]
+	data, err := event.Inputs.NonIndexed().Pack(args...)
+	if err != nil {
+		return errors.Wrapf(err, "packing event:%v", name)
+	}
+	env.logs = append(env.logs, &types.Log{
+		Address: self.address,
+		Topics:  append([]common.Hash{event.ID}, topics...),
+		Data:    data,
+	})
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package stake
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Staker statuses from https://github.com/tellor-io/tellor3/blob/7c2f38a0e3f96631fb0f96e0d0a9f73e7b355766/contracts/TellorStorage.sol#L41
+const (
+	StatusNotStaked         = 0
+	StatusStaked            = 1
+	StatusLockedForWithdraw = 2
+	StatusOnDispute         = 3
+	StatusReadyForUnlocking = 4
+	StatusUnlocked          = 5
+)
+
+// withdrawLock is how long the stake stays locked after a withdraw request.
+const withdrawLock = 7 * 24 * time.Hour
+
+func StatusName(status int64) string {
+	switch status {
+	case StatusNotStaked:
+		return "Not staked"
+	case StatusStaked:
+		return "Staked"
+	case StatusLockedForWithdraw:
+		return "LockedForWithdraw"
+	case StatusOnDispute:
+		return "OnDispute"
+	case StatusReadyForUnlocking:
+		return "ReadyForUnlocking"
+	case StatusUnlocked:
+		return "Unlocked"
+	default:
+		return "Unknown"
+	}
+}
+
+type Contract interface {
+	GetStakerInfo(opts *bind.CallOpts, _staker common.Address) (*big.Int, *big.Int, error)
+	BalanceOf(opts *bind.CallOpts, _user common.Address) (*big.Int, error)
+}
+
+// Info is the stake of an account.
+type Info struct {
+	Address common.Address
+	Status  int64
+	// Since is when the current status started.
+	Since time.Time
+	// WithdrawAt is when a stake locked for withdraw can be withdrawn.
+	WithdrawAt time.Time
+	// Balance is the TRB balance including the stake.
+	Balance *big.Int
+}
+
+func GetInfo(ctx context.Context, contract Contract, addr common.Address) (*Info, error) {
+	status, started, err := contract.GetStakerInfo(&bind.CallOpts{Context: ctx}, addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "get stake status")
+	}
+	balance, err := contract.BalanceOf(&bind.CallOpts{Context: ctx}, addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "get TRB balance")
+	}
+	info := &Info{
+		Address: addr,
+		Status:  status.Int64(),
+		Since:   time.Unix(started.Int64(), 0),
+		Balance: balance,
+	}
+	if info.Status == StatusLockedForWithdraw {
+		info.WithdrawAt = WithdrawAt(info.Since)
+	}
+	return info, nil
+}
+
+// CanDeposit returns whether the contract accepts a stake deposit for the account.
+// A stake locked for withdraw can be deposited again to continue staking.
+func (self *Info) CanDeposit() bool {
+	return self.Status == StatusNotStaked || self.Status == StatusLockedForWithdraw
+}
+
+// WithdrawAt returns when the stake can be withdrawn after a withdraw request at the given time.
+// The contract stores the request time floored to the day and unlocks the stake 7 days after it.
+func WithdrawAt(requested time.Time) time.Time {
+	const day = 86400
+	floored := (requested.Unix() / day) * day
+	return time.Unix(floored, 0).Add(withdrawLock)
+}
@@ -0,0 +1,22 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package stake
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestWithdrawAt(t *testing.T) {
+	day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	testutil.Equals(t, day.Add(week).Unix(), WithdrawAt(day).Unix())
+	// A request during the day is counted from the start of the day.
+	testutil.Equals(t, day.Add(week).Unix(), WithdrawAt(day.Add(time.Second)).Unix())
+	testutil.Equals(t, day.Add(week).Unix(), WithdrawAt(day.Add(23*time.Hour)).Unix())
+	testutil.Equals(t, day.Add(24*time.Hour+week).Unix(), WithdrawAt(day.Add(24*time.Hour)).Unix())
+}
@@ -0,0 +1,216 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tellor
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/mining"
+)
+
+// pendingSubmit is a solution that is not yet confirmed on chain
+// together with the hashes of all transactions sent for it.
+type pendingSubmit struct {
+	Challenge  hexutil.Bytes `json:"challenge"`
+	PublicAddr string        `json:"publicAddr"`
+	Nonce      string        `json:"nonce"`
+	Txs        []common.Hash `json:"txs"`
+}
+
+func (self *pendingSubmit) is(result *mining.Result) bool {
+	return self.Nonce == result.Nonce && string(self.Challenge) == string(result.Work.Challenge.Challenge)
+}
+
+// state keeps the pending submit in a file so that it can be recovered after a restart.
+// An empty path disables the file and the state is kept only in memory.
+type state struct {
+	mtx     sync.Mutex
+	path    string
+	pending *pendingSubmit
+}
+
+func newState(dir string, addr common.Address) (*state, error) {
+	if dir == "" {
+		return &state{}, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "creating the state directory")
+	}
+	self := &state{path: statePath(dir, addr)}
+	pending, err := readPendingSubmit(self.path)
+	if err != nil {
+		return nil, err
+	}
+	self.pending = pending
+	return self, nil
+}
+
+// readPendingSubmit returns nil when the state file doesn't exist.
+func readPendingSubmit(path string) (*pendingSubmit, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the state file")
+	}
+	pending := &pendingSubmit{}
+	if err := json.Unmarshal(data, pending); err != nil {
+		return nil, errors.Wrapf(err, "parsing the state file:%v", path)
+	}
+	return pending, nil
+}
+
+func statePath(dir string, addr common.Address) string {
+	return filepath.Join(dir, addr.Hex()+".json")
+}
+
+// start records a solution that is about to be submitted.
+func (self *state) start(result *mining.Result) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.pending = &pendingSubmit{
+		Challenge:  result.Work.Challenge.Challenge,
+		PublicAddr: result.Work.PublicAddr,
+		Nonce:      result.Nonce,
+	}
+	return self.save()
+}
+
+// sent records a transaction for the solution.
+func (self *state) sent(result *mining.Result, tx common.Hash) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.pending == nil || !self.pending.is(result) {
+		return nil // Already replaced by a newer solution.
+	}
+	self.pending.Txs = append(self.pending.Txs, tx)
+	return self.save()
+}
+
+// hasTxs returns whether a transaction was sent for the solution.
+func (self *state) hasTxs(result *mining.Result) bool {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.pending != nil && self.pending.is(result) && len(self.pending.Txs) > 0
+}
+
+// done removes the solution once there is nothing to recover for it.
+func (self *state) done(result *mining.Result) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.pending == nil || !self.pending.is(result) {
+		return nil // Already replaced by a newer solution.
+	}
+	self.pending = nil
+	return self.save()
+}
+
+// save writes the pending submit to a temporary file and renames it
+// so that the file is never left half written.
+// It should be called with the mutex held.
+func (self *state) save() error {
+	if self.path == "" {
+		return nil
+	}
+	if self.pending == nil {
+		if err := os.Remove(self.path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "removing the state file")
+		}
+		return nil
+	}
+	data, err := json.Marshal(self.pending)
+	if err != nil {
+		return errors.Wrap(err, "marshaling the state")
+	}
+	tmp := self.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrap(err, "writing the state file")
+	}
+	return errors.Wrap(os.Rename(tmp, self.path), "renaming the state file")
+}
+
+// HasPendingSubmit returns whether the submitter of the account has a transaction
+// for a solution that is still pending on chain.
+// A solution without transactions or with transactions that are already mined or dropped
+// is ignored as a stopped miner won't send it.
+func HasPendingSubmit(ctx context.Context, client ethereum.TransactionReader, dir string, addr common.Address) (bool, error) {
+	if dir == "" {
+		return false, nil
+	}
+	pending, err := readPendingSubmit(statePath(dir, addr))
+	if err != nil || pending == nil {
+		return false, err
+	}
+	for _, hash := range pending.Txs {
+		_, isPending, err := client.TransactionByHash(ctx, hash)
+		if err == ethereum.NotFound {
+			continue
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "getting a pending submit transaction:%v", hash)
+		}
+		if isPending {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Recover reconciles the solution that was pending before a restart with the chain.
+// When a transaction for it was already mined successfully or is mined within the context deadline there is nothing to recover.
+// Otherwise it returns the solution so that the miner can send it again without mining the challenge again.
+// Sending it again replaces a stuck transaction as the transactor reuses the nonce of the account.
+func (self *Submitter) Recover(ctx context.Context) (*mining.Result, error) {
+	self.state.mtx.Lock()
+	pending := self.state.pending
+	self.state.mtx.Unlock()
+	if pending == nil {
+		return nil, nil
+	}
+	level.Info(self.logger).Log("msg", "recovering a pending submit", "challenge", pending.Challenge, "nonce", pending.Nonce, "txs", len(pending.Txs))
+
+	result := &mining.Result{
+		Work: &mining.Work{
+			Challenge:  &mining.MiningChallenge{Challenge: pending.Challenge},
+			PublicAddr: pending.PublicAddr,
+		},
+		Nonce: pending.Nonce,
+	}
+	for _, hash := range pending.Txs {
+		tx, isPending, err := self.client.TransactionByHash(ctx, hash)
+		if err == ethereum.NotFound {
+			continue // Never sent or dropped from the mempool.
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting a recovered transaction:%v", hash)
+		}
+		if isPending {
+			level.Info(self.logger).Log("msg", "waiting for a recovered transaction to be mined", "tx", hash)
+		}
+		receipt, err := bind.WaitMined(ctx, self.client, tx)
+		if err != nil {
+			level.Info(self.logger).Log("msg", "recovered transaction is not mined so will send the solution again", "tx", hash, "err", err)
+			break
+		}
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			level.Info(self.logger).Log("msg", "recovered transaction was mined", "tx", hash)
+			return nil, self.state.done(result)
+		}
+		level.Warn(self.logger).Log("msg", "recovered transaction failed", "tx", hash)
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tellor
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/mining"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestRecover(t *testing.T) {
+	logger := logging.NewLogger()
+	accounts, err := simulation.NewAccounts(2)
+	testutil.Ok(t, err)
+	backend, err := simulation.NewBackend(logger, simulation.Config{
+		Difficulty: big.NewInt(100),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Accounts:   []common.Address{accounts[0].Address},
+		Balance:    big.NewInt(1e18),
+	})
+	testutil.Ok(t, err)
+	dir := t.TempDir()
+
+	// restart loads the state saved before the restart.
+	restart := func() *Submitter {
+		state, err := newState(dir, accounts[0].Address)
+		testutil.Ok(t, err)
+		return &Submitter{logger: logger, client: backend, state: state}
+	}
+	result := &mining.Result{
+		Work: &mining.Work{
+			Challenge:  &mining.MiningChallenge{Challenge: []byte{1, 2, 3}},
+			PublicAddr: accounts[0].Address.Hex(),
+		},
+		Nonce: "123",
+	}
+
+	// Nothing to recover without a pending solution.
+	recovered, err := restart().Recover(context.Background())
+	testutil.Ok(t, err)
+	testutil.Assert(t, recovered == nil, "nothing should be recovered")
+
+	// A discarded solution is removed.
+	submitter := restart()
+	testutil.Ok(t, submitter.state.start(result))
+	testutil.Assert(t, !submitter.state.hasTxs(result), "no transaction was sent")
+	submitter.Discard(result)
+	_, err = os.Stat(submitter.state.path)
+	testutil.Assert(t, os.IsNotExist(err), "the state file should be removed")
+
+	// A solution with a transaction that never reached the chain is sent again.
+	testutil.Ok(t, submitter.state.start(result))
+	testutil.Ok(t, submitter.state.sent(result, common.HexToHash("0x1")))
+	recovered, err = restart().Recover(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, result.Nonce, recovered.Nonce)
+	testutil.Equals(t, result.Work.PublicAddr, recovered.Work.PublicAddr)
+	testutil.Equals(t, result.Work.Challenge.Challenge, recovered.Work.Challenge.Challenge)
+
+	// A mined transaction completes the solution.
+	tx := types.NewTransaction(0, accounts[1].Address, big.NewInt(1), 21000, big.NewInt(1), nil)
+	auth := accounts[0].NewTransactor(big.NewInt(simulation.NetworkID))
+	tx, err = auth.Signer(auth.From, tx)
+	testutil.Ok(t, err)
+	testutil.Ok(t, backend.SendTransaction(context.Background(), tx))
+	backend.Commit()
+	testutil.Ok(t, submitter.state.sent(result, tx.Hash()))
+	recovered, err = restart().Recover(context.Background())
+	testutil.Ok(t, err)
+	testutil.Assert(t, recovered == nil, "the mined solution shouldn't be recovered")
+	_, err = os.Stat(submitter.state.path)
+	testutil.Assert(t, os.IsNotExist(err), "the state file should be removed")
+}
+
+func TestHasPendingSubmit(t *testing.T) {
+	logger := logging.NewLogger()
+	accounts, err := simulation.NewAccounts(2)
+	testutil.Ok(t, err)
+	backend, err := simulation.NewBackend(logger, simulation.Config{
+		Difficulty: big.NewInt(100),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Accounts:   []common.Address{accounts[0].Address},
+		Balance:    big.NewInt(1e18),
+	})
+	testutil.Ok(t, err)
+	dir := t.TempDir()
+	ctx := context.Background()
+	addr := accounts[0].Address
+
+	state, err := newState(dir, addr)
+	testutil.Ok(t, err)
+	result := &mining.Result{
+		Work:  &mining.Work{Challenge: &mining.MiningChallenge{Challenge: []byte{1, 2, 3}}, PublicAddr: addr.Hex()},
+		Nonce: "123",
+	}
+
+	pending, err := HasPendingSubmit(ctx, backend, dir, addr)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !pending, "no state file")
+
+	// A solution without transactions or with a dropped transaction is never sent by a stopped miner.
+	testutil.Ok(t, state.start(result))
+	testutil.Ok(t, state.sent(result, common.HexToHash("0x1")))
+	pending, err = HasPendingSubmit(ctx, backend, dir, addr)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !pending, "the transactions are not on chain")
+
+	tx := types.NewTransaction(0, accounts[1].Address, big.NewInt(1), 21000, big.NewInt(1), nil)
+	auth := accounts[0].NewTransactor(big.NewInt(simulation.NetworkID))
+	tx, err = auth.Signer(auth.From, tx)
+	testutil.Ok(t, err)
+	testutil.Ok(t, backend.SendTransaction(ctx, tx))
+	testutil.Ok(t, state.sent(result, tx.Hash()))
+	pending, err = HasPendingSubmit(ctx, backend, dir, addr)
+	testutil.Ok(t, err)
+	testutil.Assert(t, pending, "the transaction is not mined")
+
+	backend.Commit()
+	pending, err = HasPendingSubmit(ctx, backend, dir, addr)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !pending, "the transaction is mined")
+}
@@ -8,12 +8,12 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
@@ -25,6 +25,8 @@ import (
 	"github.com/tellor-io/telliot/pkg/logging"
 	"github.com/tellor-io/telliot/pkg/mining"
 	psr "github.com/tellor-io/telliot/pkg/psr/tellor"
+	"github.com/tellor-io/telliot/pkg/stake"
+	"github.com/tellor-io/telliot/pkg/tasker"
 	"github.com/tellor-io/telliot/pkg/tracker/reward"
 	"github.com/tellor-io/telliot/pkg/transactor"
 )
@@ -42,6 +44,7 @@ type Config struct {
 	LogLevel        string
 	ProfitThreshold uint64          `help:"Minimum percent of profit when submitting a solution. For example if the tx cost is 0.01 ETH and current reward is 0.02 ETH a ProfitThreshold of 200% or more will wait until the reward is increased or the gas cost is lowered a ProfitThreshold of 199% or less will submit."`
 	MinSubmitPeriod format.Duration `help:"The time limit between each submit for a staked miner."`
+	StateDir        string          `help:"Directory for the pending solutions and transactions that are recovered after a restart. Empty disables it."`
 }
 
 /**
@@ -56,7 +59,7 @@ type Submitter struct {
 	logger          log.Logger
 	cfg             Config
 	account         *ethereum.Account
-	client          *ethclient.Client
+	client          ethereum.EthClient
 	contract        ContractCaller
 	resultCh        chan *mining.Result
 	submitCount     prometheus.Counter
@@ -67,13 +70,16 @@ type Submitter struct {
 	reward          *reward.RewardQuerier
 	gasPriceQuerier gasPrice.GasPriceQuerier
 	psr             *psr.Psr
+	state           *state
+	// submits tracks the running submits so that the shutdown waits for them.
+	submits sync.WaitGroup
 }
 
 func New(
 	ctx context.Context,
 	logger log.Logger,
 	cfg Config,
-	client *ethclient.Client,
+	client ethereum.EthClient,
 	contract ContractCaller,
 	account *ethereum.Account,
 	reward *reward.RewardQuerier,
@@ -86,6 +92,10 @@ func New(
 		return nil, nil, errors.Wrap(err, "apply filter logger")
 	}
 	logger = log.With(logger, "component", ComponentName)
+	state, err := newState(cfg.StateDir, account.Address)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "loading the submitter state")
+	}
 	ctx, close := context.WithCancel(ctx)
 	submitter := &Submitter{
 		ctx:             ctx,
@@ -100,6 +110,7 @@ func New(
 		transactor:      transactor,
 		gasPriceQuerier: gasPriceQuerier,
 		psr:             psr,
+		state:           state,
 		submitCount: promauto.NewCounter(prometheus.CounterOpts{
 			Namespace:   "telliot",
 			Subsystem:   ComponentName,
@@ -133,6 +144,8 @@ func (self *Submitter) Start() error {
 		select {
 		case <-self.ctx.Done():
 			self.CancelPendingSubmit()
+			// A submit with a sent transaction waits for it to be mined up to the transactor drain timeout.
+			self.submits.Wait()
 			return self.ctx.Err()
 		case result := <-self.resultCh:
 			self.CancelPendingSubmit()
@@ -197,15 +210,33 @@ func (self *Submitter) blockUntilTimeToSubmit(newChallengeReplace context.Contex
 	}
 }
 
+// errSkip is returned when no slot of the challenge is expected to be profitable.
+type errSkip struct {
+	reason string
+}
+
+func (e errSkip) Error() string {
+	return "skipping the challenge:" + e.reason
+}
+
 func (self *Submitter) canSubmit() error {
 	if self.cfg.ProfitThreshold > 0 { // Profit check is enabled.
-		profitPercent, err := self.profitPercent()
-		if _, ok := errors.Cause(err).(reward.ErrNoDataForSlot); ok {
-			level.Warn(self.logger).Log("msg", "skipping profit check when the slot has no record for how much gas it uses", "err", err)
-		} else if err != nil {
-			return errors.Wrapf(err, "submit solution profit check")
-		} else if profitPercent < int64(self.cfg.ProfitThreshold) {
-			return errors.Errorf("profit:%v lower then the profit threshold:%v", profitPercent, self.cfg.ProfitThreshold)
+		gasPrice, err := self.gasPriceQuerier.Query(self.ctx)
+		if err != nil {
+			return errors.Wrap(err, "getting current Gas price")
+		}
+		ctx, cncl := context.WithTimeout(self.ctx, 3*time.Second)
+		defer cncl()
+		decision, err := self.reward.Decide(ctx, gasPrice, int64(self.cfg.ProfitThreshold))
+		if err != nil {
+			return errors.Wrap(err, "submit solution profit check")
+		}
+		decision.Log(self.logger)
+		switch decision.Action {
+		case reward.ActionWait:
+			return errors.Errorf("profit:%v lower then the profit threshold:%v", decision.ProfitPercent, self.cfg.ProfitThreshold)
+		case reward.ActionSkip:
+			return errSkip{reason: decision.Reason}
 		}
 	}
 
@@ -213,39 +244,27 @@ func (self *Submitter) canSubmit() error {
 	if err != nil {
 		return errors.Wrap(err, "getting miner status")
 	}
-	if statusID != 1 {
-		return errors.Errorf("miner is not in a status that can submit:%v", minerStatusName(statusID))
+	if statusID != stake.StatusStaked {
+		return errors.Errorf("miner is not in a status that can submit:%v", stake.StatusName(statusID))
 	}
 
 	return nil
 }
 
-func (self *Submitter) profitPercent() (int64, error) {
-	slot, err := self.reward.Slot()
-	if err != nil {
-		return 0, errors.Wrapf(err, "getting current slot")
-	}
-	gasPrice, err := self.gasPriceQuerier.Query(self.ctx)
-	if err != nil {
-		return 0, errors.Wrapf(err, "getting current Gas price")
-	}
-
-	// Need the price for next slot transaction so increment by one.
-	slot.Add(slot, big.NewInt(1))
-
-	// Slots numbers are from 0 to 4 so
-	// when next slot is 4+1=5 get the price for slot 0.
-	if slot.Int64() == 5 {
-		slot.SetInt64(0)
-	}
-
-	ctx, cncl := context.WithTimeout(self.ctx, 3*time.Second)
-	defer cncl()
-	return self.reward.Current(ctx, slot, gasPrice)
-}
-
 func (self *Submitter) Submit(newChallengeReplace context.Context, result *mining.Result) {
+	if err := self.state.start(result); err != nil {
+		level.Error(self.logger).Log("msg", "saving the pending solution", "err", err)
+	}
+	self.submits.Add(1)
 	go func(newChallengeReplace context.Context, result *mining.Result) {
+		defer self.submits.Done()
+		// On shutdown a solution with a sent transaction stays in the state so that it is recovered on the next start.
+		defer func() {
+			if self.ctx.Err() != nil && self.state.hasTxs(result) {
+				return
+			}
+			self.Discard(result)
+		}()
 		ticker := time.NewTicker(30 * time.Second)
 		defer ticker.Stop()
 		for {
@@ -258,8 +277,15 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 
 			self.blockUntilTimeToSubmit(newChallengeReplace)
 			if err := self.canSubmit(); err != nil {
+				if _, ok := errors.Cause(err).(errSkip); ok {
+					level.Info(self.logger).Log("msg", "dropping the solution", "reason", err)
+					return
+				}
 				level.Info(self.logger).Log("msg", "can't submit and will retry later", "reason", err)
-				<-ticker.C
+				select {
+				case <-ticker.C:
+				case <-newChallengeReplace.Done():
+				}
 				continue
 			}
 			for {
@@ -273,7 +299,10 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 				reqVals, err := self.requestVals(result.Work.Challenge.RequestIDs)
 				if err != nil {
 					level.Error(self.logger).Log("msg", "adding the request ids, retrying", "err", err)
-					<-ticker.C
+					select {
+					case <-ticker.C:
+					case <-newChallengeReplace.Done():
+					}
 					continue
 				}
 				level.Info(self.logger).Log(
@@ -283,7 +312,13 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 					"vals", fmt.Sprintf("%+v", reqVals),
 				)
 				f := func(auth *bind.TransactOpts) (*types.Transaction, error) {
-					return self.contract.SubmitMiningSolution(auth, result.Nonce, result.Work.Challenge.RequestIDs, reqVals)
+					tx, err := self.contract.SubmitMiningSolution(auth, result.Nonce, result.Work.Challenge.RequestIDs, reqVals)
+					if err == nil {
+						if err := self.state.sent(result, tx.Hash()); err != nil {
+							level.Error(self.logger).Log("msg", "saving the pending transaction", "err", err)
+						}
+					}
+					return tx, err
 				}
 				tx, recieipt, err := self.transactor.Transact(newChallengeReplace, f)
 				select {
@@ -327,6 +362,13 @@ func (self *Submitter) Submit(newChallengeReplace context.Context, result *minin
 	}(newChallengeReplace, result)
 }
 
+// Discard removes a solution that won't be submitted from the pending state.
+func (self *Submitter) Discard(result *mining.Result) {
+	if err := self.state.done(result); err != nil {
+		level.Error(self.logger).Log("msg", "removing the pending solution", "err", err)
+	}
+}
+
 func (self *Submitter) requestVals(requestIDs [5]*big.Int) ([5]*big.Int, error) {
 	var currentValues [5]*big.Int
 	for i, reqID := range requestIDs {
@@ -348,50 +390,51 @@ func (self *Submitter) minerStatus() (int64, error) {
 	return statusID.Int64(), nil
 }
 
-func (self *Submitter) lastSubmit() (time.Duration, *time.Time, error) {
-	address := "000000000000000000000000" + self.account.Address.Hex()[2:]
-	decoded, err := hex.DecodeString(address)
+// NextSubmit returns the time until the account can submit its next solution.
+// It returns tasker.Never when the miner is not in a status that can submit.
+func (self *Submitter) NextSubmit() (time.Duration, error) {
+	statusID, err := self.minerStatus()
 	if err != nil {
-		return 0, nil, errors.Wrapf(err, "decoding address")
+		return 0, errors.Wrap(err, "getting miner status")
 	}
-	last, err := self.contract.GetUintVar(nil, ethereum.Keccak256(decoded))
-
+	if statusID != stake.StatusStaked {
+		return tasker.Never, nil
+	}
+	lastSubmit, _, err := self.lastSubmit()
 	if err != nil {
-		return 0, nil, errors.Wrapf(err, "getting last submit time for:%v", self.account.Address.String())
+		return 0, err
 	}
-	// The Miner has never submitted so put a timestamp at the beginning of unix time.
-	if last.Int64() == 0 {
-		last.Set(big.NewInt(1))
+	if wait := self.cfg.MinSubmitPeriod.Duration - lastSubmit; wait > 0 {
+		return wait, nil
 	}
+	return 0, nil
+}
 
-	lastInt := last.Int64()
-	now := time.Now()
-	var lastSubmit time.Duration
-	var tm time.Time
-	if lastInt > 0 {
-		tm = time.Unix(lastInt, 0)
-		lastSubmit = now.Sub(tm)
+func (self *Submitter) lastSubmit() (time.Duration, *time.Time, error) {
+	tm, err := LastSubmit(nil, self.contract, self.account.Address)
+	if err != nil {
+		return 0, nil, err
 	}
-
-	return lastSubmit, &tm, nil
+	// The Miner has never submitted so put a timestamp at the beginning of unix time.
+	if tm.IsZero() {
+		tm = time.Unix(1, 0)
+	}
+	return time.Since(tm), &tm, nil
 }
 
-func minerStatusName(statusID int64) string {
-	// From https://github.com/tellor-io/tellor3/blob/7c2f38a0e3f96631fb0f96e0d0a9f73e7b355766/contracts/TellorStorage.sol#L41
-	switch statusID {
-	case 0:
-		return "Not staked"
-	case 1:
-		return "Staked"
-	case 2:
-		return "LockedForWithdraw"
-	case 3:
-		return "OnDispute"
-	case 4:
-		return "ReadyForUnlocking"
-	case 5:
-		return "Unlocked"
-	default:
-		return "Unknown"
+// LastSubmit returns the time of the last submit of a miner.
+// It is zero when the miner has never submitted.
+func LastSubmit(opts *bind.CallOpts, contract ContractCaller, addr common.Address) (time.Time, error) {
+	decoded, err := hex.DecodeString("000000000000000000000000" + addr.Hex()[2:])
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "decoding address")
+	}
+	last, err := contract.GetUintVar(opts, ethereum.Keccak256(decoded))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "getting last submit time for:%v", addr.String())
+	}
+	if last.Sign() == 0 {
+		return time.Time{}, nil
 	}
+	return time.Unix(last.Int64(), 0), nil
 }
@@ -9,11 +9,11 @@ import (
 	"math"
 	"math/big"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
@@ -35,8 +35,25 @@ const (
 type Config struct {
 	Enabled              bool
 	LogLevel             string
-	MinSubmitPeriod      format.Duration `help:"The time limit between each submit for a staked miner."`
-	MinSubmitPriceChange float64         `help:" Submit only if that price changed at least that much percent."`
+	MinSubmitPeriod      format.Duration `help:"How often to check whether the values of the request IDs need a submit."`
+	MinSubmitPriceChange float64         `help:"Default for the request IDs - submit only if the price changed at least that much percent."`
+	Heartbeat            format.Duration `help:"Default for the request IDs - submit when there was no submit for that long even when the price hasn't changed."`
+	RequestIDs           []RequestID     `help:"The request IDs to submit. The deviation threshold and the heartbeat of each ID default to the values above when not set."`
+}
+
+// RequestID sets when to submit the value of a request ID.
+type RequestID struct {
+	ID                   int64
+	MinSubmitPriceChange float64         `help:"Submit only if the price changed at least that much percent."`
+	Heartbeat            format.Duration `help:"Submit when there was no submit for that long even when the price hasn't changed."`
+}
+
+// request is a request ID with the last value submitted for it.
+// Each request ID is checked in its own goroutine so no locking is needed.
+type request struct {
+	RequestID
+	lastSubmitValue float64
+	lastSubmitTime  time.Time
 }
 
 /**
@@ -51,23 +68,23 @@ type Submitter struct {
 	logger          log.Logger
 	cfg             Config
 	account         *ethereum.Account
-	client          *ethclient.Client
+	client          ethereum.EthClient
 	contract        *contracts.ITellorMesosphere
 	transactor      transactor.Transactor
-	submitCount     prometheus.Counter
+	submitCount     *prometheus.CounterVec
 	submitFailCount prometheus.Counter
 	submitValue     *prometheus.GaugeVec
 	psr             *psr.Psr
-	lastSubmitValue map[int64]float64
-	lastSubmitTime  map[int64]time.Time
-	reqIDs          []int64
+	requests        []*request
+	// transactMtx sends one transaction at a time as all request IDs use the nonce of the same account.
+	transactMtx sync.Mutex
 }
 
 func New(
 	ctx context.Context,
 	logger log.Logger,
 	cfg Config,
-	client *ethclient.Client,
+	client ethereum.EthClient,
 	contract *contracts.ITellorMesosphere,
 	account *ethereum.Account,
 	transactor transactor.Transactor,
@@ -78,27 +95,42 @@ func New(
 		return nil, errors.Wrap(err, "apply filter logger")
 	}
 	logger = log.With(logger, "component", ComponentName)
+
+	var requests []*request
+	for _, reqID := range cfg.RequestIDs {
+		if reqID.MinSubmitPriceChange == 0 {
+			reqID.MinSubmitPriceChange = cfg.MinSubmitPriceChange
+		}
+		if reqID.Heartbeat.Duration == 0 {
+			reqID.Heartbeat = cfg.Heartbeat
+		}
+		requests = append(requests, &request{RequestID: reqID})
+	}
+	if len(requests) == 0 {
+		return nil, errors.New("no request IDs to submit")
+	}
+
 	ctx, close := context.WithCancel(ctx)
 	submitter := &Submitter{
-		ctx:             ctx,
-		close:           close,
-		client:          client,
-		cfg:             cfg,
-		account:         account,
-		logger:          logger,
-		contract:        contract,
-		transactor:      transactor,
-		psr:             psr,
-		reqIDs:          []int64{1, 2},
-		lastSubmitValue: make(map[int64]float64),
-		lastSubmitTime:  make(map[int64]time.Time),
-		submitCount: promauto.NewCounter(prometheus.CounterOpts{
+		ctx:        ctx,
+		close:      close,
+		client:     client,
+		cfg:        cfg,
+		account:    account,
+		logger:     logger,
+		contract:   contract,
+		transactor: transactor,
+		psr:        psr,
+		requests:   requests,
+		submitCount: promauto.NewCounterVec(prometheus.CounterOpts{
 			Namespace:   "telliot",
 			Subsystem:   ComponentName,
 			Name:        "submit_total",
-			Help:        "The total number of submitted solutions",
+			Help:        "The total number of submitted solutions by the reason for the submit",
 			ConstLabels: prometheus.Labels{"account": account.Address.String()},
-		}),
+		},
+			[]string{"id", "reason"},
+		),
 		submitFailCount: promauto.NewCounter(prometheus.CounterOpts{
 			Namespace:   "telliot",
 			Subsystem:   ComponentName,
@@ -117,54 +149,51 @@ func New(
 		),
 	}
 
-	// Set the initial values
-	for _, reqID := range submitter.reqIDs {
-		submitter.lastSubmitValue[reqID] = 0
-		submitter.lastSubmitTime[reqID] = time.Unix(0, 0)
-	}
-
 	return submitter, nil
 }
 
+// Start checks all request IDs in parallel and
+// submits the values that deviate enough or are due for a heartbeat.
 func (self *Submitter) Start() error {
-	for _, reqID := range self.reqIDs {
-		exists, val, ts, err := self.contract.GetCurrentValue(&bind.CallOpts{Context: self.ctx}, big.NewInt(1))
-		if err != nil {
-			level.Error(self.logger).Log("msg", "retrieve current value while checking for last submit", "reqID", reqID, "err", err)
-			break
-		}
-		if !exists {
-			level.Error(self.logger).Log("msg", "current value doesn't exist for checking for last submit", "reqID", reqID)
-			break
-		}
-		self.lastSubmitValue[reqID] = float64(val.Int64())
-		self.lastSubmitTime[reqID] = time.Unix(ts.Int64(), 0)
-		level.Debug(self.logger).Log(
-			"msg", "recorded initial values",
-			"reqID", reqID,
-			"lastSubmitValue", self.lastSubmitValue[reqID],
-			"lastSubmitTime", time.Since(self.lastSubmitTime[reqID]),
-		)
+	var wg sync.WaitGroup
+	for _, req := range self.requests {
+		wg.Add(1)
+		go func(req *request) {
+			defer wg.Done()
+			self.run(req)
+		}(req)
 	}
+	wg.Wait()
+	return self.ctx.Err()
+}
 
-	for _, reqID := range self.reqIDs {
-		if err := self.Submit(reqID); err != nil {
-			level.Error(self.logger).Log("msg", "submit", "err", err)
-		}
+func (self *Submitter) run(req *request) {
+	logger := log.With(self.logger, "reqID", req.ID)
+	exists, val, ts, err := self.contract.GetCurrentValue(&bind.CallOpts{Context: self.ctx}, big.NewInt(req.ID))
+	if err != nil {
+		level.Error(logger).Log("msg", "retrieve current value while checking for last submit", "err", err)
+	} else if !exists {
+		level.Info(logger).Log("msg", "current value doesn't exist for checking for last submit")
+	} else {
+		req.lastSubmitValue = float64(val.Int64())
+		req.lastSubmitTime = time.Unix(ts.Int64(), 0)
+		level.Debug(logger).Log(
+			"msg", "recorded initial values",
+			"lastSubmitValue", req.lastSubmitValue,
+			"lastSubmitTime", time.Since(req.lastSubmitTime),
+		)
 	}
 
 	ticker := time.NewTicker(self.cfg.MinSubmitPeriod.Duration)
 	defer ticker.Stop()
 	for {
+		if err := self.Submit(req); err != nil {
+			level.Error(logger).Log("msg", "submit", "err", err)
+		}
 		select {
 		case <-self.ctx.Done():
-			return self.ctx.Err()
+			return
 		case <-ticker.C:
-			for _, reqID := range self.reqIDs {
-				if err := self.Submit(reqID); err != nil {
-					level.Error(self.logger).Log("msg", "submit", "reqID", reqID, "err", err)
-				}
-			}
 		}
 	}
 }
@@ -173,7 +202,7 @@ func (self *Submitter) Stop() {
 	self.close()
 }
 
-func (self *Submitter) Submit(reqID int64) error {
+func (self *Submitter) Submit(req *request) error {
 	ctx, cncl := context.WithTimeout(self.ctx, time.Minute)
 	defer cncl()
 	isReporter, err := self.contract.IsReporter(&bind.CallOpts{Context: ctx}, self.account.Address)
@@ -184,26 +213,30 @@ func (self *Submitter) Submit(reqID int64) error {
 		return errors.New("addr not a reporter")
 	}
 
-	val, err := self.psr.GetValue(reqID, time.Now())
+	val, err := self.psr.GetValue(req.ID, time.Now())
 	if err != nil {
 		return errors.Wrap(err, "getting the value from the aggregator")
 	}
 
-	if !self.shouldSubmit(reqID, float64(val)) {
+	reason := self.shouldSubmit(req, float64(val))
+	if reason == "" {
 		return nil
 	}
 	level.Info(self.logger).Log(
 		"msg", "sending values to the chain",
-		"ID", reqID,
+		"ID", req.ID,
 		"val", val,
+		"reason", reason,
 	)
 
 	f := func(auth *bind.TransactOpts) (*types.Transaction, error) {
-		_reqID := big.NewInt(reqID)
+		_reqID := big.NewInt(req.ID)
 		_val := big.NewInt(val)
 		return self.contract.SubmitValue(auth, _reqID, _val)
 	}
+	self.transactMtx.Lock()
 	tx, recieipt, err := self.transactor.Transact(ctx, f)
+	self.transactMtx.Unlock()
 	if err != nil {
 		self.submitFailCount.Inc()
 		return errors.Wrap(err, "submiting a solution")
@@ -211,7 +244,7 @@ func (self *Submitter) Submit(reqID int64) error {
 
 	if recieipt.Status != types.ReceiptStatusSuccessful {
 		self.submitFailCount.Inc()
-		return errors.Wrapf(err, "submiting solution status not success status:%v, tx hash:%v", recieipt.Status, tx.Hash())
+		return errors.Errorf("submiting solution status not success status:%v, tx hash:%v", recieipt.Status, tx.Hash())
 	}
 	level.Info(self.logger).Log("msg", "successfully submited solution",
 		"txHash", tx.Hash().String(),
@@ -221,58 +254,64 @@ func (self *Submitter) Submit(reqID int64) error {
 		"gasLimit", tx.Gas(),
 		"data", fmt.Sprintf("%x", tx.Data()),
 	)
-	self.submitCount.Inc()
+	id := strconv.Itoa(int(req.ID))
+	self.submitCount.With(prometheus.Labels{"id": id, "reason": reason}).(prometheus.Counter).Inc()
 
 	self.submitValue.With(
 		prometheus.Labels{
-			"id": strconv.Itoa(int(reqID)),
+			"id": id,
 		},
 	).(prometheus.Gauge).Set(float64(val))
 
-	self.lastSubmitValue[reqID] = float64(val)
-	self.lastSubmitTime[reqID] = time.Now()
+	req.lastSubmitValue = float64(val)
+	req.lastSubmitTime = time.Now()
 	level.Debug(self.logger).Log(
 		"msg", "recorded new values after a submit",
-		"reqID", reqID,
-		"lastSubmitValue", self.lastSubmitValue[reqID],
-		"lastSubmitTime", time.Since(self.lastSubmitTime[reqID]),
+		"reqID", req.ID,
+		"lastSubmitValue", req.lastSubmitValue,
+		"lastSubmitTime", time.Since(req.lastSubmitTime),
 	)
 	return nil
 }
 
-func (self *Submitter) shouldSubmit(reqID int64, newVal float64) bool {
-	logger := log.With(self.logger, "msg", "should submit check passed", "reqID", reqID)
+// Reasons for submitting a value.
+const (
+	ReasonFirst     = "first"
+	ReasonHeartbeat = "heartbeat"
+	ReasonDeviation = "deviation"
+)
 
-	if self.lastSubmitTime[reqID].IsZero() {
+// shouldSubmit returns the reason to submit the new value or
+// an empty string when it doesn't need a submit.
+func (self *Submitter) shouldSubmit(req *request, newVal float64) string {
+	logger := log.With(self.logger, "msg", "should submit check passed", "reqID", req.ID)
+
+	if req.lastSubmitTime.IsZero() {
 		level.Info(logger).Log(
-			"reason", "first submit",
+			"reason", ReasonFirst,
 		)
-		return true
+		return ReasonFirst
 	}
 
-	if lastSubmitTime, ok := self.lastSubmitTime[reqID]; ok && time.Since(lastSubmitTime) > (5*time.Minute) {
+	if timePassed := time.Since(req.lastSubmitTime); timePassed > req.Heartbeat.Duration {
 		level.Info(logger).Log(
-			"reason", "more then 5 minutes since last submit",
-			"timePassed", time.Since(lastSubmitTime),
+			"reason", ReasonHeartbeat,
+			"timePassed", timePassed,
+			"heartbeat", req.Heartbeat,
 		)
-		return true
-	}
-
-	lastSubmitValue, ok := self.lastSubmitValue[reqID]
-	if !ok {
-		level.Error(self.logger).Log("msg", "last value check - no record for last value")
+		return ReasonHeartbeat
 	}
 
-	PercentageDiff := math.Abs(mathU.PercentageDiff(lastSubmitValue, newVal))
-	if PercentageDiff > self.cfg.MinSubmitPriceChange {
+	PercentageDiff := math.Abs(mathU.PercentageDiff(req.lastSubmitValue, newVal))
+	if PercentageDiff > req.MinSubmitPriceChange {
 		level.Info(logger).Log(
-			"reason", "value change more then threshold",
+			"reason", ReasonDeviation,
 			"PercentageDiff", PercentageDiff,
-			"percentageThresohld", self.cfg.MinSubmitPriceChange,
-			"lastSubmitValue", lastSubmitValue,
+			"percentageThresohld", req.MinSubmitPriceChange,
+			"lastSubmitValue", req.lastSubmitValue,
 			"newValue", newVal,
 		)
-		return true
+		return ReasonDeviation
 	}
-	return false
+	return ""
 }
@@ -0,0 +1,32 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tellorMesosphere
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestShouldSubmit(t *testing.T) {
+	submitter := &Submitter{logger: logging.NewLogger()}
+	req := &request{RequestID: RequestID{
+		ID:                   2,
+		MinSubmitPriceChange: 1,
+		Heartbeat:            format.Duration{Duration: time.Hour},
+	}}
+
+	testutil.Equals(t, ReasonFirst, submitter.shouldSubmit(req, 100))
+
+	req.lastSubmitValue, req.lastSubmitTime = 100, time.Now()
+	testutil.Equals(t, "", submitter.shouldSubmit(req, 100.5))
+	testutil.Equals(t, ReasonDeviation, submitter.shouldSubmit(req, 102))
+	testutil.Equals(t, ReasonDeviation, submitter.shouldSubmit(req, 98))
+
+	req.lastSubmitTime = time.Now().Add(-2 * time.Hour)
+	testutil.Equals(t, ReasonHeartbeat, submitter.shouldSubmit(req, 100))
+}
@@ -0,0 +1,141 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tasker
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/kit/log/level"
+	"github.com/tellor-io/telliot/pkg/mining"
+)
+
+// Never is the wait of an account that can't submit until its stake status changes.
+const Never = time.Duration(math.MaxInt64)
+
+// SubmitEligibility reports when the account of a submitter can send its next solution.
+type SubmitEligibility interface {
+	// NextSubmit returns the time until the account can submit.
+	// It returns Never when the account is not in a status that can submit.
+	NextSubmit() (time.Duration, error)
+}
+
+// AddSubmitEligibility registers the eligibility check for an account.
+// Accounts without a check always get the current challenge.
+func (self *Tasker) AddSubmitEligibility(addr common.Address, eligibility SubmitEligibility) {
+	self.eligibilities[addr.String()] = eligibility
+}
+
+// eligibility is the result of the last check for an account.
+type eligibility struct {
+	checked time.Time
+	wait    time.Duration
+}
+
+// countdown returns the seconds left until the account can submit.
+func (self eligibility) countdown() float64 {
+	if self.wait == Never {
+		return math.Inf(1)
+	}
+	left := self.wait - time.Since(self.checked)
+	if left < 0 {
+		return 0
+	}
+	return left.Seconds()
+}
+
+// schedule sends the current challenge to the accounts that can submit now and
+// pauses the miners of the other accounts so that all hash power goes to the eligible ones.
+// The paused accounts are checked again when their wait ends.
+// Accounts that are not in a status that can submit are checked again after the check interval.
+func (self *Tasker) schedule(newChallenge bool) {
+	if self.challenge == nil {
+		return
+	}
+	var recheck time.Duration
+	for _, acc := range self.accounts {
+		addr := acc.Address.String()
+		wait := self.nextSubmit(acc.Address)
+
+		var work *mining.Work
+		if wait > 0 {
+			if wait == Never {
+				wait = self.cfg.EligibilityCheckInterval.Duration
+			}
+			if recheck == 0 || wait < recheck {
+				recheck = wait
+			}
+			if !self.mining[addr] {
+				continue // Already paused.
+			}
+			level.Info(self.logger).Log("msg", "pausing the mining until the account can submit",
+				"addr", addr,
+				"countdown", self.countdown(addr),
+			)
+		} else {
+			if !newChallenge && self.mining[addr] {
+				continue // Already mining the current challenge.
+			}
+			level.Info(self.logger).Log("msg", "new event",
+				"addr", addr,
+				"challenge", fmt.Sprintf("%x", self.challenge.Challenge),
+				"difficulty", self.challenge.Difficulty,
+				"requestIDs", fmt.Sprintf("%+v", self.challenge.RequestIDs),
+			)
+			work = &mining.Work{Challenge: self.challenge, PublicAddr: addr, Start: uint64(rand.Int63()), N: math.MaxInt64}
+		}
+
+		select {
+		case self.workSinks[addr] <- work:
+			self.mining[addr] = work != nil
+		case <-self.ctx.Done():
+			return
+		}
+	}
+
+	if !self.recheck.Stop() {
+		select {
+		case <-self.recheck.C:
+		default:
+		}
+	}
+	if recheck > 0 {
+		self.recheck.Reset(recheck)
+	}
+}
+
+// nextSubmit returns the time until the account can submit.
+// When the check fails the account keeps mining as the submitter checks again before submitting.
+func (self *Tasker) nextSubmit(addr common.Address) time.Duration {
+	check, ok := self.eligibilities[addr.String()]
+	if !ok {
+		return 0
+	}
+	wait, err := check.NextSubmit()
+	if err != nil {
+		level.Warn(self.logger).Log("msg", "checking when the account can submit so mining anyway", "addr", addr.String(), "err", err)
+		wait = 0
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.checks[addr.String()] = eligibility{checked: time.Now(), wait: wait}
+	return wait
+}
+
+// countdown returns the time until the account can submit in a human readable form.
+func (self *Tasker) countdown(addr string) string {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	countdown := self.checks[addr].countdown()
+	if math.IsInf(countdown, 1) {
+		return "not in a status that can submit"
+	}
+	return time.Duration(countdown * float64(time.Second)).Round(time.Second).String()
+}
@@ -0,0 +1,122 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tasker
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/events"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/mining"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// eligibleAt is an account that can submit after the given time.
+type eligibleAt struct {
+	mtx sync.Mutex
+	at  time.Time
+	// never is set when the account is not in a status that can submit.
+	never bool
+}
+
+func (self *eligibleAt) set(at time.Time, never bool) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.at, self.never = at, never
+}
+
+func (self *eligibleAt) NextSubmit() (time.Duration, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.never {
+		return Never, nil
+	}
+	return time.Until(self.at), nil
+}
+
+func TestSchedule(t *testing.T) {
+	logger := logging.NewLogger()
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+
+	accounts, err := simulation.NewAccounts(3)
+	testutil.Ok(t, err)
+	backend, err := simulation.NewBackend(logger, simulation.Config{
+		Difficulty: big.NewInt(100),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+	})
+	testutil.Ok(t, err)
+	contract, err := contracts.NewITellor(backend)
+	testutil.Ok(t, err)
+	hub, err := events.New(logger, ctx, events.Config{LogLevel: "info"}, backend, contract.Address)
+	testutil.Ok(t, err)
+
+	cfg := Config{
+		LogLevel:                 "info",
+		ConfirmationDepth:        1,
+		EligibilityCheckInterval: format.Duration{Duration: 100 * time.Millisecond},
+	}
+	tasker, workSinks, err := New(ctx, logger, cfg, backend, contract, hub, accounts)
+	testutil.Ok(t, err)
+
+	eligible, waiting, notStaked := &eligibleAt{}, &eligibleAt{at: time.Now().Add(300 * time.Millisecond)}, &eligibleAt{never: true}
+	tasker.AddSubmitEligibility(accounts[0].Address, eligible)
+	tasker.AddSubmitEligibility(accounts[1].Address, waiting)
+	tasker.AddSubmitEligibility(accounts[2].Address, notStaked)
+
+	go func() {
+		testutil.Ok(t, tasker.Start())
+	}()
+	defer tasker.Stop()
+
+	receive := func(i int) *mining.Work {
+		select {
+		case work := <-workSinks[accounts[i].Address.String()]:
+			return work
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for the work of account:%v", i)
+		}
+		return nil
+	}
+	noWork := func(i int) {
+		select {
+		case work := <-workSinks[accounts[i].Address.String()]:
+			t.Fatalf("account:%v shouldn't receive work:%+v", i, work)
+		default:
+		}
+	}
+
+	// Only the eligible account gets the challenge at the start.
+	start := time.Now()
+	work := receive(0)
+	challenge := backend.CurrentChallenge()
+	testutil.Equals(t, challenge[:], work.Challenge.Challenge)
+	noWork(1)
+	noWork(2)
+
+	// The waiting account gets the same challenge when its wait ends.
+	work = receive(1)
+	testutil.Assert(t, time.Since(start) >= 250*time.Millisecond, "the waiting account got the work too early")
+	testutil.Equals(t, challenge[:], work.Challenge.Challenge)
+	testutil.Equals(t, accounts[1].Address.String(), work.PublicAddr)
+	noWork(2)
+	testutil.Equals(t, "not in a status that can submit", tasker.countdown(accounts[2].Address.String()))
+
+	// An account that can't submit anymore is paused.
+	eligible.set(time.Time{}, true)
+	testutil.Assert(t, receive(0) == nil, "the account should be paused")
+
+	// The not staked account is checked again on every interval.
+	notStaked.set(time.Time{}, false)
+	work = receive(2)
+	testutil.Equals(t, challenge[:], work.Challenge.Challenge)
+	testutil.Equals(t, "0s", tasker.countdown(accounts[2].Address.String()))
+}
@@ -6,30 +6,31 @@ package tasker
 import (
 	"context"
 	"fmt"
-	"math"
-	"math/rand"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/event"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/contracts/tellor"
 	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
+	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/logging"
 	"github.com/tellor-io/telliot/pkg/mining"
 )
 
 const ComponentName = "taskerNewChallenge"
 
-const defaultDelay = 10 * time.Second
-
 type Config struct {
 	LogLevel string
+	// ConfirmationDepth is the number of blocks for a new challenge to be considered final.
+	ConfirmationDepth uint64 `help:"Number of confirmations before mining a new challenge."`
+	// EligibilityCheckInterval is how often to check again the accounts that are not in a status that can submit.
+	EligibilityCheckInterval format.Duration `help:"How often to check again if accounts that are not staked can submit."`
 }
 
 // SubmitCanceler will be used to cancel current submits when new event arrives.
@@ -43,18 +44,31 @@ type Tasker struct {
 	logger          log.Logger
 	accounts        []*ethereum.Account
 	contract        *contracts.ITellor
-	client          *ethclient.Client
+	client          ethereum.EthClient
+	newChallenges   <-chan *tellor.ITellorNewChallenge
 	workSinks       map[string]chan *mining.Work
 	SubmitCancelers []SubmitCanceler
-	txPending       context.CancelFunc
+	// current is the last challenge sent to the miners.
+	current [32]byte
+
+	cfg           Config
+	eligibilities map[string]SubmitEligibility
+	// mtx protects the checks which are also read by the countdown metrics.
+	mtx    sync.Mutex
+	checks map[string]eligibility
+	// challenge is the current challenge and mining holds the accounts that mine it.
+	challenge *mining.MiningChallenge
+	mining    map[string]bool
+	recheck   *time.Timer
 }
 
 func New(
 	ctx context.Context,
 	logger log.Logger,
 	cfg Config,
-	client *ethclient.Client,
+	client ethereum.EthClient,
 	contract *contracts.ITellor,
+	hub *events.Hub,
 	accounts []*ethereum.Account,
 ) (*Tasker, map[string]chan *mining.Work, error) {
 	ctx, close := context.WithCancel(ctx)
@@ -67,6 +81,8 @@ func New(
 		close()
 		return nil, nil, errors.Wrap(err, "apply filter logger")
 	}
+	recheck := time.NewTimer(time.Hour)
+	recheck.Stop()
 	tasker := &Tasker{
 		ctx:             ctx,
 		close:           close,
@@ -75,7 +91,27 @@ func New(
 		workSinks:       workSinks,
 		logger:          log.With(logger, "component", ComponentName),
 		client:          client,
+		newChallenges:   hub.NewChallenge(cfg.ConfirmationDepth),
 		SubmitCancelers: make([]SubmitCanceler, 0),
+		cfg:             cfg,
+		eligibilities:   make(map[string]SubmitEligibility),
+		checks:          make(map[string]eligibility),
+		mining:          make(map[string]bool),
+		recheck:         recheck,
+	}
+	for _, acc := range accounts {
+		addr := acc.Address.String()
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   "telliot",
+			Subsystem:   ComponentName,
+			Name:        "eligibility_countdown_seconds",
+			Help:        "The seconds until the account can submit its next solution. +Inf when the account is not in a status that can submit.",
+			ConstLabels: prometheus.Labels{"addr": addr},
+		}, func() float64 {
+			tasker.mtx.Lock()
+			defer tasker.mtx.Unlock()
+			return tasker.checks[addr].countdown()
+		})
 	}
 	return tasker, tasker.workSinks, nil
 }
@@ -84,154 +120,74 @@ func (self *Tasker) AddSubmitCanceler(SubmitCanceler SubmitCanceler) {
 	self.SubmitCancelers = append(self.SubmitCancelers, SubmitCanceler)
 }
 
-func (self *Tasker) newSub(output chan *tellor.ITellorNewChallenge) (event.Subscription, error) {
-	tellorFilterer, err := tellor.NewITellorFilterer(self.contract.Address, self.client)
-	if err != nil {
-		return nil, errors.Wrap(err, "getting filter instance")
-	}
-	sub, err := tellorFilterer.WatchNewChallenge(&bind.WatchOpts{Context: self.ctx}, output, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "getting subscription channel")
-	}
-	return sub, nil
-}
-
 func (self *Tasker) sendWork(challenge *tellor.ITellorNewChallenge) {
-	newChallenge := &mining.MiningChallenge{
+	self.current = challenge.CurrentChallenge
+	self.challenge = &mining.MiningChallenge{
 		Challenge:  challenge.CurrentChallenge[:],
 		Difficulty: challenge.Difficulty,
 		RequestIDs: challenge.CurrentRequestId,
 	}
-	for _, acc := range self.accounts {
-		level.Info(self.logger).Log("msg", "new event",
-			"addr", acc.Address.String(),
-			"challenge", fmt.Sprintf("%x", newChallenge.Challenge),
-			"difficulty", newChallenge.Difficulty,
-			"requestIDs", fmt.Sprintf("%+v", newChallenge.RequestIDs),
-		)
+	self.schedule(true)
+}
+
+func (self *Tasker) Start() error {
+	level.Info(self.logger).Log("msg", "starting")
+
+	level.Info(self.logger).Log("msg", "sending the initial event")
+	if err := self.sendCurrent(); err != nil {
+		return err
+	}
 
+	for {
 		select {
-		case self.workSinks[acc.Address.String()] <- &mining.Work{Challenge: newChallenge, PublicAddr: acc.Address.String(), Start: uint64(rand.Int63()), N: math.MaxInt64}:
 		case <-self.ctx.Done():
-			return
+			return nil
+		case <-self.recheck.C:
+			self.schedule(false)
+		case event := <-self.newChallenges:
+			level.Debug(self.logger).Log("msg", "new event", "reorg", event.Raw.Removed)
+			// The challenge was reorged out so
+			// continue with the one that is current on the canonical chain.
+			if event.Raw.Removed {
+				if err := self.sendCurrent(); err != nil {
+					level.Error(self.logger).Log("msg", "sending the current challenge after a reorg", "err", err)
+				}
+				continue
+			}
+			self.cancelPendingSubmits()
+			self.sendWork(event)
 		}
 	}
-
 }
 
-func (self *Tasker) Start() error {
-	var err error
-	ticker := time.NewTicker(defaultDelay)
-	defer ticker.Stop()
-	level.Info(self.logger).Log("msg", "starting")
-
-	// Getting current challenge from the contract.
+// sendCurrent sends the challenge that is current in the contract.
+func (self *Tasker) sendCurrent() error {
 	newVariables, err := self.contract.GetNewCurrentVariables(nil)
 	if err != nil {
 		level.Warn(self.logger).Log("msg", "getting new current variables", "err", err)
 		return errors.Wrap(err, "getting GetNewCurrentVariables")
 	}
+	// The canonical chain might already have a challenge that replaced the reorged one.
+	// Sending it again would cancel the pending submits for it
+	// and the miners would drop the same solutions as duplicates.
+	if newVariables.Challenge == self.current {
+		level.Debug(self.logger).Log("msg", "current challenge already sent", "challenge", fmt.Sprintf("%x", self.current))
+		return nil
+	}
 
-	currentChallenge := &tellor.ITellorNewChallenge{
+	self.cancelPendingSubmits()
+	self.sendWork(&tellor.ITellorNewChallenge{
 		CurrentChallenge: newVariables.Challenge,
 		Difficulty:       newVariables.Difficutly,
 		CurrentRequestId: newVariables.RequestIds,
 		TotalTips:        newVariables.Tip,
-	}
-
-	level.Info(self.logger).Log("msg", "sending the initial event")
-	self.sendWork(currentChallenge)
-
-	// Subscribe and wait until the context cancellation event.
-	events := make(chan *tellor.ITellorNewChallenge)
-	var sub event.Subscription
-
-	// Initial subscription.
-	for {
-		select {
-		case <-self.ctx.Done():
-			return nil
-		default:
-		}
-		sub, err = self.newSub(events)
-		if err != nil {
-			level.Error(self.logger).Log("msg", "initial subscription to events failed")
-			<-ticker.C
-			continue
-		}
-		break
-	}
-
-	for {
-		select {
-		case <-self.ctx.Done():
-			return nil
-		case err := <-sub.Err():
-			if err != nil {
-				level.Error(self.logger).Log(
-					"msg",
-					"subscription error",
-					"err", err)
-			}
-
-			// Trying to resubscribe until it succeeds.
-			for {
-				select {
-				case <-self.ctx.Done():
-					return nil
-				default:
-				}
-				sub, err = self.newSub(events)
-				if err != nil {
-					level.Error(self.logger).Log("msg", "re-subscribing to events failed")
-					<-ticker.C
-					continue
-				}
-				break
-			}
-			level.Info(self.logger).Log("msg", "re-subscribed to events")
-		case event := <-events:
-			level.Debug(self.logger).Log("msg", "new event", "reorg", event.Raw.Removed)
-			if self.txPending != nil {
-				self.txPending()
-				self.txPending = nil
-			}
-
-			if !event.Raw.Removed { // For reorg events just cancel the old TXs without sending this one.
-				ctxPending, ctxPendingCncl := context.WithCancel(self.ctx)
-				self.txPending = ctxPendingCncl
-				go self.sendWhenConfirmed(ctxPending, event)
-			}
-		}
-	}
+	})
+	return nil
 }
 
-func (self *Tasker) sendWhenConfirmed(ctx context.Context, vLog *tellor.ITellorNewChallenge) {
-	ticker := time.NewTicker(defaultDelay)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-		}
-		// Send the event only when the tx that emitted the event has been confirmed.
-		receipt, err := self.client.TransactionReceipt(ctx, vLog.Raw.TxHash)
-		if receipt != nil {
-			// Send it only when the TX ReceiptStatusSuccessful or otherwise ignore.
-			if receipt.Status == types.ReceiptStatusSuccessful {
-				for _, canceler := range self.SubmitCancelers {
-					canceler.CancelPendingSubmit()
-				}
-				self.sendWork(vLog)
-			}
-			return
-		}
-		if err != nil {
-			level.Error(self.logger).Log("msg", "getting TX receipt", "err", err)
-		} else {
-			level.Debug(self.logger).Log("msg", "transaction not yet mined", "tx", vLog.Raw.TxHash)
-		}
+func (self *Tasker) cancelPendingSubmits() {
+	for _, canceler := range self.SubmitCancelers {
+		canceler.CancelPendingSubmit()
 	}
 }
 
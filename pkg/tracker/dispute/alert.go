@@ -0,0 +1,234 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package dispute
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	mathU "github.com/tellor-io/telliot/pkg/math"
+	"github.com/tellor-io/telliot/pkg/web"
+)
+
+// slots is the number of submits for every value.
+const slots = 5
+
+// pendingTTL is how long a disputable submit waits for its challenge to get all submits.
+const pendingTTL = 24 * time.Hour
+
+// Alert is a submitted value outside the tolerance of the PSR value.
+type Alert struct {
+	RequestID  int64     `json:"requestId"`
+	Miner      string    `json:"miner"`
+	Value      float64   `json:"value"`
+	PSRValue   float64   `json:"psrValue"`
+	Deviation  float64   `json:"deviationPercent"`
+	Tolerance  float64   `json:"tolerancePercent"`
+	SubmitTime time.Time `json:"submitTime"`
+	TxHash     string    `json:"txHash"`
+	// Dispute is set when the dispute for the value is prepared.
+	Dispute *PreparedDispute `json:"dispute,omitempty"`
+}
+
+// PreparedDispute has the BeginDispute arguments for a disputable submit.
+type PreparedDispute struct {
+	RequestID  int64 `json:"requestId"`
+	Timestamp  int64 `json:"timestamp"`
+	MinerIndex int64 `json:"minerIndex"`
+	// TxHash is the hash of the signed BeginDispute transaction.
+	TxHash string `json:"txHash"`
+	// RawTx is the signed BeginDispute transaction that can be broadcasted when not sent.
+	RawTx string `json:"rawTx"`
+	Sent  bool   `json:"sent"`
+}
+
+// disputable returns the percent difference between the submitted and the PSR value
+// and whether it is above the tolerance.
+// A zero PSR value means there is no data to compare with so it is never disputable.
+func disputable(value, psrValue, tolerance float64) (float64, bool) {
+	if psrValue == 0 {
+		return 0, false
+	}
+	deviation := math.Abs(mathU.PercentageDiff(psrValue, value))
+	return deviation, deviation > tolerance
+}
+
+// evaluate compares the value at the given index of the submit with the PSR value
+// and raises an alert when it is outside the tolerance.
+func (self *Dispute) evaluate(event *tellor.TellorNonceSubmitted, i int, submitTime time.Time, psrValue float64) {
+	requestID := event.RequestId[i].Int64()
+	tolerance := self.cfg.RequestTolerance(requestID)
+	value := float64(event.Value[i].Int64())
+	deviation, ok := disputable(value, psrValue, tolerance)
+	if !ok {
+		return
+	}
+
+	alert := &Alert{
+		RequestID:  requestID,
+		Miner:      event.Miner.String(),
+		Value:      value,
+		PSRValue:   psrValue,
+		Deviation:  deviation,
+		Tolerance:  tolerance,
+		SubmitTime: submitTime,
+		TxHash:     event.Raw.TxHash.String(),
+	}
+	self.disputable.With(map[string]string{"id": event.RequestId[i].String()}).Inc()
+	self.alert("disputable submit", alert)
+
+	if self.account != nil {
+		self.pending[event.CurrentChallenge] = append(self.pending[event.CurrentChallenge], alert)
+	}
+}
+
+// prepareDisputes creates the BeginDispute transactions for the disputable submits of the challenge.
+// The disputed value timestamp is the time of the last new value at the block of the last submit for the challenge.
+func (self *Dispute) prepareDisputes(event *tellor.TellorNonceSubmitted) {
+	alerts := self.pending[event.CurrentChallenge]
+	delete(self.pending, event.CurrentChallenge)
+	// Drop the submits of challenges that never got all submits because of a reorg.
+	for challenge, pending := range self.pending {
+		if time.Since(pending[0].SubmitTime) > pendingTTL {
+			delete(self.pending, challenge)
+		}
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	// The contract records the value at the block time rounded down to the minute.
+	timestamp, err := self.contract.GetUintVar(
+		&bind.CallOpts{Context: self.ctx, BlockNumber: new(big.Int).SetUint64(event.Raw.BlockNumber)},
+		ethereum.Keccak256([]byte("_TIME_OF_LAST_NEW_VALUE")),
+	)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "getting the value timestamp", "err", err)
+		return
+	}
+
+	for _, alert := range alerts {
+		dispute, err := self.prepareDispute(alert, timestamp)
+		if err != nil {
+			level.Error(self.logger).Log(
+				"msg", "preparing dispute",
+				"id", alert.RequestID,
+				"miner", alert.Miner,
+				"err", err,
+			)
+			continue
+		}
+		alert.Dispute = dispute
+		self.alert("dispute prepared", alert)
+	}
+}
+
+func (self *Dispute) prepareDispute(alert *Alert, timestamp *big.Int) (*PreparedDispute, error) {
+	requestID := big.NewInt(alert.RequestID)
+	inDispute, err := self.contract.ITellor.IsInDispute(&bind.CallOpts{Context: self.ctx}, requestID, timestamp)
+	if err != nil {
+		return nil, errors.Wrap(err, "checking if the value is already disputed")
+	}
+	if inDispute {
+		return nil, errors.New("value already disputed")
+	}
+
+	// The miners are sorted by their submitted values so the index is the position in this list.
+	miners, err := self.contract.GetMinersByRequestIdAndTimestamp(&bind.CallOpts{Context: self.ctx}, requestID, timestamp)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the miners of the value")
+	}
+	minerIndex := -1
+	for i, miner := range miners {
+		if miner.String() == alert.Miner {
+			minerIndex = i
+		}
+	}
+	if minerIndex == -1 {
+		return nil, errors.Errorf("miner not found for value timestamp:%v", timestamp)
+	}
+
+	fee, err := self.contract.GetUintVar(&bind.CallOpts{Context: self.ctx}, ethereum.Keccak256([]byte("_DISPUTE_FEE")))
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the dispute fee")
+	}
+	balance, err := self.contract.BalanceOf(&bind.CallOpts{Context: self.ctx}, self.account.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the TRB balance")
+	}
+	if balance.Cmp(fee) < 0 {
+		return nil, errors.Errorf("insufficient balance for the dispute fee TRB actual:%v, TRB required:%v",
+			mathU.BigInt18eToFloat(balance),
+			mathU.BigInt18eToFloat(fee))
+	}
+
+	auth, err := ethereum.PrepareEthTransaction(self.ctx, self.client, self.account, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "prepare ethereum transaction")
+	}
+	auth.NoSend = !self.cfg.SendDisputes
+	tx, err := self.contract.BeginDispute(auth, requestID, timestamp, big.NewInt(int64(minerIndex)))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the dispute transaction")
+	}
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding the dispute transaction")
+	}
+
+	return &PreparedDispute{
+		RequestID:  alert.RequestID,
+		Timestamp:  timestamp.Int64(),
+		MinerIndex: int64(minerIndex),
+		TxHash:     tx.Hash().String(),
+		RawTx:      hexutil.Encode(rawTx),
+		Sent:       self.cfg.SendDisputes,
+	}, nil
+}
+
+// alert logs the alert and posts it to the webhook.
+func (self *Dispute) alert(msg string, alert *Alert) {
+	keyvals := []interface{}{
+		"msg", msg,
+		"id", alert.RequestID,
+		"miner", alert.Miner,
+		"value", alert.Value,
+		"psrValue", alert.PSRValue,
+		"deviationPercent", alert.Deviation,
+		"tolerancePercent", alert.Tolerance,
+		"tx", alert.TxHash,
+	}
+	if alert.Dispute != nil {
+		keyvals = append(keyvals,
+			"timestamp", alert.Dispute.Timestamp,
+			"minerIndex", alert.Dispute.MinerIndex,
+			"disputeTx", alert.Dispute.TxHash,
+			"sent", alert.Dispute.Sent,
+		)
+	}
+	level.Warn(self.logger).Log(keyvals...)
+
+	if self.cfg.AlertWebhook == "" {
+		return
+	}
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "encoding the alert", "err", err)
+		return
+	}
+	ctx, cncl := context.WithTimeout(self.ctx, 10*time.Second)
+	defer cncl()
+	if _, err := web.Post(ctx, self.cfg.AlertWebhook, payload, nil); err != nil {
+		level.Error(self.logger).Log("msg", "sending the alert to the webhook", "err", err)
+	}
+}
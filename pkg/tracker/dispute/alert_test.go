@@ -0,0 +1,164 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package dispute
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestDisputable(t *testing.T) {
+	cfg := Config{
+		Tolerance:  5,
+		Tolerances: []Tolerance{{ID: 2, Tolerance: 30}},
+	}
+
+	cases := []struct {
+		name       string
+		id         int64
+		value      float64
+		psrValue   float64
+		disputable bool
+	}{
+		{"within tolerance", 1, 1040, 1000, false},
+		{"above", 1, 1250, 1000, true},
+		{"below", 1, 800, 1000, true},
+		{"request id tolerance", 2, 1250, 1000, false},
+		{"no psr value", 1, 1250, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ok := disputable(tc.value, tc.psrValue, cfg.RequestTolerance(tc.id))
+			testutil.Equals(t, tc.disputable, ok)
+		})
+	}
+}
+
+func TestPrepareDisputes(t *testing.T) {
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+
+	accounts, err := simulation.NewAccounts(6)
+	testutil.Ok(t, err)
+	reporter := accounts[5]
+	cfg := simulation.Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Balance:    big.NewInt(1e18),
+		Tokens:     new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+	}
+	var miners [slots]*ethereum.Account
+	for i, acc := range accounts {
+		cfg.Accounts = append(cfg.Accounts, acc.Address)
+		if i < slots {
+			cfg.Stakers = append(cfg.Stakers, acc.Address)
+			miners[i] = acc
+		}
+	}
+	backend, err := simulation.NewBackend(logging.NewLogger(), cfg)
+	testutil.Ok(t, err)
+	// Keep the block time away from a whole minute so that it differs from the value timestamp.
+	backend.AdjustTime(time.Duration(90-time.Now().Unix()%60) * time.Second)
+	contract, err := contracts.NewITellor(backend)
+	testutil.Ok(t, err)
+
+	// The first miner submits an outlier for request ID 1 so after sorting by value it is the last one.
+	var values [slots][5]*big.Int
+	for i := range values {
+		for j := range values[i] {
+			values[i][j] = big.NewInt(int64(1000 + 10*i))
+		}
+	}
+	values[0][0] = big.NewInt(2000)
+	header, err := backend.SubmitValue(miners, values)
+	testutil.Ok(t, err)
+
+	var (
+		mtx    sync.Mutex
+		alerts []Alert
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(&alert))
+		mtx.Lock()
+		alerts = append(alerts, alert)
+		mtx.Unlock()
+	}))
+	defer webhook.Close()
+
+	tracker := &Dispute{
+		logger:   logging.NewLogger(),
+		ctx:      ctx,
+		cfg:      Config{Tolerance: 10, AlertWebhook: webhook.URL, SendDisputes: true},
+		client:   backend,
+		contract: contract,
+		account:  reporter,
+		pending:  make(map[[32]byte][]*Alert),
+		disputable: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "disputable_submits_total",
+		}, []string{"id"}),
+	}
+
+	// Same as the tracker compare all submits and prepare the disputes after the last slot.
+	filterer, err := tellor.NewTellorFilterer(contract.Address, backend)
+	testutil.Ok(t, err)
+	submits, err := filterer.FilterNonceSubmitted(&bind.FilterOpts{Context: ctx, Start: header.Number.Uint64()}, nil, nil)
+	testutil.Ok(t, err)
+	var n int
+	for ; submits.Next(); n++ {
+		event := submits.Event
+		tracker.evaluate(event, 0, time.Unix(int64(header.Time), 0), 1000)
+		if event.Slot.Int64() == slots-1 {
+			tracker.prepareDisputes(event)
+		}
+	}
+	testutil.Ok(t, submits.Error())
+	testutil.Equals(t, slots, n)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	testutil.Equals(t, 2, len(alerts))
+	testutil.Equals(t, miners[0].Address.String(), alerts[0].Miner)
+	testutil.Equals(t, float64(2000), alerts[0].Value)
+	testutil.Assert(t, alerts[0].Dispute == nil, "the first alert should be sent before the dispute is prepared")
+
+	prepared := alerts[1].Dispute
+	testutil.Assert(t, prepared != nil, "the second alert should have the prepared dispute")
+	valueTime := int64(backend.NewValues()[0].Time)
+	testutil.Equals(t, valueTime, prepared.Timestamp)
+	testutil.Assert(t, valueTime != int64(header.Time), "the value timestamp should be rounded down to the minute")
+	testutil.Equals(t, int64(4), prepared.MinerIndex)
+	testutil.Equals(t, true, prepared.Sent)
+
+	// The sent dispute is for the submitted value.
+	testutil.Equals(t, 1, backend.Pending())
+	backend.Commit()
+	receipt, err := backend.TransactionReceipt(ctx, common.HexToHash(prepared.TxHash))
+	testutil.Ok(t, err)
+	testutil.Equals(t, types.ReceiptStatusSuccessful, receipt.Status)
+	inDispute, err := contract.ITellor.IsInDispute(nil, big.NewInt(1), big.NewInt(valueTime))
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, inDispute)
+	_, _, _, _, miner, reportingParty, _, _, _, err := contract.GetAllDisputeVars(nil, big.NewInt(1))
+	testutil.Ok(t, err)
+	testutil.Equals(t, miners[0].Address, miner)
+	testutil.Equals(t, reporter.Address, reportingParty)
+}
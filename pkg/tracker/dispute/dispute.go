@@ -6,20 +6,22 @@ package dispute
 import (
 	"context"
 	"sort"
-	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/event"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/prometheus/pkg/labels"
 	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
+	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/logging"
 	"github.com/tellor-io/telliot/pkg/math"
 	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
@@ -27,23 +29,55 @@ import (
 
 const ComponentName = "disputeTracker"
 
-const reorgEventWait = 3 * time.Minute
-
 type Config struct {
 	LogLevel string
+	// ConfirmationDepth is the number of blocks for a submit to be considered final.
+	ConfirmationDepth uint64 `help:"Number of confirmations before recording the submitted values."`
+	// Tolerance is the percent difference from the PSR value above which a submitted value is considered disputable.
+	Tolerance float64 `help:"Percent difference between a submitted value and the PSR value above which the value is considered disputable."`
+	// Tolerances overrides the tolerance for some request IDs.
+	Tolerances []Tolerance `help:"Tolerance for request IDs that need a different one than the default."`
+	// Window is how far before and after a disputed value to look for the recorded values.
+	Window format.Duration `help:"How far before and after a disputed value to look for the recorded values."`
+	// AlertWebhook receives a JSON POST for every disputable submit.
+	AlertWebhook string `help:"URL that receives a JSON POST for every disputable submit."`
+	// DisputeAddr is the account used to prepare a BeginDispute transaction for every disputable submit.
+	DisputeAddr string `help:"Account used to prepare a BeginDispute transaction for every disputable submit. Empty disables the preparation."`
+	// SendDisputes sends the prepared transactions instead of only logging them.
+	SendDisputes bool `help:"Send the prepared BeginDispute transactions. Each one costs the dispute fee."`
+}
+
+type Tolerance struct {
+	ID        int64
+	Tolerance float64
+}
+
+// RequestTolerance returns the tolerance for the request ID.
+func (self Config) RequestTolerance(id int64) float64 {
+	for _, t := range self.Tolerances {
+		if t.ID == id {
+			return t.Tolerance
+		}
+	}
+	return self.Tolerance
 }
 
 type Dispute struct {
-	logger        log.Logger
-	ctx           context.Context
-	close         context.CancelFunc
-	cfg           Config
-	tsDB          *tsdb.DB
-	client        *ethclient.Client
-	contract      *contracts.ITellor
-	pendingAppend map[string]context.CancelFunc
-	mtx           sync.Mutex
-	psrTellor     *psrTellor.Psr
+	logger    log.Logger
+	ctx       context.Context
+	close     context.CancelFunc
+	cfg       Config
+	tsDB      *tsdb.DB
+	client    ethereum.EthClient
+	contract  *contracts.ITellor
+	events    <-chan *tellor.TellorNonceSubmitted
+	psrTellor *psrTellor.Psr
+	// account prepares the BeginDispute transactions when set.
+	account *ethereum.Account
+	// pending has the disputable submits by challenge until the challenge
+	// gets all its submits and the disputed value timestamp is known.
+	pending    map[[32]byte][]*Alert
+	disputable *prometheus.CounterVec
 }
 
 func New(
@@ -51,8 +85,9 @@ func New(
 	ctx context.Context,
 	cfg Config,
 	tsDB *tsdb.DB,
-	client *ethclient.Client,
+	client ethereum.EthClient,
 	contract *contracts.ITellor,
+	hub *events.Hub,
 	psrTellor *psrTellor.Psr,
 ) (*Dispute, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
@@ -60,136 +95,91 @@ func New(
 		return nil, errors.Wrap(err, "apply filter logger")
 	}
 	logger = log.With(logger, "component", ComponentName)
+
+	var account *ethereum.Account
+	if cfg.DisputeAddr != "" {
+		account, err = ethereum.GetAccountByPubAddess(cfg.DisputeAddr)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting the dispute account")
+		}
+	}
 	ctx, close := context.WithCancel(ctx)
 
 	return &Dispute{
-		client:        client,
-		contract:      contract,
-		psrTellor:     psrTellor,
-		cfg:           cfg,
-		ctx:           ctx,
-		close:         close,
-		tsDB:          tsDB,
-		logger:        logger,
-		pendingAppend: make(map[string]context.CancelFunc),
+		account: account,
+		pending: make(map[[32]byte][]*Alert),
+		disputable: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "telliot",
+			Subsystem: ComponentName,
+			Name:      "disputable_submits_total",
+			Help:      "The total number of submitted values outside the tolerance of the PSR value",
+		}, []string{"id"}),
+		client:    client,
+		contract:  contract,
+		events:    hub.NonceSubmitted(cfg.ConfirmationDepth),
+		psrTellor: psrTellor,
+		cfg:       cfg,
+		ctx:       ctx,
+		close:     close,
+		tsDB:      tsDB,
+		logger:    logger,
 	}, nil
 }
 
 func (self *Dispute) Start() {
-	var err error
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-
 	logger := log.With(self.logger, "contract", "tellor")
 
-	var sub event.Subscription
-	events := make(chan *tellor.TellorNonceSubmitted)
-
-	for {
-		select {
-		case <-self.ctx.Done():
-			return
-		default:
-		}
-		sub, err = self.newSubTellor(events)
-		if err != nil {
-			level.Error(logger).Log("msg", "initial subscribing to events failed")
-			<-ticker.C
-			continue
-		}
-		break
-	}
-
 	for {
 		select {
 		case <-self.ctx.Done():
 			return
-		case err := <-sub.Err():
-			if err != nil {
-				level.Error(logger).Log(
-					"msg",
-					"subscription error",
-					"err", err)
-			}
-
-			// Trying to resubscribe until it succeeds.
-			for {
-				select {
-				case <-self.ctx.Done():
-					return
-				default:
-				}
-				sub, err = self.newSubTellor(events)
-				if err != nil {
-					level.Error(logger).Log("msg", "re-subscribing to events failed", "err", err)
-					<-ticker.C
-					continue
-				}
-				break
-			}
-			level.Info(logger).Log("msg", "re-subscribed to events")
-		case event := <-events:
+		case event := <-self.events:
 			level.Debug(self.logger).Log(
 				"msg", "new event",
 				"removed", event.Raw.Removed,
 				"hash", event.Raw.TxHash.String()[:8],
 				"miner", event.Miner.String()[:8],
 			)
+			// The values are added only after the confirmation depth so
+			// a retraction means the reorg was deeper than the depth.
 			if event.Raw.Removed {
-				self.removePending(event)
+				level.Warn(logger).Log(
+					"msg", "already recorded submit was reorged out, consider increasing the confirmation depth",
+					"hash", event.Raw.TxHash.String()[:8],
+				)
+				continue
+			}
+			if err := self.addValTellor(event); err != nil {
+				level.Error(logger).Log(
+					"msg", "adding value",
+					"err", err,
+				)
+			}
+			// The last slot records the value so the disputes for it can be prepared.
+			if self.account != nil && event.Slot.Int64() == slots-1 {
+				self.prepareDisputes(event)
 			}
-
-			ctx, cncl := context.WithCancel(self.ctx)
-			self.mtx.Lock()
-			self.pendingAppend[event.Raw.TxHash.String()] = cncl
-			self.mtx.Unlock()
-
-			go func(ctx context.Context) {
-				ticker := time.NewTicker(reorgEventWait) // Wait this long for any re-org events that can cancel this append.
-				defer ticker.Stop()
-
-				select {
-				case <-ticker.C:
-					if err := self.addValTellor(event); err != nil {
-						level.Error(logger).Log(
-							"msg", "adding value",
-							"err", err,
-						)
-					}
-					self.removePending(event)
-				case <-ctx.Done():
-					level.Debug(self.logger).Log("msg", "append canceled", "hash", event.Raw.TxHash.String()[:8])
-					return
-				}
-			}(ctx)
 		}
 	}
 }
 
-// removePending is extracted in a separate function to use defer for unlocking the mutex and
-// avoid forgetting to unlock it for early returns.
-func (self *Dispute) removePending(event *tellor.TellorNonceSubmitted) {
-	self.mtx.Lock()
-	defer self.mtx.Unlock()
-	pendingCncl, ok := self.pendingAppend[event.Raw.TxHash.String()]
-	if !ok {
-		level.Error(self.logger).Log("msg", "missing pending TX for removed event")
-		return
-	}
-	pendingCncl()
-	delete(self.pendingAppend, event.Raw.TxHash.String())
-}
-
 func (self *Dispute) Stop() {
 	self.close()
 }
 
 func (self *Dispute) addValTellor(event *tellor.TellorNonceSubmitted) (err error) {
+	// Compare with the PSR value at the time of the submit.
+	header, err := self.client.HeaderByHash(self.ctx, event.Raw.BlockHash)
+	if err != nil {
+		return errors.Wrap(err, "getting the submit block")
+	}
+	submitTime := time.Unix(int64(header.Time), 0)
+
 	appender := self.tsDB.Appender(self.ctx)
 
-	// Round up the time so that all appends happen with the same TS and
-	// avoid out of order samples errors.
-	ts := timestamp.FromTime(time.Now().Round(5 * time.Second))
+	// Recorded at the submit time so that the values are found around
+	// the disputed value timestamp even when the events arrive late.
+	ts := timestamp.FromTime(submitTime)
 
 	defer func() { // An appender always needs to be committed or rolled back.
 		if err != nil {
@@ -205,34 +195,36 @@ func (self *Dispute) addValTellor(event *tellor.TellorNonceSubmitted) (err error
 
 	for i, valAct := range event.Value {
 		lbls := labels.Labels{
-			labels.Label{Name: "__name__", Value: "oracle_value"},
+			labels.Label{Name: "__name__", Value: OracleValueMetric},
 			labels.Label{Name: "contract", Value: "tellor"},
 			labels.Label{Name: "id", Value: event.RequestId[i].String()},
 			labels.Label{Name: "miner", Value: event.Miner.String()},
 		}
 
 		sort.Sort(lbls) // This is important! The labels need to be sorted to avoid creating the same series with duplicate reference.
-		_, err = appender.Append(0, lbls, ts, float64(valAct.Int64()))
+		err = self.append(appender, lbls, ts, float64(valAct.Int64()))
 		if err != nil {
 			return errors.Wrap(err, "append values to the DB")
 		}
-		valExp, err := self.psrTellor.GetValue(event.RequestId[i].Int64(), time.Now().Add(-reorgEventWait))
+		valExp, err := self.psrTellor.GetValue(event.RequestId[i].Int64(), submitTime)
 		if err != nil {
 			return errors.Wrapf(err, "getting value from the PSR id:%v", event.RequestId[i].Int64())
 		}
 
 		lbls = labels.Labels{
-			labels.Label{Name: "__name__", Value: "psr_value"},
+			labels.Label{Name: "__name__", Value: PSRValueMetric},
 			labels.Label{Name: "contract", Value: "tellor"},
 			labels.Label{Name: "id", Value: event.RequestId[i].String()},
 		}
 
 		sort.Sort(lbls) // This is important! The labels need to be sorted to avoid creating the same series with duplicate reference.
-		_, err = appender.Append(0, lbls, ts, float64(valExp))
+		err = self.append(appender, lbls, ts, float64(valExp))
 		if err != nil {
 			return errors.Wrap(err, "append values to the DB")
 		}
 
+		self.evaluate(event, i, submitTime, float64(valExp))
+
 		level.Debug(self.logger).Log(
 			"msg", "added dispute tracker values",
 			"id", event.RequestId[i].String(),
@@ -245,14 +237,14 @@ func (self *Dispute) addValTellor(event *tellor.TellorNonceSubmitted) (err error
 	return nil
 }
 
-func (self *Dispute) newSubTellor(output chan *tellor.TellorNonceSubmitted) (event.Subscription, error) {
-	tellorFilterer, err := tellor.NewTellorFilterer(self.contract.Address, self.client)
-	if err != nil {
-		return nil, errors.Wrap(err, "getting instance")
-	}
-	sub, err := tellorFilterer.WatchNonceSubmitted(&bind.WatchOpts{Context: self.ctx}, output, nil, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "getting channel")
+// append skips the samples that are older than the recorded ones.
+// These are from the events that the hub backfills after a restart and were already recorded.
+func (self *Dispute) append(appender storage.Appender, lbls labels.Labels, ts int64, val float64) error {
+	_, err := appender.Append(0, lbls, ts, val)
+	switch errors.Cause(err) {
+	case storage.ErrOutOfOrderSample, storage.ErrOutOfBounds, storage.ErrDuplicateSampleForTimestamp:
+		level.Debug(self.logger).Log("msg", "skipping an already recorded sample", "labels", lbls, "err", err)
+		return nil
 	}
-	return sub, nil
+	return err
 }
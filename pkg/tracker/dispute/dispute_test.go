@@ -0,0 +1,106 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package dispute
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/tellor-io/telliot/pkg/aggregator"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/logging"
+	psrTellor "github.com/tellor-io/telliot/pkg/psr/tellor"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestAddValTellor(t *testing.T) {
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+	logger := logging.NewLogger()
+
+	accounts, err := simulation.NewAccounts(slots)
+	testutil.Ok(t, err)
+	cfg := simulation.Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Balance:    big.NewInt(1e18),
+		Tokens:     new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+	}
+	var miners [slots]*ethereum.Account
+	for i, acc := range accounts {
+		cfg.Accounts = append(cfg.Accounts, acc.Address)
+		cfg.Stakers = append(cfg.Stakers, acc.Address)
+		miners[i] = acc
+	}
+	backend, err := simulation.NewBackend(logger, cfg)
+	testutil.Ok(t, err)
+	contract, err := contracts.NewITellor(backend)
+	testutil.Ok(t, err)
+
+	// The PSR values come from the manual data file.
+	manual := filepath.Join(t.TempDir(), "manualData.json")
+	testutil.Ok(t, ioutil.WriteFile(manual, []byte(`{"tellor":{
+		"1":{"VALUE":1,"DATE":4102444800},"2":{"VALUE":1,"DATE":4102444800},"3":{"VALUE":1,"DATE":4102444800},
+		"4":{"VALUE":1,"DATE":4102444800},"5":{"VALUE":1,"DATE":4102444800}}}`), 0600))
+	db, err := tsdb.Open(t.TempDir(), nil, nil, tsdb.DefaultOptions())
+	testutil.Ok(t, err)
+	defer db.Close()
+	agg, err := aggregator.New(logger, ctx, aggregator.Config{LogLevel: "info", ManualDataFile: manual}, db)
+	testutil.Ok(t, err)
+
+	// The submit happens an hour away from the time of the recording
+	// same as for the events that arrive after downtime.
+	backend.AdjustTime(time.Hour)
+	var values [slots][5]*big.Int
+	for i := range values {
+		for j := range values[i] {
+			values[i][j] = big.NewInt(1e6)
+		}
+	}
+	header, err := backend.SubmitValue(miners, values)
+	testutil.Ok(t, err)
+
+	tracker := &Dispute{
+		logger:    logger,
+		ctx:       ctx,
+		cfg:       Config{Tolerance: 10},
+		tsDB:      db,
+		client:    backend,
+		psrTellor: psrTellor.New(logger, psrTellor.Config{}, agg),
+		pending:   make(map[[32]byte][]*Alert),
+		disputable: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "disputable_submits_total",
+		}, []string{"id"}),
+	}
+	filterer, err := tellor.NewTellorFilterer(contract.Address, backend)
+	testutil.Ok(t, err)
+	record := func() {
+		submits, err := filterer.FilterNonceSubmitted(&bind.FilterOpts{Context: ctx, Start: header.Number.Uint64()}, nil, nil)
+		testutil.Ok(t, err)
+		for submits.Next() {
+			testutil.Ok(t, tracker.addValTellor(submits.Event))
+		}
+		testutil.Ok(t, submits.Error())
+	}
+	record()
+	// The events backfilled after a restart are already recorded.
+	record()
+
+	valueTime := time.Unix(int64(backend.NewValues()[0].Time), 0)
+	recommendation, err := Recommend(ctx, db, 1, 1e6, valueTime, 15*time.Minute, 10)
+	testutil.Ok(t, err)
+	testutil.Assert(t, recommendation != nil, "the values should be recorded at the submit time")
+	testutil.Equals(t, int64(header.Time), recommendation.PSRTime.Unix())
+	testutil.Equals(t, slots+1, len(recommendation.Datapoints))
+}
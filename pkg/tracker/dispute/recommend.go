@@ -0,0 +1,118 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package dispute
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/storage"
+	mathU "github.com/tellor-io/telliot/pkg/math"
+)
+
+// Metrics recorded for every submitted value.
+const (
+	OracleValueMetric = "oracle_value"
+	PSRValueMetric    = "psr_value"
+)
+
+// Datapoint is a value recorded by the dispute tracker.
+type Datapoint struct {
+	Time time.Time
+	// Metric is the submitted value or the PSR value at the time of the submit.
+	Metric string
+	// Miner is set only for the submitted values.
+	Miner string
+	Value float64
+}
+
+// Recommendation is a vote suggestion for a disputed value.
+type Recommendation struct {
+	// Support is true when the disputed value is outside the tolerance
+	// of the PSR value so the vote should support the dispute.
+	Support  bool
+	PSRValue float64
+	PSRTime  time.Time
+	// Deviation is the percent difference between the disputed and the PSR value.
+	Deviation float64
+	// Datapoints are the recorded values around the disputed value sorted by time.
+	Datapoints []Datapoint
+}
+
+// Recommend compares a disputed value with the PSR value recorded closest to its time.
+// It returns nil when there is no PSR value recorded within the window before or after that time.
+func Recommend(ctx context.Context, db storage.Queryable, requestID int64, value float64, at time.Time, window time.Duration, tolerance float64) (*Recommendation, error) {
+	querier, err := db.Querier(ctx, timestamp.FromTime(at.Add(-window)), timestamp.FromTime(at.Add(window)))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the db querier")
+	}
+	defer querier.Close()
+
+	id := strconv.FormatInt(requestID, 10)
+	var datapoints []Datapoint
+	for _, metric := range []string{OracleValueMetric, PSRValueMetric} {
+		set := querier.Select(false, nil,
+			labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, metric),
+			labels.MustNewMatcher(labels.MatchEqual, "contract", "tellor"),
+			labels.MustNewMatcher(labels.MatchEqual, "id", id),
+		)
+		for set.Next() {
+			series := set.At()
+			it := series.Iterator()
+			for it.Next() {
+				ts, val := it.At()
+				datapoints = append(datapoints, Datapoint{
+					Time:   timestamp.Time(ts),
+					Metric: metric,
+					Miner:  series.Labels().Get("miner"),
+					Value:  val,
+				})
+			}
+			if err := it.Err(); err != nil {
+				return nil, errors.Wrapf(err, "reading the %v samples", metric)
+			}
+		}
+		if err := set.Err(); err != nil {
+			return nil, errors.Wrapf(err, "selecting the %v series", metric)
+		}
+	}
+	sort.Slice(datapoints, func(i, j int) bool {
+		return datapoints[i].Time.Before(datapoints[j].Time)
+	})
+
+	var closest *Datapoint
+	for i, dp := range datapoints {
+		if dp.Metric != PSRValueMetric {
+			continue
+		}
+		if closest == nil || absDuration(dp.Time.Sub(at)) < absDuration(closest.Time.Sub(at)) {
+			closest = &datapoints[i]
+		}
+	}
+	if closest == nil {
+		return nil, nil
+	}
+
+	deviation := math.Abs(mathU.PercentageDiff(closest.Value, value))
+	return &Recommendation{
+		Support:    deviation > tolerance,
+		PSRValue:   closest.Value,
+		PSRTime:    closest.Time,
+		Deviation:  deviation,
+		Datapoints: datapoints,
+	}, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,62 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package dispute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestRecommend(t *testing.T) {
+	ctx := context.Background()
+	db, err := tsdb.Open(t.TempDir(), nil, nil, tsdb.DefaultOptions())
+	testutil.Ok(t, err)
+	defer db.Close()
+
+	at := time.Now().Truncate(time.Second)
+	appender := db.Appender(ctx)
+	for _, s := range []struct {
+		metric string
+		miner  string
+		offset time.Duration
+		value  float64
+	}{
+		{PSRValueMetric, "", -10 * time.Minute, 900},
+		{OracleValueMetric, "0x1", time.Minute, 1200},
+		{PSRValueMetric, "", time.Minute, 1000},
+		{PSRValueMetric, "", time.Hour, 1500},
+	} {
+		lbls := labels.FromStrings(labels.MetricName, s.metric, "contract", "tellor", "id", "1")
+		if s.miner != "" {
+			lbls = labels.FromStrings(labels.MetricName, s.metric, "contract", "tellor", "id", "1", "miner", s.miner)
+		}
+		_, err := appender.Append(0, lbls, timestamp.FromTime(at.Add(s.offset)), s.value)
+		testutil.Ok(t, err)
+	}
+	testutil.Ok(t, appender.Commit())
+
+	recommendation, err := Recommend(ctx, db, 1, 1250, at, 15*time.Minute, 5)
+	testutil.Ok(t, err)
+	testutil.Assert(t, recommendation != nil, "expected a recommendation")
+	testutil.Equals(t, true, recommendation.Support)
+	testutil.Equals(t, float64(1000), recommendation.PSRValue)
+	testutil.Equals(t, float64(20), recommendation.Deviation)
+	// The sample an hour later is outside the window.
+	testutil.Equals(t, 3, len(recommendation.Datapoints))
+	testutil.Equals(t, "0x1", recommendation.Datapoints[1].Miner)
+
+	recommendation, err = Recommend(ctx, db, 1, 1020, at, 15*time.Minute, 5)
+	testutil.Ok(t, err)
+	testutil.Equals(t, false, recommendation.Support)
+
+	recommendation, err = Recommend(ctx, db, 2, 1200, at, 15*time.Minute, 5)
+	testutil.Ok(t, err)
+	testutil.Assert(t, recommendation == nil, "expected no recommendation without recorded values")
+}
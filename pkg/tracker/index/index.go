@@ -15,7 +15,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/itchyny/gojq"
@@ -62,7 +61,7 @@ func New(
 	ctx context.Context,
 	cfg Config,
 	tsDB *tsdb.DB,
-	client *ethclient.Client,
+	client ethereum.EthClient,
 ) (*IndexTracker, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
 	if err != nil {
@@ -100,7 +99,7 @@ func New(
 	}, nil
 }
 
-func createDataSources(ctx context.Context, cfg Config, client *ethclient.Client) (map[string][]DataSource, error) {
+func createDataSources(ctx context.Context, cfg Config, client ethereum.EthClient) (map[string][]DataSource, error) {
 	// Load index file.
 	byteValue, err := ioutil.ReadFile(cfg.IndexFile)
 	if err != nil {
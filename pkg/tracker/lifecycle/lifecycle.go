@@ -0,0 +1,317 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package lifecycle
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/math"
+)
+
+const ComponentName = "disputeLifecycle"
+
+const (
+	// scanWindow is how far in the past to look for disputes on start.
+	// It covers the 7 days voting and some time for the tally.
+	scanWindow = 10 * 24 * time.Hour
+	// unlockDelay is the time after the tally before the contract allows to unlock the fee.
+	unlockDelay = 24 * time.Hour
+	// doneTTL is how long the done disputes are kept in the state.
+	doneTTL = 30 * 24 * time.Hour
+)
+
+type Config struct {
+	Enabled  bool
+	LogLevel string
+	// ConfirmationDepth is the number of blocks for a new dispute to be considered final.
+	ConfirmationDepth uint64 `help:"Number of confirmations before tracking a new dispute."`
+	// Interval is how often to check the tracked disputes.
+	Interval format.Duration `help:"How often to check the tracked disputes."`
+	// StateFile keeps the tracked disputes between restarts.
+	StateFile string `help:"File with the state of the tracked disputes."`
+	// RetryAfter is how long to wait for a tally or unlock to be mined before sending it again.
+	RetryAfter format.Duration `help:"How long to wait for a sent tally or unlock transaction before sending it again."`
+}
+
+// LifecycleTracker follows the disputes opened or voted by our accounts
+// and tallies these when the voting ends and unlocks their fees when allowed.
+type LifecycleTracker struct {
+	logger   log.Logger
+	ctx      context.Context
+	close    context.CancelFunc
+	cfg      Config
+	client   ethereum.EthClient
+	contract *contracts.ITellor
+	accounts map[string]*ethereum.Account
+	events   <-chan *tellor.ITellorNewDispute
+	state    *state
+	// candidates are the disputes still in voting that none of our accounts opened or voted on yet.
+	candidates map[int64]bool
+	// now is the clock compared with the dispute dates.
+	now func() time.Time
+}
+
+func NewLifecycleTracker(
+	logger log.Logger,
+	ctx context.Context,
+	cfg Config,
+	client ethereum.EthClient,
+	contract *contracts.ITellor,
+	hub *events.Hub,
+	accounts []*ethereum.Account,
+) (*LifecycleTracker, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	logger = log.With(logger, "component", ComponentName)
+
+	state, err := newState(cfg.StateFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the dispute state")
+	}
+	accs := make(map[string]*ethereum.Account)
+	for _, account := range accounts {
+		accs[account.Address.String()] = account
+	}
+
+	ctx, close := context.WithCancel(ctx)
+	return &LifecycleTracker{
+		logger:     logger,
+		ctx:        ctx,
+		close:      close,
+		cfg:        cfg,
+		client:     client,
+		contract:   contract,
+		accounts:   accs,
+		events:     hub.NewDispute(cfg.ConfirmationDepth),
+		state:      state,
+		candidates: make(map[int64]bool),
+		now:        time.Now,
+	}, nil
+}
+
+func (self *LifecycleTracker) Start() error {
+	// Find the disputes opened while not running.
+	if err := self.scan(); err != nil {
+		level.Error(self.logger).Log("msg", "scanning the disputes", "err", err)
+	}
+	self.check()
+
+	ticker := time.NewTicker(self.cfg.Interval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-self.ctx.Done():
+			return nil
+		case event := <-self.events:
+			if event.Raw.Removed {
+				continue
+			}
+			if _, ok := self.state.disputes[event.DisputeId.Int64()]; !ok {
+				self.candidates[event.DisputeId.Int64()] = true
+			}
+			self.check()
+		case <-ticker.C:
+			self.check()
+		}
+	}
+}
+
+func (self *LifecycleTracker) Stop() {
+	self.close()
+}
+
+func (self *LifecycleTracker) scan() error {
+	startBlock, err := ethereum.BlockBefore(self.ctx, self.client, scanWindow)
+	if err != nil {
+		return errors.Wrap(err, "estimating the dispute scan start block")
+	}
+	disputes, err := self.contract.FilterNewDispute(&bind.FilterOpts{Context: self.ctx, Start: startBlock}, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "filter dispute logs")
+	}
+	defer disputes.Close()
+	for disputes.Next() {
+		if _, ok := self.state.disputes[disputes.Event.DisputeId.Int64()]; !ok {
+			self.candidates[disputes.Event.DisputeId.Int64()] = true
+		}
+	}
+	return errors.Wrap(disputes.Error(), "iterating the dispute logs")
+}
+
+// check updates all candidates and tracked disputes and saves the state.
+func (self *LifecycleTracker) check() {
+	for id := range self.candidates {
+		d, err := self.candidate(id)
+		if err != nil {
+			level.Error(self.logger).Log("msg", "checking dispute", "id", id, "err", err)
+			continue
+		}
+		if d != nil {
+			level.Info(self.logger).Log("msg", "tracking dispute", "id", id, "reporter", d.Reporter, "voters", len(d.Voters))
+			self.state.disputes[id] = d
+			delete(self.candidates, id)
+		}
+	}
+
+	for id, d := range self.state.disputes {
+		if d.Stage == StageDone {
+			if self.now().Sub(d.Updated) > doneTTL {
+				delete(self.state.disputes, id)
+			}
+			continue
+		}
+		if err := self.update(d); err != nil {
+			level.Error(self.logger).Log("msg", "updating dispute", "id", id, "stage", d.Stage, "err", err)
+		}
+	}
+
+	if err := self.state.save(); err != nil {
+		level.Error(self.logger).Log("msg", "saving the dispute state", "err", err)
+	}
+}
+
+// candidate returns the dispute when one of our accounts opened or voted on it.
+// Disputes that can't be voted on any more are dropped from the candidates.
+func (self *LifecycleTracker) candidate(id int64) (*Dispute, error) {
+	d := &Dispute{ID: id, Stage: StageVoting}
+	if err := self.refresh(d); err != nil {
+		return nil, err
+	}
+	if _, ok := self.accounts[d.Reporter]; ok || len(d.Voters) > 0 {
+		return d, nil
+	}
+	if d.Stage != StageVoting {
+		delete(self.candidates, id)
+	}
+	return nil, nil
+}
+
+// refresh reads the dispute from the contract and sets its stage.
+func (self *LifecycleTracker) refresh(d *Dispute) error {
+	disputeID := big.NewInt(d.ID)
+	_, executed, passed, _, reportedMiner, reportingParty, _, uintVars, _, err := self.contract.GetAllDisputeVars(&bind.CallOpts{Context: self.ctx}, disputeID)
+	if err != nil {
+		return errors.Wrap(err, "get dispute details")
+	}
+	d.RequestID = uintVars[0].Int64()
+	d.Timestamp = uintVars[1].Int64()
+	d.Miner = reportedMiner.String()
+	d.Reporter = reportingParty.String()
+	d.Fee = math.BigInt18eToFloat(uintVars[8])
+	d.VotingEnds = time.Unix(uintVars[3].Int64(), 0)
+	d.Passed = passed
+	d.Updated = self.now()
+
+	d.Voters = d.Voters[:0]
+	for addr, account := range self.accounts {
+		voted, err := self.contract.DidVote(&bind.CallOpts{Context: self.ctx}, disputeID, account.Address)
+		if err != nil {
+			return errors.Wrap(err, "check if voted")
+		}
+		if voted {
+			d.Voters = append(d.Voters, addr)
+		}
+	}
+	sort.Strings(d.Voters)
+
+	if !executed {
+		d.Stage = StageVoting
+		if self.now().After(d.VotingEnds) {
+			d.Stage = StageTally
+		}
+		return nil
+	}
+
+	paid, err := self.contract.GetDisputeUintVars(&bind.CallOpts{Context: self.ctx}, disputeID, ethereum.Keccak256([]byte("_PAID")))
+	if err != nil {
+		return errors.Wrap(err, "get the paid status")
+	}
+	if paid.Sign() > 0 {
+		d.Stage = StageDone
+		return nil
+	}
+	tallyDate, err := self.contract.GetDisputeUintVars(&bind.CallOpts{Context: self.ctx}, disputeID, ethereum.Keccak256([]byte("_TALLY_DATE")))
+	if err != nil {
+		return errors.Wrap(err, "get the tally date")
+	}
+	d.Stage = StageUnlock
+	d.UnlockAt = time.Unix(tallyDate.Int64(), 0).Add(unlockDelay)
+	return nil
+}
+
+// update refreshes a tracked dispute and sends the tally or the unlock when due.
+func (self *LifecycleTracker) update(d *Dispute) error {
+	stage := d.Stage
+	if err := self.refresh(d); err != nil {
+		return err
+	}
+	if d.Stage != stage {
+		level.Info(self.logger).Log("msg", "dispute stage changed", "id", d.ID, "from", stage, "to", d.Stage, "passed", d.Passed)
+		d.Sent = time.Time{}
+	}
+
+	switch {
+	case d.Stage == StageTally:
+	case d.Stage == StageUnlock && self.now().After(d.UnlockAt):
+	default:
+		return nil
+	}
+	if !d.Sent.IsZero() && self.now().Sub(d.Sent) < self.cfg.RetryAfter.Duration {
+		return nil
+	}
+
+	account := self.sender(d)
+	if account == nil {
+		return errors.New("none of our accounts opened or voted on the dispute")
+	}
+	auth, err := ethereum.PrepareEthTransaction(self.ctx, self.client, account, nil)
+	if err != nil {
+		return errors.Wrap(err, "prepare ethereum transaction")
+	}
+	var tx *types.Transaction
+	if d.Stage == StageTally {
+		tx, err = self.contract.TallyVotes(auth, big.NewInt(d.ID))
+		if err != nil {
+			return errors.Wrap(err, "send tally transaction")
+		}
+		d.TallyTx = tx.Hash().String()
+	} else {
+		tx, err = self.contract.UnlockDisputeFee(auth, big.NewInt(d.ID))
+		if err != nil {
+			return errors.Wrap(err, "send unlock fee transaction")
+		}
+		d.UnlockTx = tx.Hash().String()
+	}
+	d.Sent = self.now()
+	level.Info(self.logger).Log("msg", "dispute transaction sent", "id", d.ID, "stage", d.Stage, "account", account.Address.String(), "tx", tx.Hash().String())
+	return nil
+}
+
+// sender returns the account that sends the transactions for the dispute.
+// This is the reporter when it is our account and otherwise the first of our voters.
+func (self *LifecycleTracker) sender(d *Dispute) *ethereum.Account {
+	if account, ok := self.accounts[d.Reporter]; ok {
+		return account
+	}
+	if len(d.Voters) == 0 {
+		return nil
+	}
+	return self.accounts[d.Voters[0]]
+}
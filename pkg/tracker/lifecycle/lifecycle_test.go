@@ -0,0 +1,166 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package lifecycle
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+const slots = 5
+
+// TestLifecycle follows a dispute voted by two of our accounts through all stages on a simulated chain.
+func TestLifecycle(t *testing.T) {
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+
+	accounts, err := simulation.NewAccounts(slots + 3)
+	testutil.Ok(t, err)
+	cfg := simulation.Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Balance:    big.NewInt(1e18),
+		Tokens:     new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+	}
+	var miners [slots]*ethereum.Account
+	for i, acc := range accounts {
+		cfg.Accounts = append(cfg.Accounts, acc.Address)
+		if i < slots {
+			cfg.Stakers = append(cfg.Stakers, acc.Address)
+			miners[i] = acc
+		}
+	}
+	backend, err := simulation.NewBackend(logging.NewLogger(), cfg)
+	testutil.Ok(t, err)
+	contract, err := contracts.NewITellor(backend)
+	testutil.Ok(t, err)
+	chainID, err := backend.NetworkID(ctx)
+	testutil.Ok(t, err)
+
+	var values [slots][5]*big.Int
+	for i := range values {
+		for j := range values[i] {
+			values[i][j] = big.NewInt(1000)
+		}
+	}
+	_, err = backend.SubmitValue(miners, values)
+	testutil.Ok(t, err)
+
+	// The reporter is not our account so the transactions are sent by one of our voters.
+	reporter, voters := accounts[slots], accounts[slots+1:]
+	auth, err := ethereum.PrepareEthTransaction(ctx, backend, reporter, nil)
+	testutil.Ok(t, err)
+	_, err = contract.BeginDispute(auth, big.NewInt(1), big.NewInt(int64(backend.NewValues()[0].Time)), big.NewInt(0))
+	testutil.Ok(t, err)
+	backend.Commit()
+	for _, voter := range voters {
+		auth, err := ethereum.PrepareEthTransaction(ctx, backend, voter, nil)
+		testutil.Ok(t, err)
+		_, err = contract.Vote(auth, big.NewInt(1), true)
+		testutil.Ok(t, err)
+	}
+	backend.Commit()
+
+	state, err := newState(filepath.Join(t.TempDir(), "disputes.json"))
+	testutil.Ok(t, err)
+	tracker := &LifecycleTracker{
+		logger:     logging.NewLogger(),
+		ctx:        ctx,
+		cfg:        Config{RetryAfter: format.Duration{Duration: time.Hour}},
+		client:     backend,
+		contract:   contract,
+		accounts:   map[string]*ethereum.Account{voters[0].Address.String(): voters[0], voters[1].Address.String(): voters[1]},
+		state:      state,
+		candidates: map[int64]bool{1: true},
+		now:        backend.Now,
+	}
+	expectedVoters := []string{voters[0].Address.String(), voters[1].Address.String()}
+	sort.Strings(expectedVoters)
+	sender := common.HexToAddress(expectedVoters[0])
+
+	// checkSent checks that the mined transaction succeeded and was sent by the expected account.
+	checkSent := func(hash string) {
+		receipt, err := backend.TransactionReceipt(ctx, common.HexToHash(hash))
+		testutil.Ok(t, err)
+		testutil.Equals(t, types.ReceiptStatusSuccessful, receipt.Status)
+		tx, _, err := backend.TransactionByHash(ctx, common.HexToHash(hash))
+		testutil.Ok(t, err)
+		from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+		testutil.Ok(t, err)
+		testutil.Equals(t, sender, from)
+	}
+
+	tracker.check()
+	d := tracker.state.disputes[1]
+	testutil.Assert(t, d != nil, "a dispute voted by our accounts should be tracked")
+	testutil.Equals(t, 0, len(tracker.candidates))
+	testutil.Equals(t, StageVoting, d.Stage)
+	testutil.Equals(t, reporter.Address.String(), d.Reporter)
+	testutil.Equals(t, expectedVoters, d.Voters)
+	testutil.Equals(t, 0, backend.Pending())
+
+	backend.AdjustTime(7*24*time.Hour + time.Minute)
+	tracker.check()
+	testutil.Equals(t, StageTally, d.Stage)
+	testutil.Assert(t, d.TallyTx != "", "the tally should be sent when the voting ends")
+
+	// The tally is not sent again until it is due for a retry.
+	tally := d.TallyTx
+	tracker.check()
+	testutil.Equals(t, 1, backend.Pending())
+	tracker.now = func() time.Time { return backend.Now().Add(tracker.cfg.RetryAfter.Duration) }
+	tracker.check()
+	testutil.Equals(t, 2, backend.Pending())
+	testutil.Assert(t, d.TallyTx != tally, "the tally should be sent again after the retry period")
+	tracker.now = backend.Now
+	backend.Commit()
+	checkSent(tally)
+
+	tracker.check()
+	testutil.Equals(t, StageUnlock, d.Stage)
+	testutil.Equals(t, true, d.Passed)
+	tallyDate, err := contract.GetDisputeUintVars(nil, big.NewInt(1), ethereum.Keccak256([]byte("_TALLY_DATE")))
+	testutil.Ok(t, err)
+	testutil.Equals(t, time.Unix(tallyDate.Int64(), 0).Add(24*time.Hour), d.UnlockAt)
+	testutil.Equals(t, 0, backend.Pending())
+
+	backend.AdjustTime(24*time.Hour + time.Minute)
+	tracker.check()
+	testutil.Equals(t, StageUnlock, d.Stage)
+	testutil.Assert(t, d.UnlockTx != "", "the unlock should be sent a day after the tally")
+	testutil.Equals(t, 1, backend.Pending())
+	backend.Commit()
+	checkSent(d.UnlockTx)
+
+	tracker.check()
+	testutil.Equals(t, StageDone, d.Stage)
+	testutil.Equals(t, 0, backend.Pending())
+}
+
+func TestSender(t *testing.T) {
+	accounts, err := simulation.NewAccounts(3)
+	testutil.Ok(t, err)
+	tracker := &LifecycleTracker{accounts: make(map[string]*ethereum.Account)}
+	for _, acc := range accounts[:2] {
+		tracker.accounts[acc.Address.String()] = acc
+	}
+	reporter, voter, other := accounts[0].Address.String(), accounts[1].Address.String(), accounts[2].Address.String()
+
+	testutil.Equals(t, accounts[0], tracker.sender(&Dispute{Reporter: reporter, Voters: []string{voter}}))
+	testutil.Equals(t, accounts[1], tracker.sender(&Dispute{Reporter: other, Voters: []string{voter}}))
+	testutil.Assert(t, tracker.sender(&Dispute{Reporter: other}) == nil, "no sender without our reporter or voters")
+}
@@ -0,0 +1,104 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package lifecycle
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Stages of a dispute.
+const (
+	StageVoting = "voting"
+	// StageTally is a dispute with an ended voting that is not tallied yet.
+	StageTally = "awaiting tally"
+	// StageUnlock is a tallied dispute with a fee that is not paid out yet.
+	StageUnlock = "awaiting unlock"
+	StageDone   = "done"
+)
+
+// Dispute is the state of a dispute opened or voted by our accounts.
+type Dispute struct {
+	ID        int64  `json:"id"`
+	RequestID int64  `json:"requestId"`
+	Timestamp int64  `json:"timestamp"`
+	Miner     string `json:"miner"`
+	Reporter  string `json:"reporter"`
+	// Voters are our accounts that voted on the dispute.
+	Voters     []string  `json:"voters,omitempty"`
+	Fee        float64   `json:"fee"`
+	VotingEnds time.Time `json:"votingEnds"`
+	Stage      string    `json:"stage"`
+	Passed     bool      `json:"passed"`
+	// UnlockAt is when the fee can be unlocked after the tally.
+	UnlockAt time.Time `json:"unlockAt,omitempty"`
+	TallyTx  string    `json:"tallyTx,omitempty"`
+	UnlockTx string    `json:"unlockTx,omitempty"`
+	// Sent is when the last tally or unlock transaction was sent.
+	Sent    time.Time `json:"sent,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// state keeps the tracked disputes in a file so these are not lost after a restart.
+type state struct {
+	path     string
+	disputes map[int64]*Dispute
+}
+
+func newState(path string) (*state, error) {
+	disputes, err := LoadState(path)
+	if err != nil {
+		return nil, err
+	}
+	self := &state{path: path, disputes: make(map[int64]*Dispute)}
+	for _, d := range disputes {
+		self.disputes[d.ID] = d
+	}
+	return self, nil
+}
+
+// LoadState reads the tracked disputes sorted by ID.
+func LoadState(path string) ([]*Dispute, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the state file")
+	}
+	var disputes []*Dispute
+	if err := json.Unmarshal(data, &disputes); err != nil {
+		return nil, errors.Wrapf(err, "parsing the state file:%v", path)
+	}
+	return disputes, nil
+}
+
+// save writes the disputes to a temporary file and renames it
+// so that the file is never left half written.
+func (self *state) save() error {
+	disputes := make([]*Dispute, 0, len(self.disputes))
+	for _, d := range self.disputes {
+		disputes = append(disputes, d)
+	}
+	sort.Slice(disputes, func(i, j int) bool { return disputes[i].ID < disputes[j].ID })
+
+	data, err := json.MarshalIndent(disputes, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "marshaling the state")
+	}
+	if err := os.MkdirAll(filepath.Dir(self.path), 0700); err != nil {
+		return errors.Wrap(err, "creating the state directory")
+	}
+	tmp := self.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return errors.Wrap(err, "writing the state file")
+	}
+	return errors.Wrap(os.Rename(tmp, self.path), "renaming the state file")
+}
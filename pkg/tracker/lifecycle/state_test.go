@@ -0,0 +1,37 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package lifecycle
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db", "disputes.json")
+
+	state, err := newState(path)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(state.disputes))
+
+	votingEnds := time.Unix(1600000000, 0).UTC()
+	state.disputes[2] = &Dispute{ID: 2, Stage: StageUnlock, TallyTx: "0x2"}
+	state.disputes[1] = &Dispute{ID: 1, Stage: StageVoting, VotingEnds: votingEnds, Voters: []string{"0x1"}}
+	testutil.Ok(t, state.save())
+
+	disputes, err := LoadState(path)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(disputes))
+	testutil.Equals(t, int64(1), disputes[0].ID)
+	testutil.Equals(t, votingEnds, disputes[0].VotingEnds.UTC())
+	testutil.Equals(t, []string{"0x1"}, disputes[0].Voters)
+	testutil.Equals(t, "0x2", disputes[1].TallyTx)
+
+	state, err = newState(path)
+	testutil.Ok(t, err)
+	testutil.Equals(t, StageUnlock, state.disputes[2].Stage)
+}
@@ -7,15 +7,11 @@ import (
 	"context"
 	"math/big"
 	"strings"
-	"time"
 
 	"github.com/bluele/gcache"
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/event"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
@@ -23,20 +19,22 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
 	"github.com/tellor-io/telliot/pkg/logging"
 )
 
 const ComponentName = "profitTracker"
 
-const DefaultRetry = 30 * time.Second
-
 type Config struct {
 	LogLevel string
+	// ConfirmationDepth is the number of blocks for a submit or a reward to be considered final.
+	ConfirmationDepth uint64 `help:"Number of confirmations before recording the cost and profit of a submit."`
 }
 
 type ProfitTracker struct {
 	netID            *big.Int
-	client           *ethclient.Client
+	client           ethereum.EthClient
 	logger           log.Logger
 	contractInstance *contracts.ITellor
 	abi              abi.ABI
@@ -44,11 +42,13 @@ type ProfitTracker struct {
 	stop             context.CancelFunc
 	addrs            []common.Address
 	addrsMap         map[common.Address]struct{} // The same as above but used for quick matching.
+	nonceSubmitted   <-chan *tellor.TellorNonceSubmitted
+	transferred      <-chan *tellor.TellorTransferred
+	heads            <-chan *events.Head
 
 	cacheTXsProfit     gcache.Cache
 	cacheTXsCost       gcache.Cache
 	cacheTXsCostFailed gcache.Cache
-	lastFailedBlock    int64
 
 	submitProfit *prometheus.GaugeVec
 	submitCost   *prometheus.GaugeVec
@@ -59,8 +59,9 @@ func NewProfitTracker(
 	logger log.Logger,
 	ctx context.Context,
 	cfg Config,
-	client *ethclient.Client,
+	client ethereum.EthClient,
 	contractInstance *contracts.ITellor,
+	hub *events.Hub,
 	addrs []common.Address,
 ) (*ProfitTracker, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
@@ -94,6 +95,9 @@ func NewProfitTracker(
 		abi:              abi,
 		addrs:            addrs,
 		addrsMap:         addrsMap,
+		nonceSubmitted:   hub.NonceSubmitted(cfg.ConfirmationDepth),
+		transferred:      hub.Transferred(cfg.ConfirmationDepth),
+		heads:            hub.Heads(cfg.ConfirmationDepth),
 		ctx:              ctx,
 		stop:             cncl,
 
@@ -162,58 +166,16 @@ func (self *ProfitTracker) Stop() {
 }
 
 func (self *ProfitTracker) monitorReward() {
-	var err error
-	ticker := time.NewTicker(DefaultRetry)
-	defer ticker.Stop()
-	var sub event.Subscription
-	events := make(chan *tellor.TellorTransferred)
-
 	logger := log.With(self.logger, "event", "Transfer")
 
 	for {
 		select {
 		case <-self.ctx.Done():
 			return
-		default:
-		}
-		sub, err = self.transferSub(events)
-		if err != nil {
-			level.Error(logger).Log("msg", "initial subscribing to events", "err", err)
-			<-ticker.C
-			continue
-		}
-		break
-	}
-
-	for {
-		select {
-		case <-self.ctx.Done():
-			return
-		case err := <-sub.Err():
-			if err != nil {
-				level.Error(logger).Log(
-					"msg",
-					"subscription error",
-					"err", err)
-			}
-
-			// Trying to resubscribe until it succeeds.
-			for {
-				select {
-				case <-self.ctx.Done():
-					return
-				default:
-				}
-				sub, err = self.transferSub(events)
-				if err != nil {
-					level.Error(logger).Log("msg", "re-subscribing to events failed")
-					<-ticker.C
-					continue
-				}
-				break
+		case event := <-self.transferred:
+			if _, ok := self.addrsMap[event.To]; !ok || event.From != (common.Address{}) { // Rewards are minted from the zero address.
+				continue
 			}
-			level.Info(logger).Log("msg", "re-subscribed to events")
-		case event := <-events:
 			logger := log.With(logger, "addr", event.To.String()[:6], "tx", event.Raw.TxHash)
 
 			if event.Raw.Removed {
@@ -227,65 +189,22 @@ func (self *ProfitTracker) monitorReward() {
 				continue
 			}
 
-			self.setProfitWhenConfirmed(logger, event)
+			self.setProfit(logger, event)
 		}
 	}
 }
 
 func (self *ProfitTracker) monitorCost() {
-	var err error
-	ticker := time.NewTicker(DefaultRetry)
-	defer ticker.Stop()
-
 	logger := log.With(self.logger, "event", "NonceSubmitted")
 
-	var sub event.Subscription
-	events := make(chan *tellor.TellorNonceSubmitted)
-
 	for {
 		select {
 		case <-self.ctx.Done():
 			return
-		default:
-		}
-		sub, err = self.nonceSubmittedSub(events)
-		if err != nil {
-			level.Error(logger).Log("msg", "initial subscribing to events failed")
-			<-ticker.C
-			continue
-		}
-		break
-	}
-
-	for {
-		select {
-		case <-self.ctx.Done():
-			return
-		case err := <-sub.Err():
-			if err != nil {
-				level.Error(logger).Log(
-					"msg",
-					"subscription error",
-					"err", err)
-			}
-
-			// Trying to resubscribe until it succeeds.
-			for {
-				select {
-				case <-self.ctx.Done():
-					return
-				default:
-				}
-				sub, err = self.nonceSubmittedSub(events)
-				if err != nil {
-					level.Error(logger).Log("msg", "re-subscribing to events failed", "err", err)
-					<-ticker.C
-					continue
-				}
-				break
+		case event := <-self.nonceSubmitted:
+			if _, ok := self.addrsMap[event.Miner]; !ok {
+				continue
 			}
-			level.Info(logger).Log("msg", "re-subscribed to events")
-		case event := <-events:
 			logger := log.With(logger, "addr", event.Miner.String()[:6], "tx", event.Raw.TxHash)
 
 			if event.Raw.Removed {
@@ -299,251 +218,146 @@ func (self *ProfitTracker) monitorCost() {
 				continue
 			}
 
-			self.setCostWhenConfirmed(logger, event)
+			self.setCost(logger, event)
 		}
 	}
 }
 
-func (self *ProfitTracker) monitorCostFailed() {
-	var err error
-	ticker := time.NewTicker(DefaultRetry)
-	defer ticker.Stop()
+// failedCost is the cost of a failed TX from a given block.
+type failedCost struct {
+	addr common.Address
+	cost float64
+}
 
+func (self *ProfitTracker) monitorCostFailed() {
 	logger := log.With(self.logger, "event", "NewHead")
 
-	var sub event.Subscription
-	events := make(chan *types.Header)
-
 	for {
 		select {
 		case <-self.ctx.Done():
 			return
-		default:
-		}
+		case event := <-self.heads:
+			logger := log.With(logger, "block", event.Number)
 
-		sub, err = self.headSub(events)
-		if err != nil {
-			level.Error(logger).Log("msg", "initial subscribing to events failed")
-			<-ticker.C
-			continue
-		}
-		break
-	}
+			// When the block was reorged out remove the cost of its failed TXs.
+			if event.Removed {
+				val, err := self.cacheTXsCostFailed.Get(event.Hash())
+				if err != nil {
+					continue // No failed TXs in this block.
+				}
+				for _, failed := range val.([]failedCost) {
+					level.Debug(logger).Log("msg", "removing cost from dropped block", "addr", failed.addr, "amount", failed.cost)
+					self.submitCost.With(prometheus.Labels{"addr": failed.addr.String()}).(prometheus.Gauge).Sub(failed.cost)
+				}
+				continue
+			}
 
-	for {
-		select {
-		case <-self.ctx.Done():
-			return
-		case err := <-sub.Err():
+			if !event.Bloom.Test(self.abi.Events["NonceSubmitted"].ID.Bytes()) {
+				continue
+			}
+
+			block, err := self.client.BlockByHash(self.ctx, event.Hash())
 			if err != nil {
-				level.Error(logger).Log(
-					"msg",
-					"subscription error",
-					"err", err)
+				level.Error(logger).Log("msg", "get block by hash", "err", err)
+				continue
 			}
 
-			// Trying to resubscribe until it succeeds.
-			for {
-				select {
-				case <-self.ctx.Done():
-					return
-				default:
-				}
-				sub, err = self.headSub(events)
+			level.Debug(logger).Log("msg", "new block")
+
+			var costs []failedCost
+			for _, tx := range block.Transactions() {
+				logger := log.With(logger, "tx", tx.Hash())
+				level.Debug(logger).Log("msg", "processing TX")
+
+				addr, err := types.Sender(types.LatestSignerForChainID(self.netID), tx)
 				if err != nil {
-					level.Error(logger).Log("msg", "re-subscribing to events failed", "err", err)
-					<-ticker.C
+					level.Error(logger).Log("msg", "get tx sender", "err", err)
 					continue
 				}
-				break
-			}
-			level.Info(logger).Log("msg", "re-subscribed to events")
-		case event := <-events:
-			if event.Bloom.Test(self.abi.Events["NonceSubmitted"].ID.Bytes()) {
-				logger := log.With(logger, "block", event.Number)
-
-				block, err := self.client.BlockByNumber(self.ctx, event.Number)
-				if err != nil {
-					level.Error(logger).Log("msg", "get block by hash", "err", err)
+				if _, ok := self.addrsMap[addr]; !ok {
+					level.Debug(logger).Log("msg", "skipping TX for unregistered address", "addr", addr)
 					continue
 				}
-
-				level.Debug(logger).Log("msg", "new block")
-
-				for _, tx := range block.Transactions() {
-					logger := log.With(logger, "tx", tx.Hash())
-					level.Debug(logger).Log("msg", "processing TX")
-
-					addr, err := types.Sender(types.LatestSignerForChainID(self.netID), tx)
-					if err != nil {
-						level.Error(logger).Log("msg", "get tx sender", "err", err)
-						continue
-					}
-					if _, ok := self.addrsMap[addr]; !ok {
-						level.Debug(logger).Log("msg", "skipping TX for unregistered address", "addr", addr)
-						continue
-					}
-					receipt, err := self.client.TransactionReceipt(self.ctx, tx.Hash())
+				receipt, err := self.client.TransactionReceipt(self.ctx, tx.Hash())
+				if err != nil {
+					level.Error(logger).Log("msg", "receipt retrieval", "err", err)
+					continue
+				} else if receipt != nil && receipt.Status != types.ReceiptStatusSuccessful { // Track only the failed TXs. All other TXs are tracked from the emitted logs.
+					cost, _ := big.NewFloat(0).Mul(big.NewFloat(float64(tx.GasPrice().Int64())), big.NewFloat(float64(receipt.GasUsed))).Float64()
+					cost = cost / 1e18
+					level.Debug(logger).Log("msg", "adding cost", "amount", cost)
+					self.submitCost.With(prometheus.Labels{"addr": addr.String()}).(prometheus.Gauge).Add(cost)
+					costs = append(costs, failedCost{addr: addr, cost: cost})
+
+					balance, err := self.getETHBalance(addr)
 					if err != nil {
-						level.Error(logger).Log("msg", "receipt retrieval", "err", err)
+						level.Error(logger).Log("msg", "getting ETH balance", "err", err)
 						continue
-					} else if receipt != nil && receipt.Status != types.ReceiptStatusSuccessful { // Track only the failed TXs. All other TXs are tracked from the emitted logs.
-						// When it is a reorg event, remove the cost for the dropped blocks.
-						if self.lastFailedBlock >= event.Number.Int64() {
-							level.Debug(logger).Log("msg", "reorg head")
-							for cachedBlock, _cost := range self.cacheTXsCostFailed.GetALL(false) {
-								if cachedBlock.(int64) >= event.Number.Int64() {
-									cost := _cost.(float64)
-									level.Debug(logger).Log("msg", "removing cost from dropped block", "amount", cost)
-									self.submitCost.With(prometheus.Labels{"addr": addr.String()}).(prometheus.Gauge).Sub(cost)
-								}
-							}
-						}
-						self.lastFailedBlock = event.Number.Int64()
-						cost, _ := big.NewFloat(0).Mul(big.NewFloat(float64(tx.GasPrice().Int64())), big.NewFloat(float64(receipt.GasUsed))).Float64()
-						cost = cost / 1e18
-						level.Debug(logger).Log("msg", "adding cost", "amount", cost)
-						self.submitCost.With(prometheus.Labels{"addr": addr.String()}).(prometheus.Gauge).Add(cost)
-
-						if err := self.cacheTXsCostFailed.Set(event.Number.Int64(), cost); err != nil {
-							level.Error(logger).Log("msg", "adding cost to the cache", "err", err)
-						}
-
-						balance, err := self.getETHBalance(addr)
-						if err != nil {
-							level.Error(logger).Log("msg", "getting ETH balance", "err", err)
-							continue
-						}
-						level.Debug(logger).Log("msg", "new ETH balance", "balance", balance)
-						self.balances.With(prometheus.Labels{"addr": addr.String(), "token": "ETH"}).(prometheus.Gauge).Set(balance)
 					}
+					level.Debug(logger).Log("msg", "new ETH balance", "balance", balance)
+					self.balances.With(prometheus.Labels{"addr": addr.String(), "token": "ETH"}).(prometheus.Gauge).Set(balance)
 				}
 			}
-		}
-	}
-}
-
-func (self *ProfitTracker) setCostWhenConfirmed(logger log.Logger, event *tellor.TellorNonceSubmitted) {
-	ticker := time.NewTicker(DefaultRetry)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-self.ctx.Done():
-			level.Debug(logger).Log("msg", "transaction confirmation check canceled")
-			return
-		case <-ticker.C:
-		}
-		receipt, err := self.client.TransactionReceipt(self.ctx, event.Raw.TxHash)
-		if err != nil {
-			level.Error(logger).Log("msg", "receipt retrieval", "err", err)
-		} else if receipt != nil && receipt.Status == types.ReceiptStatusSuccessful { // Failed transactions cost is monitored in a different process.
-			tx, _, err := self.client.TransactionByHash(self.ctx, event.Raw.TxHash)
-			if err != nil {
-				level.Error(logger).Log("msg", "get transaction by hash", "err", err)
-				return
-			}
-			cost, _ := big.NewFloat(0).Mul(big.NewFloat(float64(tx.GasPrice().Int64())), big.NewFloat(float64(receipt.GasUsed))).Float64()
-			cost = cost / 1e18
-			level.Debug(logger).Log("msg", "adding cost", "amount", cost)
-			self.submitCost.With(prometheus.Labels{"addr": event.Miner.String()}).(prometheus.Gauge).Add(cost)
-
-			if err := self.cacheTXsCost.Set(txIDNonceSubmit(event), cost); err != nil {
-				level.Error(logger).Log("msg", "adding cost to the cache", "err", err)
-			}
-
-			balance, err := self.getETHBalance(event.Miner)
-			if err != nil {
-				level.Error(logger).Log("msg", "getting ETH balance", "err", err)
-				return
-			}
-			level.Debug(logger).Log("msg", "new ETH balance", "balance", balance)
-			self.balances.With(prometheus.Labels{"addr": event.Miner.String(), "token": "ETH"}).(prometheus.Gauge).Set(balance)
-			return
-		}
-
-		level.Debug(logger).Log("msg", "transaction not yet mined")
-	}
-}
-
-func (self *ProfitTracker) setProfitWhenConfirmed(logger log.Logger, event *tellor.TellorTransferred) {
-	ticker := time.NewTicker(DefaultRetry)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-self.ctx.Done():
-			level.Debug(logger).Log("msg", "transaction confirmation check canceled")
-			return
-		case <-ticker.C:
-		}
-		receipt, err := self.client.TransactionReceipt(self.ctx, event.Raw.TxHash)
-		if err != nil {
-			level.Error(logger).Log("msg", "receipt retrieval", "err", err)
-			return
-		} else if receipt != nil {
-			if receipt.Status != types.ReceiptStatusSuccessful {
-				level.Error(logger).Log("msg", "event status not success so no profit added", "status", receipt.Status)
-				return
-			}
-
-			trb, _ := big.NewFloat(float64(event.Value.Int64())).Float64()
-			trb = trb / 1e18
-			level.Debug(logger).Log("msg", "adding profit", "amount", trb)
-			self.submitProfit.With(prometheus.Labels{"addr": event.To.String()}).(prometheus.Gauge).Add(trb)
-
-			if err := self.cacheTXsProfit.Set(txIDTransfer(event), trb); err != nil {
-				level.Error(logger).Log("msg", "adding amount to the cache", "err", err)
-			}
-
-			balance, err := self.getTRBBalance(event.To)
-			if err != nil {
-				level.Error(logger).Log("msg", "getting TRB balance", "err", err)
-				return
+			if len(costs) > 0 {
+				if err := self.cacheTXsCostFailed.Set(event.Hash(), costs); err != nil {
+					level.Error(logger).Log("msg", "adding cost to the cache", "err", err)
+				}
 			}
-			level.Debug(logger).Log("msg", "new TRB balance", "balance", balance)
-			self.balances.With(prometheus.Labels{"addr": event.To.String(), "token": "TRB"}).(prometheus.Gauge).Set(balance)
-			return
 		}
-		level.Debug(logger).Log("msg", "transaction not yet mined")
 	}
 }
 
-func (self *ProfitTracker) nonceSubmittedSub(output chan *tellor.TellorNonceSubmitted) (event.Subscription, error) {
-	tellorFilterer, err := tellor.NewTellorFilterer(self.contractInstance.Address, self.client)
+// setCost records the cost of a confirmed submit.
+func (self *ProfitTracker) setCost(logger log.Logger, event *tellor.TellorNonceSubmitted) {
+	receipt, err := self.client.TransactionReceipt(self.ctx, event.Raw.TxHash)
 	if err != nil {
-		return nil, errors.Wrap(err, "getting instance")
+		level.Error(logger).Log("msg", "receipt retrieval", "err", err)
+		return
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful { // Failed transactions cost is monitored in a different process.
+		return
 	}
-	sub, err := tellorFilterer.WatchNonceSubmitted(&bind.WatchOpts{Context: self.ctx}, output, self.addrs, nil)
+	tx, _, err := self.client.TransactionByHash(self.ctx, event.Raw.TxHash)
 	if err != nil {
-		return nil, errors.Wrap(err, "getting channel")
+		level.Error(logger).Log("msg", "get transaction by hash", "err", err)
+		return
 	}
-	return sub, nil
-}
+	cost, _ := big.NewFloat(0).Mul(big.NewFloat(float64(tx.GasPrice().Int64())), big.NewFloat(float64(receipt.GasUsed))).Float64()
+	cost = cost / 1e18
+	level.Debug(logger).Log("msg", "adding cost", "amount", cost)
+	self.submitCost.With(prometheus.Labels{"addr": event.Miner.String()}).(prometheus.Gauge).Add(cost)
 
-func (self *ProfitTracker) transferSub(output chan *tellor.TellorTransferred) (event.Subscription, error) {
-	tellorFilterer, err := tellor.NewTellorFilterer(self.contractInstance.Address, self.client)
-	if err != nil {
-		return nil, errors.Wrap(err, "getting instance")
+	if err := self.cacheTXsCost.Set(txIDNonceSubmit(event), cost); err != nil {
+		level.Error(logger).Log("msg", "adding cost to the cache", "err", err)
 	}
-	sub, err := tellorFilterer.WatchTransferred(
-		&bind.WatchOpts{Context: self.ctx},
-		output,
-		[]common.Address{
-			common.HexToAddress("0x0000000000000000000000000000000000000000"),
-		},
-		self.addrs,
-	)
+
+	balance, err := self.getETHBalance(event.Miner)
 	if err != nil {
-		return nil, errors.Wrap(err, "getting channel")
+		level.Error(logger).Log("msg", "getting ETH balance", "err", err)
+		return
 	}
-	return sub, nil
+	level.Debug(logger).Log("msg", "new ETH balance", "balance", balance)
+	self.balances.With(prometheus.Labels{"addr": event.Miner.String(), "token": "ETH"}).(prometheus.Gauge).Set(balance)
 }
 
-func (self *ProfitTracker) headSub(output chan *types.Header) (event.Subscription, error) {
-	sub, err := self.client.SubscribeNewHead(self.ctx, output)
+// setProfit records the reward of a confirmed submit.
+func (self *ProfitTracker) setProfit(logger log.Logger, event *tellor.TellorTransferred) {
+	trb, _ := big.NewFloat(float64(event.Value.Int64())).Float64()
+	trb = trb / 1e18
+	level.Debug(logger).Log("msg", "adding profit", "amount", trb)
+	self.submitProfit.With(prometheus.Labels{"addr": event.To.String()}).(prometheus.Gauge).Add(trb)
+
+	if err := self.cacheTXsProfit.Set(txIDTransfer(event), trb); err != nil {
+		level.Error(logger).Log("msg", "adding amount to the cache", "err", err)
+	}
+
+	balance, err := self.getTRBBalance(event.To)
 	if err != nil {
-		return nil, errors.Wrap(err, "getting channel")
+		level.Error(logger).Log("msg", "getting TRB balance", "err", err)
+		return
 	}
-	return sub, nil
+	level.Debug(logger).Log("msg", "new TRB balance", "balance", balance)
+	self.balances.With(prometheus.Labels{"addr": event.To.String(), "token": "TRB"}).(prometheus.Gauge).Set(balance)
 }
 
 func (self *ProfitTracker) getTRBBalance(addr common.Address) (float64, error) {
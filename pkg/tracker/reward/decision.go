@@ -0,0 +1,205 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package reward
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql"
+)
+
+// GasPriceMetricName is the TSDB metric for the gas price history.
+const GasPriceMetricName = "gas_price"
+
+// slots is the number of solutions for every challenge.
+const slots = 5
+
+// Action is what the submitter should do with a solution.
+type Action string
+
+const (
+	// ActionSubmit sends the solution now.
+	ActionSubmit Action = "submit"
+	// ActionWait checks again later as a cheaper gas price or slot is expected for the same challenge.
+	ActionWait Action = "wait"
+	// ActionSkip drops the solution as no slot of the challenge is expected to be profitable.
+	ActionSkip Action = "skip"
+)
+
+// Decision is the result of the profit check together with all its inputs.
+type Decision struct {
+	Action Action
+	Reason string
+	// Slot is the slot of the next submit.
+	Slot int64
+	// Reward is the TRB reward with the tip share for a single slot.
+	Reward   *big.Int
+	TRBPrice float64
+	GasPrice *big.Int
+	// GasPriceForecast is the low end of the gas price history.
+	GasPriceForecast *big.Int
+	// GasUsed has the last gas used for the slots still open for the challenge.
+	// Slots without a record are missing.
+	GasUsed       map[int64]*big.Int
+	ProfitPercent int64
+	// ForecastSlot is the slot with the best profit for the forecasted gas price.
+	ForecastSlot          int64
+	ForecastProfitPercent int64
+}
+
+// Log writes the decision and its inputs as a single structured record.
+func (self *Decision) Log(logger log.Logger) {
+	gasUsed := make(map[int64]string, len(self.GasUsed))
+	for slot, gas := range self.GasUsed {
+		gasUsed[slot] = gas.String()
+	}
+	level.Info(logger).Log(
+		"msg", "submit decision",
+		"action", self.Action,
+		"reason", self.Reason,
+		"slot", self.Slot,
+		"reward", fmt.Sprintf("%.2e", new(big.Float).SetInt(self.Reward)),
+		"trbPrice", self.TRBPrice,
+		"gasPrice", self.GasPrice,
+		"gasPriceForecast", self.GasPriceForecast,
+		"gasUsed", fmt.Sprintf("%v", gasUsed),
+		"profitPercent", self.ProfitPercent,
+		"forecastSlot", self.ForecastSlot,
+		"forecastProfitPercent", self.ForecastProfitPercent,
+	)
+}
+
+// Decide forecasts the reward and the gas cost of the next slot and
+// chooses whether to submit now, wait for a cheaper gas price or slot, or skip the challenge.
+// The profit threshold is in percents of the transaction cost.
+func (self *RewardQuerier) Decide(ctx context.Context, gasPrice *big.Int, profitThreshold int64) (*Decision, error) {
+	slot, err := self.Slot()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting current slot")
+	}
+	vars, err := self.contractInstance.GetNewCurrentVariables(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the current tip")
+	}
+	reward, err := self.contractInstance.CurrentReward(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the current reward")
+	}
+	trbPrice, confidence, err := self.aggr.TimeWeightedAvg("TRB/ETH", time.Now(), time.Hour)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the trb price from the aggregator")
+	}
+	if confidence < 0.5 {
+		return nil, errors.Errorf("trb price confidence too low:%v", confidence)
+	}
+
+	decision := &Decision{
+		// The tip of the challenge is split between all slots.
+		Reward:   new(big.Int).Add(reward, new(big.Int).Div(vars.Tip, big.NewInt(slots))),
+		TRBPrice: trbPrice,
+		GasPrice: gasPrice,
+		GasUsed:  make(map[int64]*big.Int),
+	}
+
+	// The slot progress counts the already filled slots so the next one is the current progress.
+	// When all slots are filled the next submit is for slot 0 of the next challenge.
+	decision.Slot = slot.Int64() % slots
+	for s := decision.Slot; s < slots; s++ {
+		gasUsed, err := self.GasUsed(ctx, big.NewInt(s))
+		if _, ok := errors.Cause(err).(ErrNoDataForSlot); ok {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting the gas used for slot:%v", s)
+		}
+		decision.GasUsed[s] = gasUsed
+	}
+
+	decision.GasPriceForecast, err = self.gasPriceForecast(ctx)
+	if err != nil {
+		level.Warn(self.logger).Log("msg", "forecasting the gas price so using the current one", "err", err)
+		decision.GasPriceForecast = gasPrice
+	}
+
+	decide(decision, profitThreshold)
+	return decision, nil
+}
+
+// decide sets the action for the decision based on its inputs.
+func decide(decision *Decision, profitThreshold int64) {
+	gasUsed, ok := decision.GasUsed[decision.Slot]
+	if !ok {
+		decision.Action = ActionSubmit
+		decision.Reason = "no record for the gas used by the slot"
+		return
+	}
+
+	decision.ProfitPercent = profitPercent(decision.Reward, decision.TRBPrice, gasUsed, decision.GasPrice)
+	if decision.ProfitPercent >= profitThreshold {
+		decision.Action = ActionSubmit
+		decision.Reason = "profit above the threshold"
+		return
+	}
+
+	// Only a lower gas price helps so a rising price is forecasted as the current one.
+	forecast := decision.GasPriceForecast
+	if forecast == nil || forecast.Cmp(decision.GasPrice) > 0 {
+		forecast = decision.GasPrice
+	}
+	decision.ForecastSlot = decision.Slot
+	decision.ForecastProfitPercent = decision.ProfitPercent
+	for slot, gasUsed := range decision.GasUsed {
+		profit := profitPercent(decision.Reward, decision.TRBPrice, gasUsed, forecast)
+		if profit > decision.ForecastProfitPercent {
+			decision.ForecastSlot, decision.ForecastProfitPercent = slot, profit
+		}
+	}
+	if decision.ForecastProfitPercent >= profitThreshold {
+		decision.Action = ActionWait
+		decision.Reason = "a cheaper gas price or slot is expected for the challenge"
+		return
+	}
+	decision.Action = ActionSkip
+	decision.Reason = "no slot is expected to be above the profit threshold"
+}
+
+// profitPercent returns the profit in percents of the transaction cost.
+func profitPercent(rewardTRB *big.Int, trbPrice float64, gasUsed, gasPrice *big.Int) int64 {
+	cost, _ := new(big.Float).SetInt(new(big.Int).Mul(gasUsed, gasPrice)).Float64()
+	if cost == 0 {
+		return 0
+	}
+	reward, _ := new(big.Float).Mul(new(big.Float).SetInt(rewardTRB), big.NewFloat(trbPrice)).Float64()
+	return int64((reward - cost) / cost * 100)
+}
+
+// gasPriceForecast returns the gas price reached a quarter of the time within the gas price window.
+func (self *RewardQuerier) gasPriceForecast(ctx context.Context) (*big.Int, error) {
+	query, err := self.engine.NewInstantQuery(
+		self.tsDB,
+		fmt.Sprintf(`quantile_over_time(0.25, %v[%v])`, GasPriceMetricName, model.Duration(self.cfg.GasPriceWindow.Duration)),
+		time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer query.Close()
+	result := query.Exec(ctx)
+	if result.Err != nil {
+		return nil, errors.Wrapf(result.Err, "error evaluating query:%v", query.Statement())
+	}
+	if len(result.Value.(promql.Vector)) == 0 {
+		return nil, errors.New("no gas price history")
+	}
+	forecast, _ := big.NewFloat(result.Value.(promql.Vector)[0].V).Int(nil)
+	return forecast, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package reward
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestDecide(t *testing.T) {
+	gwei := func(v int64) *big.Int {
+		return big.NewInt(v * params.GWei)
+	}
+	// With a TRB price of 0.01 ETH the reward is 0.01 ETH so
+	// 200k gas at 10 gwei costs 0.002 ETH and the profit is 400%.
+	reward := big.NewInt(params.Ether)
+	trbPrice := 0.01
+
+	cases := []struct {
+		name             string
+		slot             int64
+		gasUsed          map[int64]*big.Int
+		gasPrice         *big.Int
+		gasPriceForecast *big.Int
+		action           Action
+		profitPercent    int64
+		forecastSlot     int64
+	}{
+		{
+			name:          "profitable",
+			gasUsed:       map[int64]*big.Int{0: big.NewInt(200000)},
+			gasPrice:      gwei(10),
+			action:        ActionSubmit,
+			profitPercent: 400,
+		},
+		{
+			name:     "no gas used record",
+			slot:     2,
+			gasUsed:  map[int64]*big.Int{0: big.NewInt(200000)},
+			gasPrice: gwei(1000),
+			action:   ActionSubmit,
+		},
+		{
+			name:             "gas price spike",
+			gasUsed:          map[int64]*big.Int{0: big.NewInt(200000)},
+			gasPrice:         gwei(50),
+			gasPriceForecast: gwei(10),
+			action:           ActionWait,
+			profitPercent:    0,
+		},
+		{
+			name:             "cheaper slot",
+			gasUsed:          map[int64]*big.Int{0: big.NewInt(1000000), 1: big.NewInt(200000)},
+			gasPrice:         gwei(10),
+			gasPriceForecast: gwei(10),
+			action:           ActionWait,
+			profitPercent:    0,
+			forecastSlot:     1,
+		},
+		{
+			name:             "rising gas price",
+			slot:             3,
+			gasUsed:          map[int64]*big.Int{3: big.NewInt(200000), 4: big.NewInt(250000)},
+			gasPrice:         gwei(50),
+			gasPriceForecast: gwei(100),
+			action:           ActionSkip,
+			profitPercent:    0,
+			forecastSlot:     3,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decision := &Decision{
+				Slot:             tc.slot,
+				Reward:           reward,
+				TRBPrice:         trbPrice,
+				GasPrice:         tc.gasPrice,
+				GasPriceForecast: tc.gasPriceForecast,
+				GasUsed:          tc.gasUsed,
+			}
+			decide(decision, 100)
+			testutil.Equals(t, tc.action, decision.Action, decision.Reason)
+			testutil.Equals(t, tc.profitPercent, decision.ProfitPercent)
+			if tc.action != ActionSubmit {
+				testutil.Equals(t, tc.forecastSlot, decision.ForecastSlot)
+			}
+		})
+	}
+}
@@ -16,8 +16,6 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -31,23 +29,34 @@ import (
 	"github.com/tellor-io/telliot/pkg/contracts/tellor"
 	"github.com/tellor-io/telliot/pkg/db"
 	eth "github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/gasPrice"
 	"github.com/tellor-io/telliot/pkg/logging"
 )
 
 const ComponentName = "rewardTracker"
-const DefaultRetry = 30 * time.Second
 
 type Config struct {
 	LogLevel string
+	// ConfirmationDepth is the number of blocks for a submit to be considered final.
+	ConfirmationDepth uint64 `help:"Number of confirmations before recording the gas usage of a submit."`
+	// GasPriceInterval is how often the gas price is recorded for the gas price forecast.
+	GasPriceInterval format.Duration `help:"How often to record the gas price used to forecast the gas price for the submit decisions."`
+	// GasPriceWindow is the gas price history used for the forecast.
+	GasPriceWindow format.Duration `help:"The gas price history used to forecast whether a cheaper gas price is expected for a challenge."`
 }
 
 type RewardTracker struct {
-	client           *ethclient.Client
+	client           eth.EthClient
 	logger           log.Logger
 	contractInstance *contracts.ITellor
 	ctx              context.Context
 	stop             context.CancelFunc
 	addr             common.Address
+	events           <-chan *tellor.TellorNonceSubmitted
+	cfg              Config
+	gasPriceQuerier  gasPrice.GasPriceQuerier
 
 	tsDB   *tsdb.DB
 	aggr   aggregator.IAggregator
@@ -59,10 +68,12 @@ func NewRewardTracker(
 	ctx context.Context,
 	cfg Config,
 	tsDB *tsdb.DB,
-	client *ethclient.Client,
+	client eth.EthClient,
 	contractInstance *contracts.ITellor,
+	hub *events.Hub,
 	addr common.Address,
 	aggr aggregator.IAggregator,
+	gasPriceQuerier gasPrice.GasPriceQuerier,
 ) (*RewardTracker, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
 	if err != nil {
@@ -87,6 +98,9 @@ func NewRewardTracker(
 		logger:           logger,
 		contractInstance: contractInstance,
 		addr:             addr,
+		events:           hub.NonceSubmitted(cfg.ConfirmationDepth),
+		cfg:              cfg,
+		gasPriceQuerier:  gasPriceQuerier,
 		ctx:              ctx,
 		stop:             cncl,
 		tsDB:             tsDB,
@@ -98,58 +112,21 @@ func NewRewardTracker(
 func (self *RewardTracker) Start() error {
 	level.Info(self.logger).Log("msg", "starting")
 
-	var err error
-	ticker := time.NewTicker(DefaultRetry)
+	ticker := time.NewTicker(self.cfg.GasPriceInterval.Duration)
 	defer ticker.Stop()
-
-	logger := log.With(self.logger, "event", "NonceSubmitted")
-
-	var sub event.Subscription
-	events := make(chan *tellor.TellorNonceSubmitted)
-	for {
-		select {
-		case <-self.ctx.Done():
-			return errors.New("context canceled")
-		default:
-		}
-		sub, err = self.nonceSubmittedSub(events)
-		if err != nil {
-			level.Error(logger).Log("msg", "initial subscribing to events failed")
-			<-ticker.C
-			continue
-		}
-		break
-	}
-
 	for {
 		select {
 		case <-self.ctx.Done():
 			return errors.New("context canceled")
-		case err := <-sub.Err():
-			if err != nil {
-				level.Error(logger).Log(
-					"msg",
-					"subscription error",
-					"err", err)
+		case <-ticker.C:
+			if err := self.recordGasPrice(); err != nil {
+				level.Error(self.logger).Log("msg", "record gas price", "err", err)
 			}
-
-			// Trying to resubscribe until it succeeds.
-			for {
-				select {
-				case <-self.ctx.Done():
-					return errors.New("context canceled")
-				default:
-				}
-				sub, err = self.nonceSubmittedSub(events)
-				if err != nil {
-					level.Error(logger).Log("msg", "re-subscribing to events failed", "err", err)
-					<-ticker.C
-					continue
-				}
-				break
+		case event := <-self.events:
+			// The gas usage of reorged submits is only an estimation input so no need to revert it.
+			if event.Raw.Removed {
+				continue
 			}
-			level.Info(logger).Log("msg", "re-subscribed to events")
-		case event := <-events:
 			err := self.recordGasUsage(event)
 			if err != nil {
 				level.Error(self.logger).Log("msg", "record gas usage", "err", err)
@@ -230,6 +207,23 @@ func (self *RewardTracker) recordGasUsageEstimated() error {
 	return nil
 }
 
+func (self *RewardTracker) recordGasPrice() error {
+	ctx, cncl := context.WithTimeout(self.ctx, 5*time.Second)
+	defer cncl()
+	gasPrice, err := self.gasPriceQuerier.Query(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting the gas price")
+	}
+	price, _ := new(big.Float).SetInt(gasPrice).Float64()
+	lbls := labels.Labels{
+		labels.Label{Name: "__name__", Value: GasPriceMetricName},
+	}
+	if err := db.Add(self.ctx, self.tsDB, lbls, price); err != nil {
+		return errors.Wrap(err, "adding the gas price to the db")
+	}
+	return nil
+}
+
 func (self *RewardTracker) addGasEstimation(slot *big.Int, gasEstimation uint64) error {
 	lbls := labels.Labels{
 		labels.Label{Name: "__name__", Value: "gas_usage_estimation"},
@@ -252,18 +246,6 @@ func (self *RewardTracker) addGasUsed(slot string, gasUsed uint64) error {
 	return nil
 }
 
-func (self *RewardTracker) nonceSubmittedSub(output chan *tellor.TellorNonceSubmitted) (event.Subscription, error) {
-	tellorFilterer, err := tellor.NewTellorFilterer(self.contractInstance.Address, self.client)
-	if err != nil {
-		return nil, errors.Wrap(err, "getting instance")
-	}
-	sub, err := tellorFilterer.WatchNonceSubmitted(&bind.WatchOpts{Context: self.ctx}, output, []common.Address{}, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "getting channel")
-	}
-	return sub, nil
-}
-
 // Current returns the profit in percents based on the current TRB price.
 func (self *RewardTracker) Current(ctx context.Context, slot *big.Int, gasPriceEth1e18 *big.Int) (int64, error) {
 	gasUsed, err := self.GasUsed(ctx, slot)
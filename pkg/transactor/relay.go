@@ -0,0 +1,163 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+const (
+	RelayMethodPrivateTx = "eth_sendPrivateTransaction"
+	RelayMethodBundle    = "eth_sendBundle"
+)
+
+type RelayConfig struct {
+	Enabled        bool   `help:"Send transactions to a private relay instead of the public mempool."`
+	URL            string `help:"JSON-RPC endpoint of the private relay."`
+	Method         string `help:"Relay JSON-RPC method - eth_sendPrivateTransaction or eth_sendBundle."`
+	FallbackBlocks uint64 `help:"Broadcast the transaction to the public mempool when it is not included after this many blocks."`
+}
+
+// RelayBackend is the part of the ethereum client used by the relay
+// to follow the chain and to broadcast publicly as a fallback.
+type RelayBackend interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// Relay sends signed transactions to a private relay so that they are not visible in the public mempool
+// until included in a block. When the relay doesn't get the transaction included
+// within the configured number of blocks it is broadcasted publicly.
+type Relay struct {
+	cfg          RelayConfig
+	logger       log.Logger
+	client       RelayBackend
+	rpc          *rpc.Client
+	pollInterval time.Duration
+}
+
+func NewRelay(logger log.Logger, cfg RelayConfig, client RelayBackend) (*Relay, error) {
+	switch cfg.Method {
+	case RelayMethodPrivateTx, RelayMethodBundle:
+	default:
+		return nil, errors.Errorf("unsupported relay method:%v", cfg.Method)
+	}
+	if cfg.FallbackBlocks == 0 {
+		return nil, errors.New("relay fallback blocks should be greater than 0")
+	}
+	rpcClient, err := rpc.DialHTTP(cfg.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "create relay rpc client")
+	}
+	return &Relay{
+		cfg:          cfg,
+		logger:       log.With(logger, "relay", cfg.URL),
+		client:       client,
+		rpc:          rpcClient,
+		pollInterval: time.Second,
+	}, nil
+}
+
+// Send submits the signed transaction to the relay and waits until it is mined.
+// Bundles target a single block so these are resent on every new block
+// until the transaction is included or the fallback is reached.
+func (self *Relay) Send(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	startBlock, err := self.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting current block number")
+	}
+	fallbackBlock := startBlock + self.cfg.FallbackBlocks
+
+	if err := self.send(ctx, tx, startBlock+1, fallbackBlock); err != nil {
+		level.Warn(self.logger).Log("msg", "relay send failed so broadcasting publicly", "tx", tx.Hash(), "err", err)
+		if err := self.client.SendTransaction(ctx, tx); err != nil {
+			return nil, errors.Wrap(err, "public broadcast after a relay failure")
+		}
+		fallbackBlock = startBlock // Already broadcasted.
+	}
+	level.Info(self.logger).Log("msg", "transaction sent", "tx", tx.Hash(), "block", startBlock, "fallbackBlock", fallbackBlock)
+
+	ticker := time.NewTicker(self.pollInterval)
+	defer ticker.Stop()
+	lastBlock := startBlock
+	broadcasted := fallbackBlock == startBlock
+	for {
+		receipt, err := self.client.TransactionReceipt(ctx, tx.Hash())
+		if receipt != nil {
+			return receipt, nil
+		}
+		if err != nil {
+			level.Debug(self.logger).Log("msg", "receipt retrieval", "err", err)
+		}
+
+		block, err := self.client.BlockNumber(ctx)
+		if err != nil {
+			level.Error(self.logger).Log("msg", "getting current block number", "err", err)
+		} else if block > lastBlock && !broadcasted {
+			lastBlock = block
+			if block >= fallbackBlock {
+				level.Warn(self.logger).Log("msg", "transaction not included by the relay so broadcasting publicly", "tx", tx.Hash(), "block", block)
+				if err := self.client.SendTransaction(ctx, tx); err != nil {
+					return nil, errors.Wrap(err, "public broadcast fallback")
+				}
+				broadcasted = true
+			} else if self.cfg.Method == RelayMethodBundle {
+				if err := self.send(ctx, tx, block+1, fallbackBlock); err != nil {
+					level.Error(self.logger).Log("msg", "resending bundle", "err", err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "waiting for relayed tx:%v", tx.Hash())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (self *Relay) send(ctx context.Context, tx *types.Transaction, targetBlock, maxBlock uint64) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "encoding transaction")
+	}
+	var result interface{}
+	switch self.cfg.Method {
+	case RelayMethodBundle:
+		err = self.rpc.CallContext(ctx, &result, self.cfg.Method, relayBundle{
+			Txs:         []hexutil.Bytes{raw},
+			BlockNumber: hexutil.Uint64(targetBlock),
+		})
+	default:
+		err = self.rpc.CallContext(ctx, &result, self.cfg.Method, relayPrivateTx{
+			Tx:             hexutil.Bytes(raw),
+			MaxBlockNumber: hexutil.Uint64(maxBlock),
+		})
+	}
+	if err != nil {
+		return errors.Wrapf(err, "calling relay method:%v", self.cfg.Method)
+	}
+	level.Debug(self.logger).Log("msg", "relay accepted transaction", "tx", tx.Hash(), "targetBlock", targetBlock, "result", result)
+	return nil
+}
+
+type relayPrivateTx struct {
+	Tx             hexutil.Bytes  `json:"tx"`
+	MaxBlockNumber hexutil.Uint64 `json:"maxBlockNumber"`
+}
+
+type relayBundle struct {
+	Txs         []hexutil.Bytes `json:"txs"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+}
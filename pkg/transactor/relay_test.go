@@ -0,0 +1,180 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// mockRelay is a local http server that acts as a private relay
+// and records all transactions it receives.
+type mockRelay struct {
+	mtx     sync.Mutex
+	methods []string
+	txs     []*types.Transaction
+	onTx    func(*types.Transaction)
+}
+
+func (self *mockRelay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var raw []hexutil.Bytes
+	switch req.Method {
+	case RelayMethodPrivateTx:
+		var p relayPrivateTx
+		if err := json.Unmarshal(req.Params[0], &p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		raw = append(raw, p.Tx)
+	case RelayMethodBundle:
+		var p relayBundle
+		if err := json.Unmarshal(req.Params[0], &p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		raw = append(raw, p.Txs...)
+	}
+
+	self.mtx.Lock()
+	self.methods = append(self.methods, req.Method)
+	for _, r := range raw {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(r); err != nil {
+			self.mtx.Unlock()
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		self.txs = append(self.txs, tx)
+		if self.onTx != nil {
+			self.onTx(tx)
+		}
+	}
+	self.mtx.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":"0x1"}`))
+}
+
+// mockChain advances one block on every block number query
+// and returns a receipt only for the included transactions.
+type mockChain struct {
+	mtx       sync.Mutex
+	block     uint64
+	included  map[common.Hash]bool
+	broadcast []*types.Transaction
+}
+
+func (self *mockChain) BlockNumber(ctx context.Context) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.block++
+	return self.block, nil
+}
+
+func (self *mockChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.included[txHash] {
+		return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+	}
+	return nil, nil
+}
+
+func (self *mockChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.broadcast = append(self.broadcast, tx)
+	self.included[tx.Hash()] = true
+	return nil
+}
+
+func (self *mockChain) include(tx *types.Transaction) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.included[tx.Hash()] = true
+}
+
+func signedTx(t *testing.T) *types.Transaction {
+	key, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+	tx, err = types.SignTx(tx, types.LatestSignerForChainID(big.NewInt(1)), key)
+	testutil.Ok(t, err)
+	return tx
+}
+
+func newTestRelay(t *testing.T, method string, chain *mockChain) (*Relay, *mockRelay) {
+	mock := &mockRelay{}
+	srv := httptest.NewServer(mock)
+	t.Cleanup(srv.Close)
+
+	relay, err := NewRelay(logging.NewLogger(), RelayConfig{
+		Enabled:        true,
+		URL:            srv.URL,
+		Method:         method,
+		FallbackBlocks: 3,
+	}, chain)
+	testutil.Ok(t, err)
+	relay.pollInterval = time.Millisecond
+	return relay, mock
+}
+
+func TestRelayIncluded(t *testing.T) {
+	for _, method := range []string{RelayMethodPrivateTx, RelayMethodBundle} {
+		t.Run(method, func(t *testing.T) {
+			chain := &mockChain{included: make(map[common.Hash]bool)}
+			relay, mock := newTestRelay(t, method, chain)
+			mock.onTx = chain.include
+
+			tx := signedTx(t)
+			ctx, cncl := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cncl()
+			receipt, err := relay.Send(ctx, tx)
+			testutil.Ok(t, err)
+			testutil.Equals(t, tx.Hash(), receipt.TxHash)
+
+			testutil.Equals(t, []string{method}, mock.methods)
+			testutil.Equals(t, tx.Hash(), mock.txs[0].Hash())
+			testutil.Equals(t, 0, len(chain.broadcast), "the tx shouldn't be broadcasted publicly")
+		})
+	}
+}
+
+func TestRelayFallback(t *testing.T) {
+	chain := &mockChain{included: make(map[common.Hash]bool)}
+	relay, mock := newTestRelay(t, RelayMethodBundle, chain)
+
+	tx := signedTx(t)
+	ctx, cncl := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cncl()
+	receipt, err := relay.Send(ctx, tx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, tx.Hash(), receipt.TxHash)
+
+	testutil.Equals(t, 1, len(chain.broadcast), "the tx should be broadcasted publicly after the fallback blocks")
+	testutil.Equals(t, tx.Hash(), chain.broadcast[0].Hash())
+	testutil.Assert(t, len(mock.txs) > 1, "the bundle should be resent on every new block")
+}
@@ -10,13 +10,14 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/format"
 	"github.com/tellor-io/telliot/pkg/gasPrice"
 	"github.com/tellor-io/telliot/pkg/logging"
 )
@@ -27,6 +28,9 @@ type Config struct {
 	LogLevel      string
 	GasMax        uint
 	GasMultiplier int
+	Relay         RelayConfig
+	// DrainTimeout is how long to wait for the receipt of an already sent transaction after a cancel.
+	DrainTimeout format.Duration `help:"How long to wait for the receipt of an already sent transaction when the submit is canceled or on shutdown."`
 }
 
 // Transactor takes care of sending transactions over the blockchain network.
@@ -40,15 +44,16 @@ type TransactorDefault struct {
 	cfg             Config
 	logger          log.Logger
 	gasPriceQuerier gasPrice.GasPriceQuerier
-	client          *ethclient.Client
+	client          ethereum.EthClient
 	account         *ethereum.Account
+	relay           *Relay
 }
 
 func New(
 	logger log.Logger,
 	cfg Config,
 	gasPriceQuerier gasPrice.GasPriceQuerier,
-	client *ethclient.Client,
+	client ethereum.EthClient,
 	account *ethereum.Account,
 ) (*TransactorDefault, error) {
 	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
@@ -63,13 +68,24 @@ func New(
 		return nil, errors.Wrap(err, "getting network id")
 	}
 
+	logger = log.With(logger, "component", ComponentName)
+
+	var relay *Relay
+	if cfg.Relay.Enabled {
+		relay, err = NewRelay(logger, cfg.Relay, client)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating relay")
+		}
+	}
+
 	return &TransactorDefault{
 		netID:           netID,
 		cfg:             cfg,
-		logger:          log.With(logger, "component", ComponentName),
+		logger:          logger,
 		gasPriceQuerier: gasPriceQuerier,
 		client:          client,
 		account:         account,
+		relay:           relay,
 	}, nil
 }
 
@@ -108,13 +124,11 @@ func (self *TransactorDefault) Transact(ctx context.Context, contractCall func(*
 			continue
 		}
 
-		auth, err := bind.NewKeyedTransactorWithChainID(self.account.PrivateKey, self.netID)
-		if err != nil {
-			return nil, nil, errors.Wrap(err, "creating transactor")
-		}
+		auth := self.account.NewTransactor(self.netID)
 		auth.Nonce = big.NewInt(IntNonce)
 		auth.Value = big.NewInt(0)      // in weiF
 		auth.GasLimit = uint64(3000000) // in units
+		auth.NoSend = self.relay != nil // The relay does the sending.
 		if gasPrice.Cmp(big.NewInt(0)) == 0 {
 			gasPrice = big.NewInt(100)
 		}
@@ -163,7 +177,20 @@ func (self *TransactorDefault) Transact(ctx context.Context, contractCall func(*
 			}
 		}
 
-		receipt, err := bind.WaitMined(ctx, self.client, tx)
+		var receipt *types.Receipt
+		if self.relay != nil {
+			// The relay does the sending so nothing is sent yet.
+			if ctx.Err() != nil {
+				return nil, nil, errors.New("the submit context was canceled")
+			}
+			waitCtx, cncl := self.drainContext(ctx, tx.Hash())
+			receipt, err = self.relay.Send(waitCtx, tx)
+			cncl()
+		} else {
+			waitCtx, cncl := self.drainContext(ctx, tx.Hash())
+			receipt, err = bind.WaitMined(waitCtx, self.client, tx)
+			cncl()
+		}
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "transaction result tx:%v", tx.Hash())
 		}
@@ -171,3 +198,24 @@ func (self *TransactorDefault) Transact(ctx context.Context, contractCall func(*
 	}
 	return nil, nil, errors.Wrapf(finalError, "submit tx after 5 attempts")
 }
+
+// drainContext returns the context for waiting the receipt of a sent transaction.
+// The transaction might still be mined after the submit is canceled so
+// the wait continues until the drain timeout after the cancel.
+func (self *TransactorDefault) drainContext(ctx context.Context, tx common.Hash) (context.Context, context.CancelFunc) {
+	drainCtx, cncl := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-drainCtx.Done():
+			return
+		case <-ctx.Done():
+		}
+		level.Info(self.logger).Log("msg", "submit canceled so waiting for the sent transaction to be mined", "tx", tx, "timeout", self.cfg.DrainTimeout)
+		select {
+		case <-drainCtx.Done():
+		case <-time.After(self.cfg.DrainTimeout.Duration):
+			cncl()
+		}
+	}()
+	return drainCtx, cncl
+}
@@ -0,0 +1,81 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package transactor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type fixedGasPrice int64
+
+func (self fixedGasPrice) Query(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(int64(self)), nil
+}
+
+// TestTransactDrain checks that a canceled submit still waits for
+// the receipt of a sent transaction until the drain timeout.
+func TestTransactDrain(t *testing.T) {
+	logger := logging.NewLogger()
+	accounts, err := simulation.NewAccounts(2)
+	testutil.Ok(t, err)
+	backend, err := simulation.NewBackend(logger, simulation.Config{
+		Difficulty: big.NewInt(100),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Accounts:   []common.Address{accounts[0].Address},
+		Balance:    big.NewInt(1e18),
+	})
+	testutil.Ok(t, err)
+
+	transfer := func(cncl context.CancelFunc) func(*bind.TransactOpts) (*types.Transaction, error) {
+		return func(auth *bind.TransactOpts) (*types.Transaction, error) {
+			tx := types.NewTransaction(auth.Nonce.Uint64(), accounts[1].Address, big.NewInt(1), 21000, auth.GasPrice, nil)
+			tx, err := auth.Signer(auth.From, tx)
+			if err != nil {
+				return nil, err
+			}
+			if err := backend.SendTransaction(auth.Context, tx); err != nil {
+				return nil, err
+			}
+			// The cancel happens after the transaction is already sent.
+			cncl()
+			return tx, nil
+		}
+	}
+
+	cfg := Config{LogLevel: "info", DrainTimeout: format.Duration{Duration: 10 * time.Second}}
+	transactor, err := New(logger, cfg, fixedGasPrice(1), backend, accounts[0])
+	testutil.Ok(t, err)
+
+	ctx, cncl := context.WithCancel(context.Background())
+	go func() {
+		<-ctx.Done()
+		time.Sleep(100 * time.Millisecond)
+		backend.Commit()
+	}()
+	tx, receipt, err := transactor.Transact(ctx, transfer(cncl))
+	testutil.Ok(t, err)
+	testutil.Equals(t, tx.Hash(), receipt.TxHash)
+	testutil.Equals(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	// Without a new block the wait ends after the drain timeout.
+	cfg.DrainTimeout.Duration = 100 * time.Millisecond
+	transactor, err = New(logger, cfg, fixedGasPrice(1), backend, accounts[0])
+	testutil.Ok(t, err)
+	ctx, cncl = context.WithCancel(context.Background())
+	start := time.Now()
+	_, _, err = transactor.Transact(ctx, transfer(cncl))
+	testutil.NotOk(t, err)
+	testutil.Assert(t, time.Since(start) < 5*time.Second, "the wait should end after the drain timeout")
+}
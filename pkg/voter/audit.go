@@ -0,0 +1,90 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package voter
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionVoted                = "voted"
+	ActionDryRun               = "dry-run"
+	ActionAwaitingConfirmation = "awaiting confirmation"
+	ActionConfirmed            = "confirmed"
+	ActionAlreadyVoted         = "already voted"
+	ActionNoData               = "no data"
+	ActionFailed               = "failed"
+)
+
+// Entry is a single decision of the voter.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	DisputeID int64     `json:"disputeId"`
+	Action    string    `json:"action"`
+	Policy    string    `json:"policy,omitempty"`
+	Account   string    `json:"account,omitempty"`
+	RequestID int64     `json:"requestId,omitempty"`
+	Value     float64   `json:"value,omitempty"`
+	PSRValue  float64   `json:"psrValue,omitempty"`
+	Deviation float64   `json:"deviationPercent,omitempty"`
+	Support   bool      `json:"support"`
+	Tx        string    `json:"tx,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Audit appends the entry to the audit log at the given path as a JSON line.
+func Audit(path string, entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "creating the audit log folder")
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "opening the audit log")
+	}
+	defer f.Close()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "encoding the audit entry")
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "writing the audit entry")
+	}
+	return nil
+}
+
+// confirmed returns the dispute IDs with a confirmation in the audit log.
+func confirmed(path string) (map[int64]bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "opening the audit log")
+	}
+	defer f.Close()
+
+	ids := make(map[int64]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrapf(err, "decoding the audit entry:%v", scanner.Text())
+		}
+		if entry.Action == ActionConfirmed {
+			ids[entry.DisputeID] = true
+		}
+	}
+	return ids, errors.Wrap(scanner.Err(), "reading the audit log")
+}
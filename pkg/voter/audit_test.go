@@ -0,0 +1,27 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package voter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestConfirmed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voter", "audit.jsonl")
+
+	ids, err := confirmed(path)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(ids))
+
+	testutil.Ok(t, Audit(path, Entry{DisputeID: 1, Action: ActionAwaitingConfirmation, Support: true}))
+	testutil.Ok(t, Audit(path, Entry{DisputeID: 2, Action: ActionAwaitingConfirmation}))
+	testutil.Ok(t, Audit(path, Entry{DisputeID: 2, Action: ActionConfirmed}))
+
+	ids, err = confirmed(path)
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[int64]bool{2: true}, ids)
+}
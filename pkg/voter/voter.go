@@ -0,0 +1,355 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package voter
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/contracts/tellor"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/events"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/tracker/dispute"
+)
+
+const ComponentName = "voter"
+
+// Voting policies.
+const (
+	// PolicyAuto votes as recommended.
+	PolicyAuto = "auto"
+	// PolicyDryRun only records the recommended votes.
+	PolicyDryRun = "dry-run"
+	// PolicyConfirm votes as recommended after a confirmation with `telliot dispute confirm`.
+	PolicyConfirm = "confirm"
+)
+
+// scanWindow is how far in the past to look for open disputes on start.
+// The voting lasts 7 days so this covers all disputes that can still be voted.
+const scanWindow = 8 * 24 * time.Hour
+
+type Config struct {
+	Enabled  bool
+	LogLevel string
+	// Policy is one of auto, dry-run or confirm.
+	Policy string `help:"How to vote on the disputes - auto, dry-run or confirm."`
+	// Accounts are the addresses that vote. All accounts vote when empty.
+	Accounts []string `help:"Addresses of the accounts that vote. All accounts vote when empty."`
+	// AuditLog is the file with a JSON line for every decision.
+	AuditLog string `help:"File with a JSON line for every voting decision."`
+	// ConfirmInterval is how often to check for confirmations of the awaiting votes.
+	ConfirmInterval   format.Duration `help:"How often to check the audit log for confirmations of the awaiting votes."`
+	ConfirmationDepth uint64          `help:"Number of confirmations before voting on a new dispute."`
+}
+
+// vote is a recommended vote awaiting a confirmation.
+type vote struct {
+	requestID int64
+	support   bool
+	// end is when the voting ends.
+	end time.Time
+}
+
+type Voter struct {
+	logger     log.Logger
+	ctx        context.Context
+	close      context.CancelFunc
+	cfg        Config
+	disputeCfg dispute.Config
+	tsDB       storage.Queryable
+	client     ethereum.EthClient
+	contract   *contracts.ITellor
+	accounts   []*ethereum.Account
+	events     <-chan *tellor.ITellorNewDispute
+	// awaiting has the votes waiting for a confirmation by dispute ID.
+	awaiting map[int64]vote
+	// retry has the disputes that are handled again on every tick by dispute ID
+	// with the end of their voting or zero when it is unknown.
+	// These failed or had no recorded values so not all accounts have voted.
+	retry map[int64]time.Time
+}
+
+func New(
+	logger log.Logger,
+	ctx context.Context,
+	cfg Config,
+	disputeCfg dispute.Config,
+	tsDB storage.Queryable,
+	client ethereum.EthClient,
+	contract *contracts.ITellor,
+	hub *events.Hub,
+	accounts []*ethereum.Account,
+) (*Voter, error) {
+	logger, err := logging.ApplyFilter(cfg.LogLevel, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	logger = log.With(logger, "component", ComponentName)
+
+	switch cfg.Policy {
+	case PolicyAuto, PolicyDryRun, PolicyConfirm:
+	default:
+		return nil, errors.Errorf("invalid voting policy:%v", cfg.Policy)
+	}
+
+	if len(cfg.Accounts) > 0 {
+		var voters []*ethereum.Account
+		for _, addr := range cfg.Accounts {
+			account, err := ethereum.GetAccountByPubAddess(addr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "getting the voter account:%v", addr)
+			}
+			voters = append(voters, account)
+		}
+		accounts = voters
+	}
+
+	ctx, close := context.WithCancel(ctx)
+	return &Voter{
+		logger:     logger,
+		ctx:        ctx,
+		close:      close,
+		cfg:        cfg,
+		disputeCfg: disputeCfg,
+		tsDB:       tsDB,
+		client:     client,
+		contract:   contract,
+		accounts:   accounts,
+		events:     hub.NewDispute(cfg.ConfirmationDepth),
+		awaiting:   make(map[int64]vote),
+		retry:      make(map[int64]time.Time),
+	}, nil
+}
+
+func (self *Voter) Start() error {
+	level.Info(self.logger).Log("msg", "starting", "policy", self.cfg.Policy, "accounts", len(self.accounts))
+
+	// Vote on the disputes opened while not running.
+	if err := self.scan(); err != nil {
+		level.Error(self.logger).Log("msg", "scanning the open disputes", "err", err)
+	}
+
+	ticker := time.NewTicker(self.cfg.ConfirmInterval.Duration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-self.ctx.Done():
+			return nil
+		case event := <-self.events:
+			if event.Raw.Removed {
+				continue
+			}
+			self.handle(event.DisputeId)
+		case <-ticker.C:
+			self.checkConfirmations()
+			self.retryDisputes()
+		}
+	}
+}
+
+func (self *Voter) Stop() {
+	self.close()
+}
+
+func (self *Voter) scan() error {
+	startBlock, err := ethereum.BlockBefore(self.ctx, self.client, scanWindow)
+	if err != nil {
+		return errors.Wrap(err, "estimating the dispute scan start block")
+	}
+	disputes, err := self.contract.FilterNewDispute(&bind.FilterOpts{Context: self.ctx, Start: startBlock}, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "filter dispute logs")
+	}
+	defer disputes.Close()
+	for disputes.Next() {
+		self.handle(disputes.Event.DisputeId)
+	}
+	return errors.Wrap(disputes.Error(), "iterating the dispute logs")
+}
+
+// handle computes the recommendation for an open dispute and applies the policy for it.
+// A dispute that fails or has no recorded values is retried until the voting ends.
+func (self *Voter) handle(disputeID *big.Int) {
+	id := disputeID.Int64()
+	delete(self.retry, id)
+	logger := log.With(self.logger, "id", disputeID)
+	_, executed, _, _, _, _, _, uintVars, _, err := self.contract.GetAllDisputeVars(&bind.CallOpts{Context: self.ctx}, disputeID)
+	if err != nil {
+		self.audit(logger, Entry{DisputeID: id, Action: ActionFailed, Error: errors.Wrap(err, "get dispute details").Error()})
+		self.retry[id] = time.Time{}
+		return
+	}
+	end := time.Unix(uintVars[3].Int64(), 0)
+	if executed || time.Now().After(end) {
+		level.Debug(logger).Log("msg", "dispute voting ended")
+		return
+	}
+
+	requestID := uintVars[0].Int64()
+	value := float64(uintVars[2].Int64())
+	recommendation, err := dispute.Recommend(
+		self.ctx,
+		self.tsDB,
+		requestID,
+		value,
+		time.Unix(uintVars[1].Int64(), 0),
+		self.disputeCfg.Window.Duration,
+		self.disputeCfg.RequestTolerance(requestID),
+	)
+	entry := Entry{
+		DisputeID: disputeID.Int64(),
+		Policy:    self.cfg.Policy,
+		RequestID: requestID,
+		Value:     value,
+	}
+	if err != nil {
+		entry.Action, entry.Error = ActionFailed, errors.Wrap(err, "recommendation").Error()
+		self.audit(logger, entry)
+		self.retry[id] = end
+		return
+	}
+	if recommendation == nil {
+		entry.Action = ActionNoData
+		self.audit(logger, entry)
+		self.retry[id] = end
+		return
+	}
+	entry.PSRValue = recommendation.PSRValue
+	entry.Deviation = recommendation.Deviation
+	entry.Support = recommendation.Support
+
+	switch self.cfg.Policy {
+	case PolicyAuto:
+		if !self.vote(logger, entry) {
+			self.retry[id] = end
+		}
+	case PolicyDryRun:
+		entry.Action = ActionDryRun
+		self.audit(logger, entry)
+	case PolicyConfirm:
+		entry.Action = ActionAwaitingConfirmation
+		self.awaiting[id] = vote{requestID: requestID, support: recommendation.Support, end: end}
+		self.audit(logger, entry)
+	}
+}
+
+// retryDisputes handles again the disputes that failed or had no recorded values
+// and drops the ones with an ended voting.
+func (self *Voter) retryDisputes() {
+	var ids []int64
+	for id, end := range self.retry {
+		if !end.IsZero() && time.Now().After(end) {
+			delete(self.retry, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		self.handle(big.NewInt(id))
+	}
+}
+
+// checkConfirmations votes on the awaiting disputes that are confirmed in the audit log.
+func (self *Voter) checkConfirmations() {
+	if len(self.awaiting) == 0 {
+		return
+	}
+	ids, err := confirmed(self.cfg.AuditLog)
+	if err != nil {
+		level.Error(self.logger).Log("msg", "reading the confirmations", "err", err)
+		return
+	}
+	for id, v := range self.awaiting {
+		if time.Now().After(v.end) {
+			delete(self.awaiting, id)
+			continue
+		}
+		if !ids[id] {
+			continue
+		}
+		// A failed vote stays awaiting so that it is retried on the next check.
+		if self.vote(log.With(self.logger, "id", id), Entry{
+			DisputeID: id,
+			Policy:    self.cfg.Policy,
+			RequestID: v.requestID,
+			Support:   v.support,
+		}) {
+			delete(self.awaiting, id)
+		}
+	}
+}
+
+// vote casts the vote of the entry from all accounts that haven't voted yet.
+// It returns false when the vote of some account failed.
+func (self *Voter) vote(logger log.Logger, entry Entry) bool {
+	disputeID := big.NewInt(entry.DisputeID)
+	ok := true
+	for _, account := range self.accounts {
+		entry := entry
+		entry.Account = account.Address.String()
+
+		voted, err := self.contract.DidVote(&bind.CallOpts{Context: self.ctx}, disputeID, account.Address)
+		if err != nil {
+			ok = false
+			entry.Action, entry.Error = ActionFailed, errors.Wrap(err, "check if already voted").Error()
+			self.audit(logger, entry)
+			continue
+		}
+		if voted {
+			entry.Action = ActionAlreadyVoted
+			self.audit(logger, entry)
+			continue
+		}
+
+		auth, err := ethereum.PrepareEthTransaction(self.ctx, self.client, account, nil)
+		if err != nil {
+			ok = false
+			entry.Action, entry.Error = ActionFailed, errors.Wrap(err, "prepare ethereum transaction").Error()
+			self.audit(logger, entry)
+			continue
+		}
+		tx, err := self.contract.Vote(auth, disputeID, entry.Support)
+		if err != nil {
+			ok = false
+			entry.Action, entry.Error = ActionFailed, errors.Wrap(err, "submit vote transaction").Error()
+			self.audit(logger, entry)
+			continue
+		}
+		entry.Action, entry.Tx = ActionVoted, tx.Hash().String()
+		self.audit(logger, entry)
+	}
+	return ok
+}
+
+// audit logs the entry and appends it to the audit log.
+func (self *Voter) audit(logger log.Logger, entry Entry) {
+	lvl := level.Info(logger)
+	if entry.Action == ActionFailed {
+		lvl = level.Error(logger)
+	}
+	lvl.Log(
+		"msg", "vote decision",
+		"action", entry.Action,
+		"account", entry.Account,
+		"support", entry.Support,
+		"requestId", entry.RequestID,
+		"value", entry.Value,
+		"psrValue", entry.PSRValue,
+		"deviationPercent", entry.Deviation,
+		"tx", entry.Tx,
+		"err", entry.Error,
+	)
+	if err := Audit(self.cfg.AuditLog, entry); err != nil {
+		level.Error(logger).Log("msg", "writing the audit log", "err", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package voter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/ethereum"
+	"github.com/tellor-io/telliot/pkg/format"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/simulation"
+	"github.com/tellor-io/telliot/pkg/testutil"
+	"github.com/tellor-io/telliot/pkg/tracker/dispute"
+)
+
+const slots = 5
+
+// newTestVoter opens a dispute for an outlier value on a simulated chain and
+// returns a voter for it with two accounts where the second one has already voted.
+func newTestVoter(t *testing.T, policy string) (*Voter, *simulation.Backend, *big.Int) {
+	ctx, cncl := context.WithCancel(context.Background())
+	t.Cleanup(cncl)
+
+	accounts, err := simulation.NewAccounts(slots + 2)
+	testutil.Ok(t, err)
+	cfg := simulation.Config{
+		Difficulty: big.NewInt(1),
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Balance:    big.NewInt(1e18),
+		Tokens:     new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+	}
+	var miners [slots]*ethereum.Account
+	for i, acc := range accounts {
+		cfg.Accounts = append(cfg.Accounts, acc.Address)
+		if i < slots {
+			cfg.Stakers = append(cfg.Stakers, acc.Address)
+			miners[i] = acc
+		}
+	}
+	backend, err := simulation.NewBackend(logging.NewLogger(), cfg)
+	testutil.Ok(t, err)
+	contract, err := contracts.NewITellor(backend)
+	testutil.Ok(t, err)
+
+	// The first miner submits an outlier for request ID 1 so after sorting by value it is the last one.
+	var values [slots][5]*big.Int
+	for i := range values {
+		for j := range values[i] {
+			values[i][j] = big.NewInt(1000)
+		}
+	}
+	values[0][0] = big.NewInt(2000)
+	_, err = backend.SubmitValue(miners, values)
+	testutil.Ok(t, err)
+	valueTime := int64(backend.NewValues()[0].Time)
+
+	reporter, voted := accounts[slots], accounts[slots+1]
+	auth, err := ethereum.PrepareEthTransaction(ctx, backend, reporter, nil)
+	testutil.Ok(t, err)
+	_, err = contract.BeginDispute(auth, big.NewInt(1), big.NewInt(valueTime), big.NewInt(slots-1))
+	testutil.Ok(t, err)
+	backend.Commit()
+	disputeID := big.NewInt(1)
+
+	auth, err = ethereum.PrepareEthTransaction(ctx, backend, voted, nil)
+	testutil.Ok(t, err)
+	_, err = contract.Vote(auth, disputeID, false)
+	testutil.Ok(t, err)
+	backend.Commit()
+
+	// The PSR value at the time of the disputed value.
+	db, err := tsdb.Open(t.TempDir(), nil, nil, tsdb.DefaultOptions())
+	testutil.Ok(t, err)
+	t.Cleanup(func() { db.Close() })
+	appender := db.Appender(ctx)
+	_, err = appender.Append(0,
+		labels.FromStrings(labels.MetricName, dispute.PSRValueMetric, "contract", "tellor", "id", "1"),
+		timestamp.FromTime(time.Unix(valueTime, 0)),
+		1000,
+	)
+	testutil.Ok(t, err)
+	testutil.Ok(t, appender.Commit())
+
+	return &Voter{
+		logger: logging.NewLogger(),
+		ctx:    ctx,
+		close:  cncl,
+		cfg: Config{
+			Policy:   policy,
+			AuditLog: filepath.Join(t.TempDir(), "audit.jsonl"),
+		},
+		disputeCfg: dispute.Config{Tolerance: 10, Window: format.Duration{Duration: 15 * time.Minute}},
+		tsDB:       db,
+		client:     backend,
+		contract:   contract,
+		accounts:   []*ethereum.Account{reporter, voted},
+		awaiting:   make(map[int64]vote),
+		retry:      make(map[int64]time.Time),
+	}, backend, disputeID
+}
+
+func readAudit(t *testing.T, path string) []Entry {
+	f, err := os.Open(path)
+	testutil.Ok(t, err)
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		testutil.Ok(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	testutil.Ok(t, scanner.Err())
+	return entries
+}
+
+// checkVoted checks that the first account voted in support of the dispute and
+// the second account which had already voted was skipped.
+func checkVoted(t *testing.T, voter *Voter, backend *simulation.Backend, disputeID *big.Int, entries []Entry) {
+	testutil.Equals(t, 2, len(entries))
+	testutil.Equals(t, ActionVoted, entries[0].Action)
+	testutil.Equals(t, voter.accounts[0].Address.String(), entries[0].Account)
+	testutil.Equals(t, true, entries[0].Support)
+	testutil.Equals(t, ActionAlreadyVoted, entries[1].Action)
+	testutil.Equals(t, voter.accounts[1].Address.String(), entries[1].Account)
+
+	testutil.Equals(t, 1, backend.Pending())
+	backend.Commit()
+	voted, err := voter.contract.DidVote(&bind.CallOpts{}, disputeID, voter.accounts[0].Address)
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, voted)
+}
+
+func TestVoterAuto(t *testing.T) {
+	voter, backend, disputeID := newTestVoter(t, PolicyAuto)
+	voter.handle(disputeID)
+	checkVoted(t, voter, backend, disputeID, readAudit(t, voter.cfg.AuditLog))
+}
+
+func TestVoterDryRun(t *testing.T) {
+	voter, backend, disputeID := newTestVoter(t, PolicyDryRun)
+	voter.handle(disputeID)
+
+	entries := readAudit(t, voter.cfg.AuditLog)
+	testutil.Equals(t, 1, len(entries))
+	testutil.Equals(t, ActionDryRun, entries[0].Action)
+	testutil.Equals(t, true, entries[0].Support)
+	testutil.Equals(t, float64(2000), entries[0].Value)
+	testutil.Equals(t, float64(1000), entries[0].PSRValue)
+	testutil.Equals(t, 0, backend.Pending())
+}
+
+func TestVoterConfirm(t *testing.T) {
+	voter, backend, disputeID := newTestVoter(t, PolicyConfirm)
+	voter.handle(disputeID)
+
+	entries := readAudit(t, voter.cfg.AuditLog)
+	testutil.Equals(t, 1, len(entries))
+	testutil.Equals(t, ActionAwaitingConfirmation, entries[0].Action)
+
+	// Nothing is sent until the vote is confirmed.
+	voter.checkConfirmations()
+	testutil.Equals(t, 0, backend.Pending())
+	testutil.Equals(t, 1, len(readAudit(t, voter.cfg.AuditLog)))
+
+	testutil.Ok(t, Audit(voter.cfg.AuditLog, Entry{DisputeID: disputeID.Int64(), Action: ActionConfirmed}))
+	voter.checkConfirmations()
+	checkVoted(t, voter, backend, disputeID, readAudit(t, voter.cfg.AuditLog)[2:])
+	testutil.Equals(t, 0, len(voter.awaiting))
+}
+
+func TestVoterRetry(t *testing.T) {
+	voter, backend, disputeID := newTestVoter(t, PolicyAuto)
+	db := voter.tsDB
+
+	// The values are not recorded yet.
+	empty, err := tsdb.Open(t.TempDir(), nil, nil, tsdb.DefaultOptions())
+	testutil.Ok(t, err)
+	defer empty.Close()
+	voter.tsDB = empty
+	voter.handle(disputeID)
+	entries := readAudit(t, voter.cfg.AuditLog)
+	testutil.Equals(t, 1, len(entries))
+	testutil.Equals(t, ActionNoData, entries[0].Action)
+	testutil.Equals(t, 1, len(voter.retry))
+
+	voter.tsDB = db
+	voter.retryDisputes()
+	checkVoted(t, voter, backend, disputeID, readAudit(t, voter.cfg.AuditLog)[1:])
+	testutil.Equals(t, 0, len(voter.retry))
+
+	// A dispute with an ended voting is dropped without handling it.
+	voter.retry[disputeID.Int64()] = time.Now().Add(-time.Second)
+	voter.retryDisputes()
+	testutil.Equals(t, 0, len(voter.retry))
+	testutil.Equals(t, 3, len(readAudit(t, voter.cfg.AuditLog)))
+}
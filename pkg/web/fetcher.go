@@ -4,6 +4,7 @@
 package web
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"io/ioutil"
@@ -69,3 +70,31 @@ func Get(ctx context.Context, url string, headers map[string]string) ([]byte, er
 	return nil, errFinal
 
 }
+
+// Post sends the payload as JSON and returns the response body.
+func Post(ctx context.Context, url string, payload []byte, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Add(k, v)
+	}
+
+	client := http.Client{Timeout: 10 * time.Second}
+	r, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "sending data")
+	}
+	defer r.Body.Close()
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read response body")
+	}
+	if r.StatusCode/100 != 2 {
+		return nil, errors.Errorf("response status code not OK code:%v, payload:%v", r.StatusCode, string(data))
+	}
+	return data, nil
+}